@@ -0,0 +1,392 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	if m.Frontend.Type() != twitch.Type {
+		return false
+	}
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"alert",
+		"alerts",
+	}
+}
+
+func (advanced) Description() string {
+	return "Send a message when someone follows, subscribes, raids or cheers."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedSet,
+		AdvancedShow,
+		AdvancedDelete,
+		AdvancedList,
+	}
+}
+
+func (advanced) Init() error {
+	core.EventFollowHooks.Register(onFollow)
+	core.EventSubscribeHooks.Register(onSubscribe)
+	core.EventRaidHooks.Register(onRaid)
+	core.EventCheerHooks.Register(onCheer)
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrUnknownEvent:
+		return "Unknown event, expected one of: " + strings.Join(Events, ", ")
+	case UrrNoAlert:
+		return "No alert has been set for that event."
+	case UrrNoAlerts:
+		return "No alerts have been set."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+/////////
+//     //
+// set //
+//     //
+/////////
+
+var AdvancedSet = advancedSet{}
+
+type advancedSet struct{}
+
+func (c advancedSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedSet) Description() string {
+	return "Set the message sent for an event. $(user) is replaced with who triggered it, subs also have $(months), $(tier) and $(message), raids $(viewers) and cheers $(bits) and $(message)."
+}
+
+func (advancedSet) UsageArgs() string {
+	return "(follow | sub | raid | cheer) <message...>"
+}
+
+func (c advancedSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedSet) Examples() []string {
+	return []string{
+		"follow Welcome $(user)!",
+		"sub $(user) has been subscribed for $(months) months!",
+		"raid $(user) is raiding with $(viewers) viewers!",
+		"cheer Thanks for the $(bits) bits $(user)!",
+	}
+}
+
+func (advancedSet) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedSet) Init() error {
+	return nil
+}
+
+func (c advancedSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Set the alert.", nil, nil
+}
+
+func (advancedSet) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	event := strings.ToLower(m.Command.Args[0])
+	return Set(here, event, strings.TrimSpace(m.RawArgs(1)))
+}
+
+//////////
+//      //
+// show //
+//      //
+//////////
+
+var AdvancedShow = advancedShow{}
+
+type advancedShow struct{}
+
+func (c advancedShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedShow) Description() string {
+	return "Show the message sent for an event."
+}
+
+func (advancedShow) UsageArgs() string {
+	return "(follow | sub | raid | cheer)"
+}
+
+func (c advancedShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedShow) Examples() []string {
+	return []string{
+		"raid",
+	}
+}
+
+func (advancedShow) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedShow) Init() error {
+	return nil
+}
+
+func (c advancedShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	message, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return message, nil, nil
+}
+
+func (advancedShow) core(m *core.EventMessage) (string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	return Get(here, strings.ToLower(m.Command.Args[0]))
+}
+
+////////////
+//        //
+// delete //
+//        //
+////////////
+
+var AdvancedDelete = advancedDelete{}
+
+type advancedDelete struct{}
+
+func (c advancedDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedDelete) Description() string {
+	return "Stop sending a message for an event."
+}
+
+func (advancedDelete) UsageArgs() string {
+	return "(follow | sub | raid | cheer)"
+}
+
+func (c advancedDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedDelete) Examples() []string {
+	return []string{
+		"follow",
+	}
+}
+
+func (advancedDelete) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedDelete) Init() error {
+	return nil
+}
+
+func (c advancedDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Deleted the alert.", nil, nil
+}
+
+func (advancedDelete) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Delete(here, strings.ToLower(m.Command.Args[0]))
+}
+
+//////////
+//      //
+// list //
+//      //
+//////////
+
+var AdvancedList = advancedList{}
+
+type advancedList struct{}
+
+func (c advancedList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedList) Description() string {
+	return "List the alerts that have been set."
+}
+
+func (advancedList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedList) Examples() []string {
+	return nil
+}
+
+func (advancedList) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedList) Init() error {
+	return nil
+}
+
+func (c advancedList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	as, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+
+	var fmted []string
+	for _, a := range as {
+		fmted = append(fmted, a.Event+": "+a.Message)
+	}
+	return strings.Join(fmted, " | "), nil, nil
+}
+
+func (advancedList) core(m *core.EventMessage) ([]alert, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return List(here)
+}
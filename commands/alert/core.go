@@ -0,0 +1,290 @@
+package alert
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrUnknownEvent = core.UrrNew("unknown event")
+	UrrNoAlert      = core.UrrNew("no alert has been set for the event")
+	UrrNoAlerts     = core.UrrNew("no alerts have been set")
+)
+
+// The events that alerts can be set for.
+const (
+	EventFollow    = "follow"
+	EventSubscribe = "sub"
+	EventRaid      = "raid"
+	EventCheer     = "cheer"
+)
+
+var Events = []string{
+	EventFollow,
+	EventSubscribe,
+	EventRaid,
+	EventCheer,
+}
+
+// The EventSub subscriptions each event requires.
+var subscriptions = map[string][]string{
+	EventFollow: {
+		helix.EventSubTypeChannelFollow,
+	},
+	EventSubscribe: {
+		helix.EventSubTypeChannelSubscription,
+		helix.EventSubTypeChannelSubscriptionMessage,
+	},
+	EventRaid: {
+		helix.EventSubTypeChannelRaid,
+	},
+	EventCheer: {
+		helix.EventSubTypeChannelCheer,
+	},
+}
+
+type alert struct {
+	Event   string
+	Message string
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbSet(place int64, event, message string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO cmd_alert_messages (place, event, message)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (place, event) DO UPDATE SET message = $3
+	`, place, event, message)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("event", event).
+		Str("message", message).
+		Msg("set alert message")
+
+	return err
+}
+
+func dbGet(place int64, event string) (string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var message string
+	err := db.DB.QueryRow(`
+		SELECT message
+		FROM cmd_alert_messages
+		WHERE place = $1 AND event = $2
+	`, place, event).Scan(&message)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("event", event).
+		Str("message", message).
+		Msg("got alert message")
+
+	return message, err
+}
+
+func dbList(place int64) ([]alert, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT event, message
+		FROM cmd_alert_messages
+		WHERE place = $1
+		ORDER BY event
+	`, place)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var as []alert
+	for rows.Next() {
+		var a alert
+		if err := rows.Scan(&a.Event, &a.Message); err != nil {
+			return nil, err
+		}
+		as = append(as, a)
+	}
+
+	log.Debug().
+		Err(rows.Err()).
+		Int64("place", place).
+		Int("#alerts", len(as)).
+		Msg("got alert messages")
+
+	return as, rows.Err()
+}
+
+// Returns false if no alert was set for the event.
+func dbDelete(place int64, event string) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_alert_messages
+		WHERE place = $1 AND event = $2
+	`, place, event)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("event", event).
+		Int64("deleted", n).
+		Msg("deleted alert message")
+
+	return n > 0, err
+}
+
+///////////
+//       //
+// alert //
+//       //
+///////////
+
+func known(event string) core.Urr {
+	if _, ok := subscriptions[event]; !ok {
+		return UrrUnknownEvent
+	}
+	return nil
+}
+
+// Set sets the message that is sent to the place every time the event
+// happens and subscribes to the event if needed.
+func Set(place int64, event, message string) (core.Urr, error) {
+	if urr := known(event); urr != nil {
+		return urr, nil
+	}
+	if err := twitch.EventsubEnsureCreated(place, subscriptions[event]...); err != nil {
+		return nil, err
+	}
+	return nil, dbSet(place, event, message)
+}
+
+// Get returns the message that is sent when the event happens.
+func Get(place int64, event string) (string, core.Urr, error) {
+	if urr := known(event); urr != nil {
+		return "", urr, nil
+	}
+	message, err := dbGet(place, event)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", UrrNoAlert, nil
+	}
+	return message, nil, err
+}
+
+// Delete stops sending a message when the event happens. The EventSub
+// subscriptions are left in place, as other commands might rely on them.
+func Delete(place int64, event string) (core.Urr, error) {
+	if urr := known(event); urr != nil {
+		return urr, nil
+	}
+	ok, err := dbDelete(place, event)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return UrrNoAlert, nil
+	}
+	return nil, nil
+}
+
+// List returns all the alerts that have been set in the place.
+func List(place int64) ([]alert, core.Urr, error) {
+	as, err := dbList(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(as) == 0 {
+		return nil, UrrNoAlerts, nil
+	}
+	return as, nil, nil
+}
+
+// Sends the event's alert to the place, if one has been set. The variables
+// are pairs of variable names and their values.
+func send(here core.Placer, event string, author core.Personifier, vars ...string) {
+	place, err := here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get place scope")
+		return
+	}
+
+	message, urr, err := Get(place, event)
+	if err != nil {
+		log.Error().Err(err).Str("event", event).Msg("failed to get alert")
+		return
+	}
+	if urr != nil {
+		return
+	}
+
+	user := "Anonymous"
+	if author != nil {
+		if user, err = author.DisplayName(); err != nil {
+			log.Error().Err(err).Msg("failed to get display name")
+			return
+		}
+	}
+
+	vars = append(vars, "$(user)", user)
+	message = strings.NewReplacer(vars...).Replace(message)
+
+	if err := core.Frontends.Announce(place, message, ""); err != nil {
+		log.Error().Err(err).Str("event", event).Msg("failed to send alert")
+	}
+}
+
+func onFollow(f *core.EventFollow) {
+	send(f.Here, EventFollow, f.Author)
+}
+
+func onSubscribe(s *core.EventSubscribe) {
+	send(s.Here, EventSubscribe, s.Author,
+		"$(months)", strconv.Itoa(s.Months),
+		// tiers are given as 1000, 2000 and 3000
+		"$(tier)", strings.TrimSuffix(s.Tier, "000"),
+		"$(message)", s.Message,
+	)
+}
+
+func onRaid(r *core.EventRaid) {
+	send(r.Here, EventRaid, r.Author,
+		"$(viewers)", strconv.Itoa(r.Viewers),
+	)
+}
+
+func onCheer(c *core.EventCheer) {
+	send(c.Here, EventCheer, c.Author,
+		"$(bits)", strconv.Itoa(c.Bits),
+		"$(message)", c.Message,
+	)
+}
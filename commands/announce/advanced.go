@@ -0,0 +1,504 @@
+package announce
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	ctime "github.com/kvlach/janitorjeff/commands/time"
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"announce",
+		"announcement",
+	}
+}
+
+func (advanced) Description() string {
+	return "Schedule messages for everyone in the channel."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedAdd,
+		AdvancedList,
+		AdvancedCancel,
+	}
+}
+
+func (advanced) Init() error {
+	announcements.Start(2 * time.Minute)
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+// Formats t using the place's time format, on Discord native timestamps are
+// used.
+func formatTime(m *core.EventMessage, t time.Time) (string, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+
+	format, err := ctime.FormatGet(here)
+	if err != nil {
+		return "", err
+	}
+
+	if m.Frontend.Type() == discord.Frontend.Type() {
+		return ctime.FormatTimeDiscord(t, format), nil
+	}
+	return ctime.FormatTime(t, format), nil
+}
+
+/////////
+//     //
+// add //
+//     //
+/////////
+
+var AdvancedAdd = advancedAdd{}
+
+type advancedAdd struct{}
+
+func (c advancedAdd) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAdd) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAdd) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedAdd) Description() string {
+	return "Schedule an announcement, on Discord a role can be mentioned."
+}
+
+func (advancedAdd) UsageArgs() string {
+	return "[@role] <what> (in|on) <when>"
+}
+
+func (c advancedAdd) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAdd) Examples() []string {
+	return []string{
+		"Stream starts in 10 minutes! in 50 minutes",
+		"@giveaway The giveaway has ended! on saturday 8pm",
+	}
+}
+
+func (advancedAdd) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedAdd) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAdd) Init() error {
+	return nil
+}
+
+func (c advancedAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	t, id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := c.fmt(urr, m, t, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: resp,
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAdd) text(m *core.EventMessage) (string, core.Urr, error) {
+	t, id, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	resp, err := c.fmt(urr, m, t, id)
+	return resp, urr, err
+}
+
+func (advancedAdd) fmt(urr core.Urr, m *core.EventMessage, t time.Time, id int64) (string, error) {
+	switch urr {
+	case nil:
+		when, err := formatTime(m, t)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Scheduled announcement for %s (#%d)", when, id), nil
+	case ctime.UrrInvalidTime:
+		return "Couldn't understand when the announcement should be made.", nil
+	case ctime.UrrOldTime:
+		return "The given time has already passed.", nil
+	default:
+		return fmt.Sprint(urr), nil
+	}
+}
+
+func (advancedAdd) core(m *core.EventMessage) (time.Time, int64, core.Urr, error) {
+	rxRole := `(<@&(?P<role>\d+)>\s+)?`
+	rxWhat := `(?P<what>.+)`
+	rxWhen := `(in|on)\s+(?P<when>.+)`
+
+	re := regexp.MustCompile(`^` + rxRole + rxWhat + `\s+` + rxWhen + `$`)
+	groupNames := re.SubexpNames()
+
+	var role string
+	var what string
+	var when string
+
+	for _, match := range re.FindAllStringSubmatch(m.RawArgs(0), -1) {
+		for i, text := range match {
+			group := groupNames[i]
+
+			switch group {
+			case "role":
+				role = text
+			case "what":
+				what = text
+			case "when":
+				when = text
+			}
+		}
+	}
+
+	// roles only exist on discord
+	if m.Frontend.Type() != discord.Frontend.Type() {
+		role = ""
+	}
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return time.Time{}, -1, nil, err
+	}
+
+	hereExact, err := m.Here.ScopeExact()
+	if err != nil {
+		return time.Time{}, -1, nil, err
+	}
+
+	hereLogical, err := m.Here.ScopeLogical()
+	if err != nil {
+		return time.Time{}, -1, nil, err
+	}
+
+	return Add(when, what, role, author, hereExact, hereLogical)
+}
+
+//////////
+//      //
+// list //
+//      //
+//////////
+
+var AdvancedList = advancedList{}
+
+type advancedList struct{}
+
+func (c advancedList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedList) Description() string {
+	return "List the scheduled announcements."
+}
+
+func (advancedList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedList) Examples() []string {
+	return nil
+}
+
+func (advancedList) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedList) Init() error {
+	return nil
+}
+
+func (c advancedList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	as, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if urr != nil {
+		embed := &dg.MessageEmbed{
+			Description: c.fmt(urr),
+		}
+		return embed, urr, nil
+	}
+
+	var desc strings.Builder
+	for _, a := range as {
+		when, err := formatTime(m, a.When)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(&desc, "**#%d** %s", a.ID, when)
+		if a.Role != "" {
+			fmt.Fprintf(&desc, " <@&%s>", a.Role)
+		}
+		fmt.Fprintf(&desc, "\n%s", a.What)
+		if a.Dead {
+			desc.WriteString(" *(failed to deliver)*")
+		}
+		desc.WriteString("\n\n")
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       "Scheduled Announcements",
+		Description: desc.String(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedList) text(m *core.EventMessage) (string, core.Urr, error) {
+	as, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+
+	var parts []string
+	for _, a := range as {
+		when, err := formatTime(m, a.When)
+		if err != nil {
+			return "", nil, err
+		}
+		part := fmt.Sprintf("#%d %s: %s", a.ID, when, a.What)
+		if a.Dead {
+			part += " (failed to deliver)"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " | "), nil, nil
+}
+
+func (advancedList) fmt(urr core.Urr) string {
+	switch urr {
+	case UrrNoAnnouncements:
+		return "There are no scheduled announcements."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedList) core(m *core.EventMessage) ([]announcement, core.Urr, error) {
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return nil, nil, err
+	}
+	return List(here)
+}
+
+////////////
+//        //
+// cancel //
+//        //
+////////////
+
+var AdvancedCancel = advancedCancel{}
+
+type advancedCancel struct{}
+
+func (c advancedCancel) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCancel) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCancel) Names() []string {
+	return append([]string{"cancel"}, core.AliasesDelete...)
+}
+
+func (advancedCancel) Description() string {
+	return "Cancel a scheduled announcement."
+}
+
+func (advancedCancel) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedCancel) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCancel) Examples() []string {
+	return []string{
+		"3",
+	}
+}
+
+func (advancedCancel) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedCancel) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCancel) Init() error {
+	return nil
+}
+
+func (c advancedCancel) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedCancel) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedCancel) text(m *core.EventMessage) (string, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (advancedCancel) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Cancelled announcement."
+	case UrrAnnouncementNotFound:
+		return "Announcement not found, maybe it was scheduled in a different channel?"
+	case UrrInvalidAnnouncementID:
+		return "The ID you provided is invalid, expected a number."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedCancel) core(m *core.EventMessage) (core.Urr, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(m.Command.Args[0], "#"), 10, 64)
+	if err != nil {
+		return UrrInvalidAnnouncementID, nil
+	}
+
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return nil, err
+	}
+
+	return Cancel(id, here)
+}
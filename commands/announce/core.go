@@ -0,0 +1,408 @@
+package announce
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	ctime "github.com/kvlach/janitorjeff/commands/time"
+	"github.com/kvlach/janitorjeff/core"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrNoAnnouncements       = core.UrrNew("there are no scheduled announcements")
+	UrrAnnouncementNotFound  = core.UrrNew("couldn't find the announcement")
+	UrrInvalidAnnouncementID = core.UrrNew("invalid announcement ID")
+)
+
+type announcement struct {
+	// Fields are public so that they show up in the debug logs
+	ID      int64
+	Place   int64
+	Creator int64
+	When    time.Time
+	What    string
+	// Empty if nobody is mentioned
+	Role string
+	// Failed delivery attempts, RetryAt is zero if the announcement is not
+	// being retried and Dead is true if delivery has been given up on.
+	Attempts int
+	RetryAt  time.Time
+	Dead     bool
+}
+
+// Returns the time of the next delivery attempt.
+func (a announcement) due() time.Time {
+	if !a.RetryAt.IsZero() {
+		return a.RetryAt
+	}
+	return a.When
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbAdd(place, creator, when int64, what, role string) (int64, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var _role any
+	if role != "" {
+		_role = role
+	}
+
+	var id int64
+	err := db.DB.QueryRow(`
+	INSERT INTO cmd_announce_announcements(place, creator, time, what, role)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id;`, place, creator, when, what, _role).Scan(&id)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int64("creator", creator).
+		Int64("when", when).
+		Str("what", what).
+		Str("role", role).
+		Msg("added announcement")
+
+	if err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+func scanAnnouncements(rows *sql.Rows) ([]announcement, error) {
+	var as []announcement
+	for rows.Next() {
+		var id, place, creator, timestamp int64
+		var what string
+		var role sql.NullString
+		var attempts int
+		var retryAt sql.NullInt64
+		var dead bool
+		err := rows.Scan(&id, &place, &creator, &timestamp, &what, &role, &attempts, &retryAt, &dead)
+		if err != nil {
+			return nil, err
+		}
+		a := announcement{
+			ID:       id,
+			Place:    place,
+			Creator:  creator,
+			When:     time.Unix(timestamp, 0).UTC(),
+			What:     what,
+			Role:     role.String,
+			Attempts: attempts,
+			Dead:     dead,
+		}
+		if retryAt.Valid {
+			a.RetryAt = time.Unix(retryAt.Int64, 0).UTC()
+		}
+		as = append(as, a)
+		log.Debug().Interface("announcement", a).Msg("found announcement")
+	}
+	return as, nil
+}
+
+func dbList(place int64) ([]announcement, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, place, creator, time, what, role, attempts, retry_at, dead
+		FROM cmd_announce_announcements
+		WHERE place = $1
+		ORDER BY time
+	`, place)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	as, err := scanAnnouncements(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int("#announcements", len(as)).
+		Msg("got announcements")
+
+	return as, err
+}
+
+// Returns all the announcements that are not dead and are due before the
+// given time.
+func dbUpcoming(before int64) ([]announcement, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, place, creator, time, what, role, attempts, retry_at, dead
+		FROM cmd_announce_announcements
+		WHERE dead = $1 and COALESCE(retry_at, time) < $2
+	`, false, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	as, err := scanAnnouncements(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("before", before).
+		Int("#announcements", len(as)).
+		Msg("got upcoming announcements")
+
+	return as, err
+}
+
+// Returns the announcement with the given id, if it doesn't exist then it
+// returns false.
+func dbGet(id int64) (announcement, bool, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, place, creator, time, what, role, attempts, retry_at, dead
+		FROM cmd_announce_announcements
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return announcement{}, false, err
+	}
+	defer rows.Close()
+
+	as, err := scanAnnouncements(rows)
+	if err != nil {
+		return announcement{}, false, err
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int("#announcements", len(as)).
+		Msg("got announcement")
+
+	if len(as) == 0 {
+		return announcement{}, false, err
+	}
+	return as[0], true, err
+}
+
+// Deletes the announcement if it belongs to the place, returns false if
+// nothing was deleted.
+func dbDelete(id, place int64) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_announce_announcements
+		WHERE id = $1 and place = $2`, id, place)
+
+	var n int64
+	if err == nil {
+		n, err = res.RowsAffected()
+	}
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int64("place", place).
+		Int64("deleted", n).
+		Msg("deleted announcement")
+
+	return n > 0, err
+}
+
+// Records a failed delivery attempt. If dead is true then the announcement
+// will not be retried, otherwise it will be retried at retryAt.
+func dbFailed(id int64, attempts int, retryAt int64, dead bool, reason string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var _retryAt any
+	if !dead {
+		_retryAt = retryAt
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_announce_announcements
+		SET attempts = $1, retry_at = $2, dead = $3, error = $4
+		WHERE id = $5`, attempts, _retryAt, dead, reason, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int("attempts", attempts).
+		Int64("retry_at", retryAt).
+		Bool("dead", dead).
+		Str("reason", reason).
+		Msg("recorded failed announcement delivery")
+
+	return err
+}
+
+/////////
+//     //
+// run //
+//     //
+/////////
+
+// Add schedules an announcement for the given place. The when string is parsed
+// in the creator's timezone. If role is not empty, then it will be mentioned
+// when the announcement is made.
+func Add(when, what, role string, creator, placeExact, placeLogical int64) (time.Time, int64, core.Urr, error) {
+	t, urr, err := ctime.Time(when, creator)
+	if urr != nil || err != nil {
+		return t, -1, urr, err
+	}
+
+	if t.Before(time.Now()) {
+		return t, -1, ctime.UrrOldTime, nil
+	}
+
+	id, err := dbAdd(placeExact, creator, t.UTC().Unix(), what, role)
+
+	// in case the announcement needs to happen close to immediately
+	announcements.Poll()
+
+	return t, id, nil, err
+}
+
+// List returns all the announcements that are scheduled for the place,
+// including the ones that failed to be delivered.
+func List(place int64) ([]announcement, core.Urr, error) {
+	as, err := dbList(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(as) == 0 {
+		return nil, UrrNoAnnouncements, nil
+	}
+	return as, nil, nil
+}
+
+// Cancel deletes the announcement, it must have been scheduled in the given
+// place.
+func Cancel(id, place int64) (core.Urr, error) {
+	deleted, err := dbDelete(id, place)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return UrrAnnouncementNotFound, nil
+	}
+	return nil, nil
+}
+
+var announcements = &core.Scheduler{
+	Name:        "announcements",
+	MaxAttempts: 5,
+	Backoff:     30 * time.Second,
+	Lookahead:   5 * time.Minute,
+	Upcoming:    announceUpcoming,
+	Run:         announceRun,
+	Retry:       announceRetry,
+	Dead:        announceDead,
+}
+
+// How long a delivery is remembered for, must outlast all the retries.
+const announceDeliveredExpiry = 24 * time.Hour
+
+func announceDeliveredKey(a announcement) string {
+	return fmt.Sprintf("cmd_announce-delivered-%d-%d", a.ID, a.When.Unix())
+}
+
+func announceUpcoming(before time.Time) ([]core.ScheduledTask, error) {
+	as, err := dbUpcoming(before.Unix())
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]core.ScheduledTask, len(as))
+	for i, a := range as {
+		tasks[i] = core.ScheduledTask{
+			ID:       a.ID,
+			When:     a.due(),
+			Attempts: a.Attempts,
+		}
+	}
+	return tasks, nil
+}
+
+func announceRun(t core.ScheduledTask) error {
+	a, exists, err := dbGet(t.ID)
+	if err != nil {
+		return err
+	}
+	// the announcement may have been cancelled in the meantime
+	if !exists || a.Dead || a.due().After(time.Now()) {
+		return nil
+	}
+
+	what := a.What
+	if time.Since(a.When) > time.Minute {
+		what = fmt.Sprintf("%s (late, was due on %s)", what, a.When.Format(time.RFC1123))
+	}
+
+	ctx := context.Background()
+	key := announceDeliveredKey(a)
+
+	delivered, err := core.RDB.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	// a previous attempt made the announcement but failed to delete it, so
+	// only the deletion is left
+	if delivered > 0 {
+		_, err = dbDelete(a.ID, a.Place)
+		return err
+	}
+
+	if err := core.Frontends.Announce(a.Place, what, a.Role); err != nil {
+		return err
+	}
+
+	// returning an error from here on would get the announcement made again,
+	// instead it is remembered and the deletion is retried the next time the
+	// announcement is picked up
+	if err := core.RDB.Set(ctx, key, nil, announceDeliveredExpiry).Err(); err != nil {
+		log.Error().Err(err).Interface("announcement", a).Msg("failed to remember delivery")
+	}
+	if _, err := dbDelete(a.ID, a.Place); err != nil {
+		log.Error().Err(err).Interface("announcement", a).Msg("failed to delete delivered announcement")
+	}
+	return nil
+}
+
+func announceRetry(t core.ScheduledTask, attempts int, at time.Time, err error) error {
+	return dbFailed(t.ID, attempts, at.Unix(), false, err.Error())
+}
+
+func announceDead(t core.ScheduledTask, attempts int, err error) error {
+	return dbFailed(t.ID, attempts, 0, true, err.Error())
+}
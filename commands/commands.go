@@ -4,21 +4,29 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/kvlach/janitorjeff/commands/alert"
+	"github.com/kvlach/janitorjeff/commands/announce"
 	"github.com/kvlach/janitorjeff/commands/audio"
 	"github.com/kvlach/janitorjeff/commands/category"
 	"github.com/kvlach/janitorjeff/commands/connect"
 	"github.com/kvlach/janitorjeff/commands/custom-command"
 	"github.com/kvlach/janitorjeff/commands/discord"
 	"github.com/kvlach/janitorjeff/commands/god"
+	"github.com/kvlach/janitorjeff/commands/group"
 	"github.com/kvlach/janitorjeff/commands/help"
 	"github.com/kvlach/janitorjeff/commands/id"
 	"github.com/kvlach/janitorjeff/commands/lens"
+	"github.com/kvlach/janitorjeff/commands/link"
 	"github.com/kvlach/janitorjeff/commands/mask"
+	"github.com/kvlach/janitorjeff/commands/mod"
 	"github.com/kvlach/janitorjeff/commands/nick"
 	"github.com/kvlach/janitorjeff/commands/paintball"
 	"github.com/kvlach/janitorjeff/commands/prefix"
+	"github.com/kvlach/janitorjeff/commands/redeem"
+	"github.com/kvlach/janitorjeff/commands/relay"
 	"github.com/kvlach/janitorjeff/commands/rps"
 	"github.com/kvlach/janitorjeff/commands/search"
+	"github.com/kvlach/janitorjeff/commands/shoutout"
 	"github.com/kvlach/janitorjeff/commands/streak"
 	"github.com/kvlach/janitorjeff/commands/teleport"
 	"github.com/kvlach/janitorjeff/commands/time"
@@ -34,6 +42,10 @@ import (
 )
 
 var Commands = core.CommandsStatic{
+	alert.Advanced,
+
+	announce.Advanced,
+
 	audio.Advanced,
 
 	category.Normal,
@@ -49,6 +61,8 @@ var Commands = core.CommandsStatic{
 	god.Normal,
 	god.Admin,
 
+	group.Advanced,
+
 	help.Normal,
 	help.Advanced,
 	help.Admin,
@@ -57,8 +71,12 @@ var Commands = core.CommandsStatic{
 
 	lens.Advanced,
 
+	link.Normal,
+
 	mask.Admin,
 
+	mod.Advanced,
+
 	nick.Normal,
 	nick.Advanced,
 	nick.Admin,
@@ -69,10 +87,17 @@ var Commands = core.CommandsStatic{
 	prefix.Advanced,
 	prefix.Admin,
 
+	redeem.Advanced,
+
+	relay.Advanced,
+
 	rps.Normal,
 
 	search.Advanced,
 
+	shoutout.Advanced,
+	shoutout.Normal,
+
 	streak.Admin,
 	streak.Advanced,
 	streak.Normal,
@@ -87,6 +112,7 @@ var Commands = core.CommandsStatic{
 	title.Advanced,
 
 	twitch.Admin,
+	twitch.Advanced,
 
 	urban_dictionary.Normal,
 	urban_dictionary.Advanced,
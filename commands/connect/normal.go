@@ -157,6 +157,14 @@ func (normalTwitch) core(m *core.EventMessage) (string, error) {
 		"channel:moderate",
 		"moderation:read",
 		"channel:read:redemptions",
+		"channel:manage:redemptions",
+		"moderator:read:followers",
+		"channel:read:subscriptions",
+		"bits:read",
+		"moderator:manage:shoutouts",
+		"moderator:manage:banned_users",
+		"moderator:manage:chat_settings",
+		"moderator:manage:chat_messages",
 	}
 
 	state, err := twitch.NewState()
@@ -2,7 +2,9 @@ package custom_command
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kvlach/janitorjeff/core"
 	"github.com/kvlach/janitorjeff/frontends/discord"
@@ -62,6 +64,13 @@ func (advanced) Children() core.CommandsStatic {
 		AdvancedDelete,
 		AdvancedList,
 		AdvancedHistory,
+		AdvancedRevert,
+		AdvancedUndelete,
+		AdvancedArgs,
+		AdvancedRole,
+		AdvancedCooldown,
+		AdvancedExport,
+		AdvancedImport,
 	}
 }
 
@@ -70,20 +79,32 @@ func (c advanced) Init() error {
 	return nil
 }
 
+// Returns the place whose custom commands are used here, which is the group's
+// authority if custom commands are shared with a place group.
+func placeHere(m *core.EventMessage) (int64, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return -1, err
+	}
+	return core.DB.PlaceShared(here, core.ShareCustomCommands)
+}
+
 func (advanced) writeCustomCommand(m *core.EventMessage) {
 	fields := m.Fields()
 
-	if len(fields) > 1 {
+	if len(fields) == 0 {
 		return
 	}
 
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return
 	}
 
-	resp, err := Show(here, fields[0])
-	if err != nil {
+	// the first field is the trigger, the rest are passed to the response as
+	// arguments
+	resp, urr, err := Run(here, m.Author, fields[0], fields[1:])
+	if err != nil || urr == UrrNotPermitted || urr == UrrCooldown {
 		return
 	}
 
@@ -129,7 +150,13 @@ func (c advancedAdd) Category() core.CommandCategory {
 }
 
 func (advancedAdd) Examples() []string {
-	return nil
+	return []string{
+		"!hug $(user) hugs $(args 1)",
+		"!deaths died $(count) times",
+		"!roll $(user) rolled $(random 1 100)",
+		"!clock it's $(time Europe/Athens)",
+		"!streak $(user) has a streak of $(streak)",
+	}
 }
 
 func (advancedAdd) Parent() core.CommandStatic {
@@ -191,6 +218,10 @@ func (advancedAdd) fmt(urr core.Urr, trigger string) string {
 		return fmt.Sprintf("Custom command %s already exists.", trigger)
 	case UrrBuiltinCommand:
 		return fmt.Sprintf("Command %s already exists as a built-in command.", trigger)
+	case UrrUnknownVariable:
+		return fmt.Sprintf("The response of %s contains an unknown variable.", trigger)
+	case UrrInvalidVariable:
+		return fmt.Sprintf("The response of %s contains a variable with invalid arguments.", trigger)
 	default:
 		return "Something went wrong..."
 	}
@@ -205,7 +236,7 @@ func (c advancedAdd) core(m *core.EventMessage) (string, core.Urr, error) {
 		return "", nil, err
 	}
 
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return "", nil, err
 	}
@@ -309,6 +340,10 @@ func (advancedEdit) fmt(urr core.Urr, trigger string) string {
 		return fmt.Sprintf("Custom command %s has been modified.", trigger)
 	case UrrTriggerNotFound:
 		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	case UrrUnknownVariable:
+		return fmt.Sprintf("The response of %s contains an unknown variable.", trigger)
+	case UrrInvalidVariable:
+		return fmt.Sprintf("The response of %s contains a variable with invalid arguments.", trigger)
 	default:
 		return "Something went wrong..."
 	}
@@ -323,7 +358,7 @@ func (advancedEdit) core(m *core.EventMessage) (string, core.Urr, error) {
 		return "", nil, err
 	}
 
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return "", nil, err
 	}
@@ -435,7 +470,7 @@ func (advancedDelete) fmt(urr core.Urr, trigger string) string {
 func (advancedDelete) core(m *core.EventMessage) (string, core.Urr, error) {
 	trigger := m.Command.Args[0]
 
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return "", nil, err
 	}
@@ -545,7 +580,7 @@ func (c advancedList) text(m *core.EventMessage) (string, core.Urr, error) {
 }
 
 func (c advancedList) core(m *core.EventMessage) ([]string, error) {
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return nil, err
 	}
@@ -613,7 +648,7 @@ func (c advancedHistory) Run(m *core.EventMessage) (any, core.Urr, error) {
 	case discord.Frontend.Type():
 		return c.discord(m)
 	default:
-		return nil, nil, nil
+		return c.text(m)
 	}
 }
 
@@ -653,14 +688,17 @@ func (c advancedHistory) discord(m *core.EventMessage) (*dg.MessageEmbed, core.U
 	for i := 0; i < len(history); i++ {
 		hist := history[i]
 
+		// versions are referenced by revert
+		version := fmt.Sprintf("#%d ", i+1)
+
 		if i == 0 {
 			// creation
-			action = append(action, "created")
+			action = append(action, version+"created")
 			response = append(response, hist.response)
 			when = append(when, formatTime(hist.created))
 		} else if history[i-1].deleted == hist.created {
 			// modification
-			action = append(action, "edited")
+			action = append(action, version+"edited")
 			response = append(response, hist.response)
 			when = append(when, formatTime(hist.created))
 		} else {
@@ -669,7 +707,7 @@ func (c advancedHistory) discord(m *core.EventMessage) (*dg.MessageEmbed, core.U
 			response = append(response, "")
 			when = append(when, formatTime(history[i-1].deleted))
 
-			action = append(action, "created")
+			action = append(action, version+"created")
 			response = append(response, hist.response)
 			when = append(when, formatTime(hist.created))
 		}
@@ -705,10 +743,32 @@ func (c advancedHistory) discord(m *core.EventMessage) (*dg.MessageEmbed, core.U
 	return embed, nil, nil
 }
 
+func (c advancedHistory) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, history, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(history) == 0 {
+		return fmt.Sprintf("Custom command '%s' has no history.", trigger), nil, nil
+	}
+
+	versions := make([]string, len(history))
+	for i, hist := range history {
+		versions[i] = fmt.Sprintf("#%d '%s'", i+1, hist.response)
+	}
+	reply := strings.Join(versions, ", ")
+
+	if history[len(history)-1].deleted != 0 {
+		reply += " (deleted)"
+	}
+	return reply, nil, nil
+}
+
 func (advancedHistory) core(m *core.EventMessage) (string, []customCommand, error) {
 	trigger := m.Command.Args[0]
 
-	here, err := m.Here.ScopeLogical()
+	here, err := placeHere(m)
 	if err != nil {
 		return trigger, nil, err
 	}
@@ -716,3 +776,1418 @@ func (advancedHistory) core(m *core.EventMessage) (string, []customCommand, erro
 	history, err := History(here, trigger)
 	return trigger, history, err
 }
+
+////////////
+//        //
+// revert //
+//        //
+////////////
+
+var AdvancedRevert = advancedRevert{}
+
+type advancedRevert struct{}
+
+func (c advancedRevert) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRevert) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRevert) Names() []string {
+	return []string{
+		"revert",
+		"rollback",
+	}
+}
+
+func (advancedRevert) Description() string {
+	return "Revert a command's response to an earlier version from its history."
+}
+
+func (advancedRevert) UsageArgs() string {
+	return "<trigger> <version>"
+}
+
+func (c advancedRevert) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRevert) Examples() []string {
+	return []string{
+		"!discord 2",
+	}
+}
+
+func (advancedRevert) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedRevert) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRevert) Init() error {
+	return nil
+}
+
+func (c advancedRevert) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedRevert) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, response, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, response),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedRevert) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, response, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, response), urr, nil
+}
+
+func (advancedRevert) fmt(urr core.Urr, trigger, response string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s has been reverted to: %s", trigger, response)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	case UrrVersionNotFound:
+		return fmt.Sprintf("Custom command %s doesn't have such a version, check its history.", trigger)
+	case UrrUnknownVariable:
+		return fmt.Sprintf("That version of %s contains an unknown variable.", trigger)
+	case UrrInvalidVariable:
+		return fmt.Sprintf("That version of %s contains a variable with invalid arguments.", trigger)
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedRevert) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	version, err := strconv.Atoi(strings.TrimPrefix(m.Command.Args[1], "#"))
+	if err != nil {
+		return trigger, "", UrrVersionNotFound, nil
+	}
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	response, urr, err := Revert(here, author, trigger, version)
+	return trigger, response, urr, err
+}
+
+//////////////
+//          //
+// undelete //
+//          //
+//////////////
+
+var AdvancedUndelete = advancedUndelete{}
+
+type advancedUndelete struct{}
+
+func (c advancedUndelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedUndelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedUndelete) Names() []string {
+	return []string{
+		"undelete",
+		"restore",
+	}
+}
+
+func (advancedUndelete) Description() string {
+	return "Restore a deleted command."
+}
+
+func (advancedUndelete) UsageArgs() string {
+	return "<trigger>"
+}
+
+func (c advancedUndelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedUndelete) Examples() []string {
+	return nil
+}
+
+func (advancedUndelete) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedUndelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedUndelete) Init() error {
+	return nil
+}
+
+func (c advancedUndelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedUndelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, response, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, response),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedUndelete) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, response, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, response), urr, nil
+}
+
+func (advancedUndelete) fmt(urr core.Urr, trigger, response string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s has been restored: %s", trigger, response)
+	case UrrTriggerExists:
+		return fmt.Sprintf("Custom command %s already exists.", trigger)
+	case UrrNoHistory:
+		return fmt.Sprintf("Custom command %s has never existed.", trigger)
+	case UrrBuiltinCommand:
+		return fmt.Sprintf("Command %s now collides with a built-in command.", trigger)
+	case UrrUnknownVariable:
+		return fmt.Sprintf("The last version of %s contains an unknown variable, add it again instead.", trigger)
+	case UrrInvalidVariable:
+		return fmt.Sprintf("The last version of %s contains a variable with invalid arguments, add it again instead.", trigger)
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedUndelete) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	response, urr, err := Undelete(here, author, trigger)
+	return trigger, response, urr, err
+}
+
+//////////
+//      //
+// args //
+//      //
+//////////
+
+var AdvancedArgs = advancedArgs{}
+
+type advancedArgs struct{}
+
+func (c advancedArgs) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedArgs) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedArgs) Names() []string {
+	return []string{
+		"args",
+		"arguments",
+	}
+}
+
+func (advancedArgs) Description() string {
+	return "Control how many arguments a command accepts."
+}
+
+func (c advancedArgs) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedArgs) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedArgs) Examples() []string {
+	return nil
+}
+
+func (advancedArgs) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedArgs) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedArgsShow,
+		AdvancedArgsSet,
+	}
+}
+
+func (advancedArgs) Init() error {
+	return nil
+}
+
+func (advancedArgs) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func formatArgsMax(max int) string {
+	if max < 0 {
+		return "no maximum"
+	}
+	return fmt.Sprint(max)
+}
+
+///////////////
+//           //
+// args show //
+//           //
+///////////////
+
+var AdvancedArgsShow = advancedArgsShow{}
+
+type advancedArgsShow struct{}
+
+func (c advancedArgsShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedArgsShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedArgsShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedArgsShow) Description() string {
+	return "Show how many arguments a command accepts and its usage message."
+}
+
+func (advancedArgsShow) UsageArgs() string {
+	return "<trigger>"
+}
+
+func (c advancedArgsShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedArgsShow) Examples() []string {
+	return nil
+}
+
+func (advancedArgsShow) Parent() core.CommandStatic {
+	return AdvancedArgs
+}
+
+func (advancedArgsShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedArgsShow) Init() error {
+	return nil
+}
+
+func (c advancedArgsShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedArgsShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, min, max, usage, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, min, max, usage),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedArgsShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, min, max, usage, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, min, max, usage), urr, nil
+}
+
+func (advancedArgsShow) fmt(urr core.Urr, trigger string, min, max int, usage string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s accepts a minimum of %d and %s arguments. Usage message: %s",
+			trigger, min, formatArgsMax(max), usage)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedArgsShow) core(m *core.EventMessage) (string, int, int, string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", 0, 0, "", nil, err
+	}
+
+	min, max, usage, urr, err := ArgsShow(here, trigger)
+	return trigger, min, max, usage, urr, err
+}
+
+//////////////
+//          //
+// args set //
+//          //
+//////////////
+
+var AdvancedArgsSet = advancedArgsSet{}
+
+type advancedArgsSet struct{}
+
+func (c advancedArgsSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedArgsSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedArgsSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedArgsSet) Description() string {
+	return "Set how many arguments a command accepts and optionally its usage message."
+}
+
+func (advancedArgsSet) UsageArgs() string {
+	return "<trigger> <min> <max|none> [usage]"
+}
+
+func (c advancedArgsSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedArgsSet) Examples() []string {
+	return []string{
+		"!hug 1 1 Usage: !hug <person>",
+		"!so 1 none",
+	}
+}
+
+func (advancedArgsSet) Parent() core.CommandStatic {
+	return AdvancedArgs
+}
+
+func (advancedArgsSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedArgsSet) Init() error {
+	return nil
+}
+
+func (c advancedArgsSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 3 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedArgsSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedArgsSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger), urr, nil
+}
+
+func (advancedArgsSet) fmt(urr core.Urr, trigger string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Updated the arguments of custom command %s.", trigger)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	case UrrArgsRange:
+		return "Expected a non-negative minimum and a maximum that is either 'none' or at least equal to the minimum."
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedArgsSet) core(m *core.EventMessage) (string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	min, err := strconv.Atoi(m.Command.Args[1])
+	if err != nil {
+		return trigger, UrrArgsRange, nil
+	}
+
+	max := -1
+	if m.Command.Args[2] != "none" {
+		max, err = strconv.Atoi(m.Command.Args[2])
+		if err != nil || max < 0 {
+			return trigger, UrrArgsRange, nil
+		}
+	}
+
+	usage := m.RawArgs(3)
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	urr, err := ArgsSet(here, trigger, min, max, usage)
+	return trigger, urr, err
+}
+
+//////////
+//      //
+// role //
+//      //
+//////////
+
+var AdvancedRole = advancedRole{}
+
+type advancedRole struct{}
+
+func (c advancedRole) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRole) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRole) Names() []string {
+	return []string{
+		"role",
+		"permission",
+	}
+}
+
+func (advancedRole) Description() string {
+	return "Control who is allowed to use a command."
+}
+
+func (c advancedRole) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedRole) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRole) Examples() []string {
+	return nil
+}
+
+func (advancedRole) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedRole) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedRoleShow,
+		AdvancedRoleSet,
+	}
+}
+
+func (advancedRole) Init() error {
+	return nil
+}
+
+func (advancedRole) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////
+//           //
+// role show //
+//           //
+///////////////
+
+var AdvancedRoleShow = advancedRoleShow{}
+
+type advancedRoleShow struct{}
+
+func (c advancedRoleShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRoleShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRoleShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedRoleShow) Description() string {
+	return "Show the role required in order to use a command."
+}
+
+func (advancedRoleShow) UsageArgs() string {
+	return "<trigger>"
+}
+
+func (c advancedRoleShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRoleShow) Examples() []string {
+	return nil
+}
+
+func (advancedRoleShow) Parent() core.CommandStatic {
+	return AdvancedRole
+}
+
+func (advancedRoleShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRoleShow) Init() error {
+	return nil
+}
+
+func (c advancedRoleShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedRoleShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, role, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, role),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedRoleShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, role, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, role), urr, nil
+}
+
+func (advancedRoleShow) fmt(urr core.Urr, trigger, role string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s can be used by: %s", trigger, role)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedRoleShow) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	role, urr, err := RoleShow(here, trigger)
+	return trigger, role, urr, err
+}
+
+//////////////
+//          //
+// role set //
+//          //
+//////////////
+
+var AdvancedRoleSet = advancedRoleSet{}
+
+type advancedRoleSet struct{}
+
+func (c advancedRoleSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRoleSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRoleSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedRoleSet) Description() string {
+	return "Set the role required in order to use a command."
+}
+
+func (advancedRoleSet) UsageArgs() string {
+	return "<trigger> (" + strings.Join(Roles, " | ") + ")"
+}
+
+func (c advancedRoleSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRoleSet) Examples() []string {
+	return []string{
+		"!raid moderator",
+	}
+}
+
+func (advancedRoleSet) Parent() core.CommandStatic {
+	return AdvancedRole
+}
+
+func (advancedRoleSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRoleSet) Init() error {
+	return nil
+}
+
+func (c advancedRoleSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedRoleSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, role, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, role),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedRoleSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, role, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, role), urr, nil
+}
+
+func (advancedRoleSet) fmt(urr core.Urr, trigger, role string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s can now be used by: %s", trigger, role)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	case UrrInvalidRole:
+		return fmt.Sprintf("Unknown role '%s', expected one of: %s", role, strings.Join(Roles, ", "))
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedRoleSet) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+	role := strings.ToLower(m.Command.Args[1])
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	urr, err := RoleSet(here, trigger, role)
+	return trigger, role, urr, err
+}
+
+//////////////
+//          //
+// cooldown //
+//          //
+//////////////
+
+var AdvancedCooldown = advancedCooldown{}
+
+type advancedCooldown struct{}
+
+func (c advancedCooldown) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldown) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldown) Names() []string {
+	return []string{
+		"cooldown",
+		"cd",
+	}
+}
+
+func (advancedCooldown) Description() string {
+	return "Control how often a command can be used."
+}
+
+func (c advancedCooldown) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedCooldown) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldown) Examples() []string {
+	return nil
+}
+
+func (advancedCooldown) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedCooldown) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedCooldownShow,
+		AdvancedCooldownSet,
+	}
+}
+
+func (advancedCooldown) Init() error {
+	return nil
+}
+
+func (advancedCooldown) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////////
+//               //
+// cooldown show //
+//               //
+///////////////////
+
+var AdvancedCooldownShow = advancedCooldownShow{}
+
+type advancedCooldownShow struct{}
+
+func (c advancedCooldownShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldownShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldownShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedCooldownShow) Description() string {
+	return "Show the global and per-user cooldowns of a command."
+}
+
+func (advancedCooldownShow) UsageArgs() string {
+	return "<trigger>"
+}
+
+func (c advancedCooldownShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldownShow) Examples() []string {
+	return nil
+}
+
+func (advancedCooldownShow) Parent() core.CommandStatic {
+	return AdvancedCooldown
+}
+
+func (advancedCooldownShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCooldownShow) Init() error {
+	return nil
+}
+
+func (c advancedCooldownShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedCooldownShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, global, user, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger, global, user),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedCooldownShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, global, user, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger, global, user), urr, nil
+}
+
+func (advancedCooldownShow) fmt(urr core.Urr, trigger string, global, user time.Duration) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Custom command %s has a global cooldown of %s and a per-user cooldown of %s.",
+			trigger, global, user)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedCooldownShow) core(m *core.EventMessage) (string, time.Duration, time.Duration, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", 0, 0, nil, err
+	}
+
+	global, user, urr, err := CooldownShow(here, trigger)
+	return trigger, global, user, urr, err
+}
+
+//////////////////
+//              //
+// cooldown set //
+//              //
+//////////////////
+
+var AdvancedCooldownSet = advancedCooldownSet{}
+
+type advancedCooldownSet struct{}
+
+func (c advancedCooldownSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldownSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldownSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedCooldownSet) Description() string {
+	return "Set the global and per-user cooldowns of a command, 0 disables a cooldown."
+}
+
+func (advancedCooldownSet) UsageArgs() string {
+	return "<trigger> <global> <user>"
+}
+
+func (c advancedCooldownSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldownSet) Examples() []string {
+	return []string{
+		"!hug 5s 1m",
+		"!discord 30s 0",
+	}
+}
+
+func (advancedCooldownSet) Parent() core.CommandStatic {
+	return AdvancedCooldown
+}
+
+func (advancedCooldownSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCooldownSet) Init() error {
+	return nil
+}
+
+func (c advancedCooldownSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 3 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedCooldownSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	trigger, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	trigger = discord.PlaceInBackticks(trigger)
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, trigger),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedCooldownSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	trigger, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	trigger = fmt.Sprintf("'%s'", trigger)
+
+	return c.fmt(urr, trigger), urr, nil
+}
+
+func (advancedCooldownSet) fmt(urr core.Urr, trigger string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Updated the cooldowns of custom command %s.", trigger)
+	case UrrTriggerNotFound:
+		return fmt.Sprintf("Custom command %s doesn't exist.", trigger)
+	case UrrInvalidCooldown:
+		return "Expected cooldowns in the form of 1m30s, use 0 to disable a cooldown."
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedCooldownSet) core(m *core.EventMessage) (string, core.Urr, error) {
+	trigger := m.Command.Args[0]
+
+	global, err := time.ParseDuration(m.Command.Args[1])
+	if err != nil {
+		return trigger, UrrInvalidCooldown, nil
+	}
+	user, err := time.ParseDuration(m.Command.Args[2])
+	if err != nil {
+		return trigger, UrrInvalidCooldown, nil
+	}
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	urr, err := CooldownSet(here, trigger, global, user)
+	return trigger, urr, err
+}
+
+////////////
+//        //
+// export //
+//        //
+////////////
+
+var AdvancedExport = advancedExport{}
+
+type advancedExport struct{}
+
+func (c advancedExport) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedExport) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedExport) Names() []string {
+	return []string{
+		"export",
+	}
+}
+
+func (advancedExport) Description() string {
+	return "Export all the commands as JSON or CSV."
+}
+
+func (advancedExport) UsageArgs() string {
+	return "[json | csv]"
+}
+
+func (c advancedExport) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedExport) Examples() []string {
+	return []string{
+		"",
+		"csv",
+	}
+}
+
+func (advancedExport) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedExport) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedExport) Init() error {
+	return nil
+}
+
+func (c advancedExport) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedExport) discord(m *core.EventMessage) (string, core.Urr, error) {
+	format, data, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+	return fmt.Sprintf("```%s\n%s\n```", format, data), nil, nil
+}
+
+func (c advancedExport) text(m *core.EventMessage) (string, core.Urr, error) {
+	_, data, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+	return data, nil, nil
+}
+
+func (advancedExport) fmt(urr core.Urr) string {
+	switch urr {
+	case UrrInvalidFormat:
+		return "Expected either json or csv as the format."
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedExport) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	format := FormatJSON
+	if len(m.Command.Args) > 0 {
+		format = strings.ToLower(m.Command.Args[0])
+	}
+
+	here, err := placeHere(m)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	data, urr, err := Export(here, format)
+	return format, data, urr, err
+}
+
+////////////
+//        //
+// import //
+//        //
+////////////
+
+var AdvancedImport = advancedImport{}
+
+type advancedImport struct{}
+
+func (c advancedImport) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedImport) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedImport) Names() []string {
+	return []string{
+		"import",
+	}
+}
+
+func (advancedImport) Description() string {
+	return "Import commands from JSON or CSV, as created by the export command."
+}
+
+func (advancedImport) UsageArgs() string {
+	return "<data>"
+}
+
+func (c advancedImport) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedImport) Examples() []string {
+	return []string{
+		`[{"trigger": "!hi", "response": "hello $(user)"}]`,
+		`!hi,hello $(user)`,
+	}
+}
+
+func (advancedImport) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedImport) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedImport) Init() error {
+	return nil
+}
+
+func (c advancedImport) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedImport) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	res, urr, err := c.core(m)
+	if err != nil {
+		return nil, urr, err
+	}
+
+	for _, triggers := range [][]string{res.Imported, res.Exists, res.Builtin, res.Invalid} {
+		for i := range triggers {
+			triggers[i] = discord.PlaceInBackticks(triggers[i])
+		}
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, res, "\n"),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedImport) text(m *core.EventMessage) (string, core.Urr, error) {
+	res, urr, err := c.core(m)
+	if err != nil {
+		return "", urr, err
+	}
+
+	for _, triggers := range [][]string{res.Imported, res.Exists, res.Builtin, res.Invalid} {
+		for i := range triggers {
+			triggers[i] = fmt.Sprintf("'%s'", triggers[i])
+		}
+	}
+
+	return c.fmt(urr, res, " "), urr, nil
+}
+
+func (advancedImport) fmt(urr core.Urr, res ImportResult, sep string) string {
+	switch urr {
+	case nil:
+		var lines []string
+		if len(res.Imported) == 0 {
+			lines = append(lines, "No commands were imported.")
+		} else {
+			lines = append(lines, "Imported: "+strings.Join(res.Imported, ", "))
+		}
+		if len(res.Exists) != 0 {
+			lines = append(lines, "Skipped, already exist: "+strings.Join(res.Exists, ", "))
+		}
+		if len(res.Builtin) != 0 {
+			lines = append(lines, "Skipped, built-in commands: "+strings.Join(res.Builtin, ", "))
+		}
+		if len(res.Invalid) != 0 {
+			lines = append(lines, "Skipped, invalid: "+strings.Join(res.Invalid, ", "))
+		}
+		return strings.Join(lines, sep)
+	case UrrInvalidData:
+		return "Couldn't parse the commands, expected the JSON or CSV produced by the export command."
+	default:
+		return "Something went wrong..."
+	}
+}
+
+func (advancedImport) core(m *core.EventMessage) (ImportResult, core.Urr, error) {
+	data := m.RawArgs(0)
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return ImportResult{}, nil, err
+	}
+
+	here, err := placeHere(m)
+	if err != nil {
+		return ImportResult{}, nil, err
+	}
+
+	return Import(here, author, data)
+}
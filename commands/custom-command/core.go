@@ -1,7 +1,11 @@
 package custom_command
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/kvlach/janitorjeff/core"
 )
@@ -10,8 +14,32 @@ var (
 	UrrTriggerExists   = core.UrrNew("trigger already exists")
 	UrrBuiltinCommand  = core.UrrNew("trigger collides with a built-in command")
 	UrrTriggerNotFound = core.UrrNew("trigger was not found")
+	UrrArgsRange       = core.UrrNew("invalid argument range")
+	UrrArgsCount       = core.UrrNew("wrong number of arguments")
+	UrrInvalidRole     = core.UrrNew("invalid role")
+	UrrInvalidCooldown = core.UrrNew("invalid cooldown")
+	UrrNotPermitted    = core.UrrNew("not permitted to use this trigger")
+	UrrCooldown        = core.UrrNew("trigger is on cooldown")
+	UrrVersionNotFound = core.UrrNew("version was not found")
+	UrrNoHistory       = core.UrrNew("trigger has never existed")
 )
 
+// The roles that may be required in order to use a trigger, from the least to
+// the most privileged.
+const (
+	RoleEveryone   = "everyone"
+	RoleSubscriber = "subscriber"
+	RoleModerator  = "moderator"
+	RoleAdmin      = "admin"
+)
+
+var Roles = []string{
+	RoleEveryone,
+	RoleSubscriber,
+	RoleModerator,
+	RoleAdmin,
+}
+
 // Check if a string corresponds to a command name. Doesn't check sub-commands.
 func isCommand(t core.CommandType, s string) bool {
 	for _, c := range core.Commands {
@@ -62,6 +90,10 @@ func Add(place, creator int64, trigger, response string) (core.Urr, error) {
 		return UrrBuiltinCommand, nil
 	}
 
+	if urr := templateValidate(response); urr != nil {
+		return urr, nil
+	}
+
 	return nil, dbAdd(place, creator, trigger, response)
 }
 
@@ -73,6 +105,9 @@ func Edit(place, editor int64, trigger, response string) (core.Urr, error) {
 	if !exists {
 		return UrrTriggerNotFound, nil
 	}
+	if urr := templateValidate(response); urr != nil {
+		return urr, nil
+	}
 	return nil, dbEdit(place, editor, trigger, response)
 }
 
@@ -95,8 +130,323 @@ func Show(place int64, trigger string) (string, error) {
 	return dbGetResponse(place, trigger)
 }
 
+func Exists(place int64, trigger string) (bool, error) {
+	return dbTriggerExists(place, trigger)
+}
+
+// Run returns the trigger's response with all of its variables evaluated.
+// The args are the fields that followed the trigger. If the number of args is
+// not within the trigger's limits, then the usage message is returned instead
+// along with UrrArgsCount. If the author doesn't have the required role or the
+// trigger is on cooldown, then UrrNotPermitted or UrrCooldown are returned
+// respectively.
+func Run(place int64, author core.Personifier, trigger string, args []string) (string, core.Urr, error) {
+	response, err := dbGetResponse(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+
+	role, err := dbRoleGet(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	permitted, err := hasRole(author, role)
+	if err != nil {
+		return "", nil, err
+	}
+	if !permitted {
+		return "", UrrNotPermitted, nil
+	}
+
+	person, err := author.Scope()
+	if err != nil {
+		return "", nil, err
+	}
+	started, err := cooldownStart(place, person, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if !started {
+		return "", UrrCooldown, nil
+	}
+
+	min, max, usage, err := dbArgsGet(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(args) < min || (max >= 0 && len(args) > max) {
+		if usage == "" {
+			usage = argsUsage(trigger, min, max)
+		}
+		// the cooldown has already started, so the usage message counts as a
+		// use and can't be spammed
+		return usage, UrrArgsCount, nil
+	}
+
+	d := &templateData{
+		place:   place,
+		trigger: trigger,
+		author:  author,
+		args:    args,
+	}
+	resp, err := templateRender(response, d)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return resp, nil, nil
+}
+
+// Checks if the author has at least the given role.
+func hasRole(author core.Personifier, role string) (bool, error) {
+	switch role {
+	case RoleEveryone:
+		return true, nil
+	case RoleSubscriber:
+		sub, err := author.Subscriber()
+		if err != nil || sub {
+			return sub, err
+		}
+		fallthrough
+	case RoleModerator:
+		mod, err := author.Moderator()
+		if err != nil || mod {
+			return mod, err
+		}
+		fallthrough
+	case RoleAdmin:
+		return author.Admin()
+	default:
+		return false, fmt.Errorf("unexpected role '%s'", role)
+	}
+}
+
+func cooldownKeyGlobal(place int64, trigger string) string {
+	return fmt.Sprintf("cmd_customcommand-cooldown-%d-%s", place, trigger)
+}
+
+func cooldownKeyUser(place, person int64, trigger string) string {
+	return fmt.Sprintf("cmd_customcommand-cooldown-%d-%s-%d", place, trigger, person)
+}
+
+// Starts the global and the person's cooldown, if the trigger has any. Returns
+// false if either one was already active, in which case neither is started.
+func cooldownStart(place, person int64, trigger string) (bool, error) {
+	global, user, err := dbCooldownGet(place, trigger)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := context.Background()
+	keyGlobal := cooldownKeyGlobal(place, trigger)
+
+	if global > 0 {
+		ok, err := core.RDB.SetNX(ctx, keyGlobal, nil, global).Result()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if user > 0 {
+		ok, err := core.RDB.SetNX(ctx, cooldownKeyUser(place, person, trigger), nil, user).Result()
+		if err != nil || !ok {
+			// give back the global cooldown, this use didn't go through
+			if global > 0 {
+				core.RDB.Del(ctx, keyGlobal)
+			}
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func plural(n int, s string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, s)
+	}
+	return fmt.Sprintf("%d %ss", n, s)
+}
+
+// The default usage message, used when the trigger doesn't have a custom one.
+func argsUsage(trigger string, min, max int) string {
+	switch {
+	case max < 0:
+		return fmt.Sprintf("%s expects at least %s.", trigger, plural(min, "argument"))
+	case min == max:
+		return fmt.Sprintf("%s expects exactly %s.", trigger, plural(min, "argument"))
+	default:
+		return fmt.Sprintf("%s expects between %d and %d arguments.", trigger, min, max)
+	}
+}
+
+// ArgsShow returns the minimum and maximum number of arguments the trigger
+// accepts along with its usage message. If there is no maximum then -1 is
+// returned. If no custom usage message has been set, the default one is
+// returned.
+func ArgsShow(place int64, trigger string) (int, int, string, core.Urr, error) {
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return 0, 0, "", nil, err
+	}
+	if !exists {
+		return 0, 0, "", UrrTriggerNotFound, nil
+	}
+
+	min, max, usage, err := dbArgsGet(place, trigger)
+	if usage == "" {
+		usage = argsUsage(trigger, min, max)
+	}
+	return min, max, usage, nil, err
+}
+
+// ArgsSet sets the argument limits of the trigger. A negative max means that
+// there is no maximum and an empty usage means that the default usage message
+// will be used.
+func ArgsSet(place int64, trigger string, min, max int, usage string) (core.Urr, error) {
+	if min < 0 || (max >= 0 && max < min) {
+		return UrrArgsRange, nil
+	}
+
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return UrrTriggerNotFound, nil
+	}
+
+	return nil, dbArgsSet(place, trigger, min, max, usage)
+}
+
+// History returns every version of the trigger, from the oldest to the newest.
+// Versions are numbered starting from 1.
 func History(place int64, trigger string) ([]customCommand, error) {
 	// We don't check to see if the trigger exists since this command may be
 	// used to view the history of a deleted trigger
 	return dbHistory(place, trigger)
 }
+
+// Revert sets the trigger's response to the one it had in the given version,
+// see History. This is recorded as a new edit, so the history is preserved.
+func Revert(place, editor int64, trigger string, version int) (string, core.Urr, error) {
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		return "", UrrTriggerNotFound, nil
+	}
+
+	history, err := dbHistory(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if version < 1 || version > len(history) {
+		return "", UrrVersionNotFound, nil
+	}
+	response := history[version-1].response
+
+	// Responses created before variables were introduced may not be valid
+	if urr := templateValidate(response); urr != nil {
+		return "", urr, nil
+	}
+
+	return response, nil, dbEdit(place, editor, trigger, response)
+}
+
+// Undelete restores a deleted trigger to its last version. This is recorded as
+// a new creation, so the history is preserved.
+func Undelete(place, creator int64, trigger string) (string, core.Urr, error) {
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if exists {
+		return "", UrrTriggerExists, nil
+	}
+
+	history, err := dbHistory(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(history) == 0 {
+		return "", UrrNoHistory, nil
+	}
+
+	// The prefixes may have changed since the trigger was deleted
+	builtin, err := isBuiltin(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if builtin {
+		return "", UrrBuiltinCommand, nil
+	}
+
+	// Responses created before variables were introduced may not be valid
+	response := history[len(history)-1].response
+	if urr := templateValidate(response); urr != nil {
+		return "", urr, nil
+	}
+
+	return response, nil, dbUndelete(place, creator, trigger)
+}
+
+func RoleShow(place int64, trigger string) (string, core.Urr, error) {
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		return "", UrrTriggerNotFound, nil
+	}
+	role, err := dbRoleGet(place, trigger)
+	return role, nil, err
+}
+
+// RoleSet sets the minimum role required in order to use the trigger, must be
+// one of Roles.
+func RoleSet(place int64, trigger, role string) (core.Urr, error) {
+	if !slices.Contains(Roles, role) {
+		return UrrInvalidRole, nil
+	}
+
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return UrrTriggerNotFound, nil
+	}
+
+	return nil, dbRoleSet(place, trigger, role)
+}
+
+// CooldownShow returns the global and per-user cooldowns of the trigger.
+func CooldownShow(place int64, trigger string) (time.Duration, time.Duration, core.Urr, error) {
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if !exists {
+		return 0, 0, UrrTriggerNotFound, nil
+	}
+	global, user, err := dbCooldownGet(place, trigger)
+	return global, user, nil, err
+}
+
+// CooldownSet sets the global and per-user cooldowns of the trigger, a
+// cooldown of 0 disables it.
+func CooldownSet(place int64, trigger string, global, user time.Duration) (core.Urr, error) {
+	if global < 0 || user < 0 {
+		return UrrInvalidCooldown, nil
+	}
+
+	exists, err := dbTriggerExists(place, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return UrrTriggerNotFound, nil
+	}
+
+	return nil, dbCooldownSet(place, trigger, global, user)
+}
@@ -64,6 +64,20 @@ func TestAdd(t *testing.T) {
 		t.Fatalf("failed to add command '%s': urr = %v, err = %v", trigger2, urr, err)
 	}
 
+	if urr, err := custom_command.Add(place, person, "!var", "$(nope)"); urr != custom_command.UrrUnknownVariable || err != nil {
+		t.Fatalf("expected UnknownVariable user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	for _, resp := range []string{
+		"$(random 10 1)",
+		"$(random -9223372036854775808 9223372036854775807)",
+		"$(random 0 9223372036854775807)",
+	} {
+		if urr, err := custom_command.Add(place, person, "!var", resp); urr != custom_command.UrrInvalidVariable || err != nil {
+			t.Fatalf("expected InvalidVariable user error for '%s', got: urr = %v, err = %v", resp, urr, err)
+		}
+	}
+
 	if resp, err := showResp(trigger1); resp != response || err != nil {
 		t.Fatalf("expected response '%s' got '%s', err = %v", response, resp, err)
 	}
@@ -1,6 +1,7 @@
 package custom_command
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/kvlach/janitorjeff/core"
@@ -8,10 +9,13 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func _dbAdd(place, creator, timestamp int64, trigger, response string) error {
-	db := core.DB
+// Allows the same queries to be used both inside and outside of transactions.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
 
-	_, err := db.DB.Exec(`
+func _dbAdd(ex execer, place, creator, timestamp int64, trigger, response string) error {
+	_, err := ex.Exec(`
 		INSERT INTO cmd_customcommand_commands(
 			place, trigger, response, active, creator, created
 		)
@@ -36,7 +40,32 @@ func dbAdd(place, creator int64, trigger, response string) error {
 	defer db.Lock.Unlock()
 
 	timestamp := time.Now().UTC().Unix()
-	return _dbAdd(place, creator, timestamp, trigger, response)
+	return _dbAdd(db.DB, place, creator, timestamp, trigger, response)
+}
+
+// Adds all the given commands in a single transaction, either all of them are
+// added or none are.
+func dbAddMany(place, creator int64, cmds []exportedCommand) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	timestamp := time.Now().UTC().Unix()
+	for _, cmd := range cmds {
+		err := _dbAdd(tx, place, creator, timestamp, cmd.Trigger, cmd.Response)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func _dbDel(place, deleter, timestamp int64, trigger string) error {
@@ -68,6 +97,55 @@ func dbDelete(place, deleter int64, trigger string) error {
 	return _dbDel(place, deleter, timestamp, trigger)
 }
 
+// Creates a new active version of the command with the given id, keeping all
+// of its settings but replacing its response.
+func _dbCopy(id, creator, timestamp int64, response string) error {
+	db := core.DB
+
+	_, err := db.DB.Exec(`
+		INSERT INTO cmd_customcommand_commands(
+			place, trigger, response, active, creator, created,
+			args_min, args_max, args_usage, role, cooldown_global, cooldown_user
+		)
+		SELECT place, trigger, $1, $2, $3, $4,
+			args_min, args_max, args_usage, role, cooldown_global, cooldown_user
+		FROM cmd_customcommand_commands
+		WHERE id = $5
+	`, response, true, creator, timestamp, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Str("response", response).
+		Int64("creator", creator).
+		Int64("timestamp", timestamp).
+		Msg("copied command")
+
+	return err
+}
+
+func _dbActiveID(place int64, trigger string) (int64, error) {
+	db := core.DB
+
+	row := db.DB.QueryRow(`
+		SELECT id
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and trigger = $2 and active = $3
+	`, place, trigger, true)
+
+	var id int64
+	err := row.Scan(&id)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int64("id", id).
+		Msg("got active command's id")
+
+	return id, err
+}
+
 func dbEdit(place, editor int64, trigger, response string) error {
 	db := core.DB
 	db.Lock.Lock()
@@ -75,12 +153,17 @@ func dbEdit(place, editor int64, trigger, response string) error {
 
 	timestamp := time.Now().UTC().Unix()
 
-	err := _dbDel(place, editor, timestamp, trigger)
+	id, err := _dbActiveID(place, trigger)
 	if err != nil {
 		return err
 	}
 
-	err = _dbAdd(place, editor, timestamp, trigger, response)
+	err = _dbDel(place, editor, timestamp, trigger)
+	if err != nil {
+		return err
+	}
+
+	err = _dbCopy(id, editor, timestamp, response)
 	if err != nil {
 		return err
 	}
@@ -95,6 +178,40 @@ func dbEdit(place, editor int64, trigger, response string) error {
 	return nil
 }
 
+// Restores the most recently deleted version of the trigger, keeping all of its
+// settings.
+func dbUndelete(place, creator int64, trigger string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	row := db.DB.QueryRow(`
+		SELECT id, response
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and trigger = $2 and active = $3
+		ORDER BY id DESC
+		LIMIT 1
+	`, place, trigger, false)
+
+	var id int64
+	var response string
+	err := row.Scan(&id, &response)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int64("id", id).
+		Msg("got last deleted version")
+
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().UTC().Unix()
+	return _dbCopy(id, creator, timestamp, response)
+}
+
 func dbTriggerExists(place int64, trigger string) (bool, error) {
 	db := core.DB
 	db.Lock.RLock()
@@ -183,6 +300,113 @@ func dbGetResponse(place int64, trigger string) (string, error) {
 	return response, err
 }
 
+// Returns the minimum and maximum number of arguments the trigger accepts
+// along with its usage message. If there is no maximum -1 is returned, if there
+// is no usage message an empty string is returned.
+func dbArgsGet(place int64, trigger string) (int, int, string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	row := db.DB.QueryRow(`
+		SELECT args_min, args_max, args_usage
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and trigger = $2 and active = $3
+	`, place, trigger, true)
+
+	var min int
+	var max sql.NullInt64
+	var usage sql.NullString
+	err := row.Scan(&min, &max, &usage)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int("min", min).
+		Interface("max", max).
+		Interface("usage", usage).
+		Msg("got trigger's arguments")
+
+	if !max.Valid {
+		max.Int64 = -1
+	}
+	return min, int(max.Int64), usage.String, err
+}
+
+// Sets the argument limits of the trigger. A negative max means that there is
+// no maximum and an empty usage means that the default message is used.
+func dbArgsSet(place int64, trigger string, min, max int, usage string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var _max, _usage any
+	if max >= 0 {
+		_max = max
+	}
+	if usage != "" {
+		_usage = usage
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_customcommand_commands
+		SET args_min = $1, args_max = $2, args_usage = $3
+		WHERE place = $4 and trigger = $5 and active = $6
+	`, min, _max, _usage, place, trigger, true)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int("min", min).
+		Int("max", max).
+		Str("usage", usage).
+		Msg("set trigger's arguments")
+
+	return err
+}
+
+// Returns all the active commands of a place, ordered by their trigger.
+func dbExport(place int64) ([]exportedCommand, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT trigger, response, creator, created
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and active = $2
+		ORDER BY trigger
+	`, place, true)
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to make query")
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var cmds []exportedCommand
+	for rows.Next() {
+		var cmd exportedCommand
+		if err := rows.Scan(&cmd.Trigger, &cmd.Response, &cmd.Creator, &cmd.Created); err != nil {
+			log.Debug().Err(err).Msg("failed while scanning rows")
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int("count", len(cmds)).
+		Msg("exported commands")
+
+	return cmds, err
+}
+
 type customCommand struct {
 	response string
 	creator  int64
@@ -198,6 +422,7 @@ func _dbHistory(place int64, trigger string, active bool) ([]customCommand, erro
 		SELECT response, creator, created, deleter, deleted
 		FROM cmd_customcommand_commands
 		WHERE place = $1 and trigger = $2 and active = $3
+		ORDER BY id
 	`, place, trigger, active)
 	if err != nil {
 		log.Debug().Err(err).Msg("failed to make query")
@@ -265,3 +490,124 @@ func dbHistory(place int64, trigger string) ([]customCommand, error) {
 
 	return append(inactive, active...), nil
 }
+
+// Increments the trigger's counter and returns the new value. Counters are
+// kept per trigger, so they survive edits.
+func dbCounterIncrement(place int64, trigger string) (int64, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	row := db.DB.QueryRow(`
+		INSERT INTO cmd_customcommand_counters(place, trigger, count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (place, trigger)
+		DO UPDATE SET count = cmd_customcommand_counters.count + 1
+		RETURNING count
+	`, place, trigger)
+
+	var count int64
+	err := row.Scan(&count)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int64("count", count).
+		Msg("incremented counter")
+
+	return count, err
+}
+
+func dbRoleGet(place int64, trigger string) (string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	row := db.DB.QueryRow(`
+		SELECT role
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and trigger = $2 and active = $3
+	`, place, trigger, true)
+
+	var role string
+	err := row.Scan(&role)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Str("role", role).
+		Msg("got trigger's role")
+
+	return role, err
+}
+
+func dbRoleSet(place int64, trigger, role string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_customcommand_commands
+		SET role = $1
+		WHERE place = $2 and trigger = $3 and active = $4
+	`, role, place, trigger, true)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Str("role", role).
+		Msg("set trigger's role")
+
+	return err
+}
+
+// Returns the global and per-user cooldowns of the trigger.
+func dbCooldownGet(place int64, trigger string) (time.Duration, time.Duration, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	row := db.DB.QueryRow(`
+		SELECT cooldown_global, cooldown_user
+		FROM cmd_customcommand_commands
+		WHERE place = $1 and trigger = $2 and active = $3
+	`, place, trigger, true)
+
+	var global, user int64
+	err := row.Scan(&global, &user)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Int64("global", global).
+		Int64("user", user).
+		Msg("got trigger's cooldowns")
+
+	return time.Duration(global) * time.Second, time.Duration(user) * time.Second, err
+}
+
+func dbCooldownSet(place int64, trigger string, global, user time.Duration) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_customcommand_commands
+		SET cooldown_global = $1, cooldown_user = $2
+		WHERE place = $3 and trigger = $4 and active = $5
+	`, int64(global.Seconds()), int64(user.Seconds()), place, trigger, true)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("trigger", trigger).
+		Dur("global", global).
+		Dur("user", user).
+		Msg("set trigger's cooldowns")
+
+	return err
+}
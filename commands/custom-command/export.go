@@ -0,0 +1,217 @@
+package custom_command
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var (
+	UrrInvalidFormat = core.UrrNew("invalid export format")
+	UrrInvalidData   = core.UrrNew("could not parse the commands to import")
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{"trigger", "response", "creator", "created"}
+
+type exportedCommand struct {
+	Trigger  string `json:"trigger"`
+	Response string `json:"response"`
+	Creator  int64  `json:"creator"`
+	Created  int64  `json:"created"`
+}
+
+// ImportResult holds the triggers that were imported along with the ones that
+// were skipped and why.
+type ImportResult struct {
+	Imported []string
+	// triggers that already exist in the place or appear multiple times
+	Exists []string
+	// triggers that collide with built-in commands
+	Builtin []string
+	// triggers that are malformed or whose responses are invalid
+	Invalid []string
+}
+
+// Export serializes all the active commands of a place in the given format,
+// which must be either FormatJSON or FormatCSV.
+func Export(place int64, format string) (string, core.Urr, error) {
+	if format != FormatJSON && format != FormatCSV {
+		return "", UrrInvalidFormat, nil
+	}
+
+	cmds, err := dbExport(place)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := encode(cmds, format)
+	return data, nil, err
+}
+
+func encode(cmds []exportedCommand, format string) (string, error) {
+	if format == FormatJSON {
+		if cmds == nil {
+			cmds = []exportedCommand{}
+		}
+		b, err := json.Marshal(cmds)
+		return string(b), err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(csvHeader); err != nil {
+		return "", err
+	}
+	for _, cmd := range cmds {
+		err := w.Write([]string{
+			cmd.Trigger,
+			cmd.Response,
+			strconv.FormatInt(cmd.Creator, 10),
+			strconv.FormatInt(cmd.Created, 10),
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// Removes a surrounding markdown code block, if there is one.
+func trimCodeBlock(data string) string {
+	data = strings.TrimSpace(data)
+	if !strings.HasPrefix(data, "```") || !strings.HasSuffix(data, "```") {
+		return data
+	}
+	data = strings.TrimSuffix(strings.TrimPrefix(data, "```"), "```")
+	// remove the language hint, e.g. ```json
+	if i := strings.IndexByte(data, '\n'); i != -1 {
+		if lang := strings.TrimSpace(data[:i]); lang == FormatJSON || lang == FormatCSV {
+			data = data[i+1:]
+		}
+	}
+	return strings.TrimSpace(data)
+}
+
+func parseJSON(data string) ([]exportedCommand, error) {
+	var cmds []exportedCommand
+	err := json.Unmarshal([]byte(data), &cmds)
+	return cmds, err
+}
+
+func parseCSV(data string) ([]exportedCommand, error) {
+	r := csv.NewReader(strings.NewReader(data))
+	// only the trigger and response are required
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var cmds []exportedCommand
+	for i, rec := range records {
+		if i == 0 && len(rec) > 0 && rec[0] == csvHeader[0] {
+			continue
+		}
+		if len(rec) < 2 {
+			return nil, csv.ErrFieldCount
+		}
+		cmds = append(cmds, exportedCommand{
+			Trigger:  rec[0],
+			Response: rec[1],
+		})
+	}
+	return cmds, nil
+}
+
+// Import adds the given commands to the place, the format (JSON or CSV) is
+// detected automatically. The importer is recorded as the creator of every
+// command. Commands that collide with existing triggers or built-in commands,
+// or whose responses are invalid, are skipped. All the remaining commands are
+// added in a single transaction.
+func Import(place, importer int64, data string) (ImportResult, core.Urr, error) {
+	var res ImportResult
+
+	data = trimCodeBlock(data)
+
+	var cmds []exportedCommand
+	var err error
+	if strings.HasPrefix(data, "[") {
+		cmds, err = parseJSON(data)
+	} else {
+		cmds, err = parseCSV(data)
+	}
+	if err != nil || len(cmds) == 0 {
+		return res, UrrInvalidData, nil
+	}
+
+	seen := map[string]struct{}{}
+	var valid []exportedCommand
+
+	for _, cmd := range cmds {
+		if cmd.Trigger == "" || cmd.Response == "" {
+			return res, UrrInvalidData, nil
+		}
+
+		if _, ok := seen[cmd.Trigger]; ok {
+			res.Exists = append(res.Exists, cmd.Trigger)
+			continue
+		}
+		seen[cmd.Trigger] = struct{}{}
+
+		exists, err := dbTriggerExists(place, cmd.Trigger)
+		if err != nil {
+			return res, nil, err
+		}
+		if exists {
+			res.Exists = append(res.Exists, cmd.Trigger)
+			continue
+		}
+
+		builtin, err := isBuiltin(place, cmd.Trigger)
+		if err != nil {
+			return res, nil, err
+		}
+		if builtin {
+			res.Builtin = append(res.Builtin, cmd.Trigger)
+			continue
+		}
+
+		// triggers are matched against the first field of a message, so they
+		// can't contain whitespace, and responses must fit in the database
+		if len(strings.Fields(cmd.Trigger)) != 1 || utf8.RuneCountInString(cmd.Response) > 255 {
+			res.Invalid = append(res.Invalid, cmd.Trigger)
+			continue
+		}
+
+		if urr := templateValidate(cmd.Response); urr != nil {
+			res.Invalid = append(res.Invalid, cmd.Trigger)
+			continue
+		}
+
+		valid = append(valid, cmd)
+	}
+
+	if len(valid) == 0 {
+		return res, nil, nil
+	}
+
+	if err := dbAddMany(place, importer, valid); err != nil {
+		return res, nil, err
+	}
+	for _, cmd := range valid {
+		res.Imported = append(res.Imported, cmd.Trigger)
+	}
+	return res, nil, nil
+}
@@ -0,0 +1,138 @@
+package custom_command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrimCodeBlock(t *testing.T) {
+	tests := []struct {
+		data string
+		exp  string
+	}{
+		{"[]", "[]"},
+		{"  trigger,response\n", "trigger,response"},
+		{"```[]```", "[]"},
+		{"```\n[]\n```", "[]"},
+		{"```json\n[]\n```", "[]"},
+		{"```csv\n!a,b\n```", "!a,b"},
+		// not a known language hint, so it's kept as part of the data
+		{"```yaml\n[]\n```", "yaml\n[]"},
+		// not closed
+		{"```json\n[]", "```json\n[]"},
+	}
+
+	for _, test := range tests {
+		if got := trimCodeBlock(test.data); got != test.exp {
+			t.Errorf("%q: expected %q, got %q", test.data, test.exp, got)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		data string
+		cmds []exportedCommand
+		ok   bool
+	}{
+		{"[]", []exportedCommand{}, true},
+		{
+			`[{"trigger":"!a","response":"b","creator":1,"created":2}]`,
+			[]exportedCommand{{Trigger: "!a", Response: "b", Creator: 1, Created: 2}},
+			true,
+		},
+		{
+			`[{"trigger":"!a","response":"b"},{"trigger":"!c","response":"d"}]`,
+			[]exportedCommand{{Trigger: "!a", Response: "b"}, {Trigger: "!c", Response: "d"}},
+			true,
+		},
+		{`[{"trigger":"!a"`, nil, false},
+		{`{"trigger":"!a","response":"b"}`, nil, false},
+		{`[{"trigger":1,"response":"b"}]`, nil, false},
+	}
+
+	for _, test := range tests {
+		cmds, err := parseJSON(test.data)
+		if (err == nil) != test.ok {
+			t.Errorf("%q: expected ok = %v, got err = %v", test.data, test.ok, err)
+			continue
+		}
+		if test.ok && !reflect.DeepEqual(cmds, test.cmds) {
+			t.Errorf("%q: expected %+v, got %+v", test.data, test.cmds, cmds)
+		}
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		data string
+		cmds []exportedCommand
+		ok   bool
+	}{
+		{"trigger,response,creator,created\n", nil, true},
+		{
+			"trigger,response,creator,created\n!a,b,1,2\n",
+			[]exportedCommand{{Trigger: "!a", Response: "b"}},
+			true,
+		},
+		// the header is optional and so are the creator and created columns
+		{
+			"!a,b\n!c,\"d, e\"\n",
+			[]exportedCommand{{Trigger: "!a", Response: "b"}, {Trigger: "!c", Response: "d, e"}},
+			true,
+		},
+		{"!a,\"multi\nline\"\n", []exportedCommand{{Trigger: "!a", Response: "multi\nline"}}, true},
+		{"!a\n", nil, false},
+		{"!a,b\n!c\n", nil, false},
+		{"!a,\"unterminated\n", nil, false},
+		{"!a,b\"c\n", nil, false},
+	}
+
+	for _, test := range tests {
+		cmds, err := parseCSV(test.data)
+		if (err == nil) != test.ok {
+			t.Errorf("%q: expected ok = %v, got err = %v", test.data, test.ok, err)
+			continue
+		}
+		if test.ok && !reflect.DeepEqual(cmds, test.cmds) {
+			t.Errorf("%q: expected %+v, got %+v", test.data, test.cmds, cmds)
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	cmds := []exportedCommand{
+		{Trigger: "!a", Response: "b", Creator: 1, Created: 2},
+		{Trigger: "!hug", Response: `$(user) hugs "$(args 1)", twice`, Creator: 3, Created: 4},
+		{Trigger: "!multi", Response: "first\nsecond", Creator: 5, Created: 6},
+	}
+
+	tests := []struct {
+		format string
+		parse  func(string) ([]exportedCommand, error)
+		// csv only keeps the trigger and the response
+		exp []exportedCommand
+	}{
+		{FormatJSON, parseJSON, cmds},
+		{FormatCSV, parseCSV, []exportedCommand{
+			{Trigger: "!a", Response: "b"},
+			{Trigger: "!hug", Response: `$(user) hugs "$(args 1)", twice`},
+			{Trigger: "!multi", Response: "first\nsecond"},
+		}},
+	}
+
+	for _, test := range tests {
+		data, err := encode(cmds, test.format)
+		if err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+		// exports are usually pasted back wrapped in a code block
+		got, err := test.parse(trimCodeBlock("```" + test.format + "\n" + data + "```"))
+		if err != nil {
+			t.Fatalf("%s: %v", test.format, err)
+		}
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%s: expected %+v, got %+v", test.format, test.exp, got)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package custom_command
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/commands/streak"
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var (
+	UrrUnknownVariable = core.UrrNew("response contains an unknown variable")
+	UrrInvalidVariable = core.UrrNew("response contains a variable with invalid arguments")
+)
+
+// A segment is either a piece of literal text or a variable.
+type segment struct {
+	text  string
+	isVar bool
+	name  string
+	args  []string
+}
+
+// Splits a response into its segments. Variables have the form
+// $(name arg1 arg2 ...), anything that isn't properly closed is kept as
+// literal text.
+func parseTemplate(response string) []segment {
+	var segments []segment
+
+	for {
+		start := strings.Index(response, "$(")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(response[start:], ")")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		if start > 0 {
+			segments = append(segments, segment{text: response[:start]})
+		}
+
+		fields := strings.Fields(response[start+2 : end])
+		if len(fields) == 0 {
+			// empty name, will be reported as an unknown variable
+			fields = []string{""}
+		}
+		segments = append(segments, segment{
+			text:  response[start : end+1],
+			isVar: true,
+			name:  fields[0],
+			args:  fields[1:],
+		})
+
+		response = response[end+1:]
+	}
+
+	if response != "" {
+		segments = append(segments, segment{text: response})
+	}
+
+	return segments
+}
+
+// Holds everything needed to evaluate the variables of a single invocation.
+type templateData struct {
+	place   int64
+	trigger string
+	author  core.Personifier
+	args    []string
+
+	// the counter is only incremented once per invocation, even if $(count)
+	// appears multiple times
+	count   int64
+	counted bool
+}
+
+var errInvalidRange = errors.New("invalid range")
+
+// Parses the bounds of $(random low high). The range must not be empty and
+// high-low+1 must fit in an int64, otherwise rand.Int63n can't be used.
+func randomRange(args []string) (int64, int64, bool) {
+	low, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	high, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if high < low {
+		return 0, 0, false
+	}
+	// the subtraction wraps around to a negative number when it overflows
+	span := high - low
+	if span < 0 || span == math.MaxInt64 {
+		return 0, 0, false
+	}
+	return low, high, true
+}
+
+type variable struct {
+	// Returns false if the arguments are not valid.
+	check func(args []string) bool
+	eval  func(d *templateData, args []string) (string, error)
+}
+
+var variables = map[string]variable{
+	"user": {
+		check: func(args []string) bool { return len(args) == 0 },
+		eval: func(d *templateData, _ []string) (string, error) {
+			return d.author.DisplayName()
+		},
+	},
+	"args": {
+		check: func(args []string) bool {
+			if len(args) == 0 {
+				return true
+			}
+			if len(args) > 1 {
+				return false
+			}
+			n, err := strconv.Atoi(args[0])
+			return err == nil && n > 0
+		},
+		eval: func(d *templateData, args []string) (string, error) {
+			if len(args) == 0 {
+				return strings.Join(d.args, " "), nil
+			}
+			n, _ := strconv.Atoi(args[0])
+			if n > len(d.args) {
+				return "", nil
+			}
+			return d.args[n-1], nil
+		},
+	},
+	"count": {
+		check: func(args []string) bool { return len(args) == 0 },
+		eval: func(d *templateData, _ []string) (string, error) {
+			if !d.counted {
+				count, err := dbCounterIncrement(d.place, d.trigger)
+				if err != nil {
+					return "", err
+				}
+				d.count = count
+				d.counted = true
+			}
+			return strconv.FormatInt(d.count, 10), nil
+		},
+	},
+	"random": {
+		check: func(args []string) bool {
+			if len(args) != 2 {
+				return false
+			}
+			_, _, ok := randomRange(args)
+			return ok
+		},
+		eval: func(_ *templateData, args []string) (string, error) {
+			low, high, ok := randomRange(args)
+			if !ok {
+				return "", errInvalidRange
+			}
+			n := low + rand.Int63n(high-low+1)
+			return strconv.FormatInt(n, 10), nil
+		},
+	},
+	"time": {
+		check: func(args []string) bool {
+			if len(args) == 0 {
+				return true
+			}
+			if len(args) > 1 {
+				return false
+			}
+			_, err := time.LoadLocation(args[0])
+			return err == nil
+		},
+		eval: func(d *templateData, args []string) (string, error) {
+			var tz string
+			if len(args) == 0 {
+				// default to the author's timezone
+				person, err := d.author.Scope()
+				if err != nil {
+					return "", err
+				}
+				tz, err = core.DB.PersonGlobalGet("cmd_time_tz", person).Str()
+				if err != nil {
+					return "", err
+				}
+			} else {
+				tz = args[0]
+			}
+			loc, err := time.LoadLocation(tz)
+			if err != nil {
+				return "", err
+			}
+			return time.Now().In(loc).Format("15:04 MST"), nil
+		},
+	},
+	"streak": {
+		check: func(args []string) bool { return len(args) == 0 },
+		eval: func(d *templateData, _ []string) (string, error) {
+			person, err := d.author.Scope()
+			if err != nil {
+				return "", err
+			}
+			n, err := streak.Get(person, d.place)
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(n, 10), nil
+		},
+	},
+}
+
+// Makes sure that every variable in the response exists and is given valid
+// arguments.
+func templateValidate(response string) core.Urr {
+	for _, s := range parseTemplate(response) {
+		if !s.isVar {
+			continue
+		}
+		v, ok := variables[s.name]
+		if !ok {
+			return UrrUnknownVariable
+		}
+		if !v.check(s.args) {
+			return UrrInvalidVariable
+		}
+	}
+	return nil
+}
+
+// Evaluates all the variables in the response.
+func templateRender(response string, d *templateData) (string, error) {
+	var b strings.Builder
+
+	for _, s := range parseTemplate(response) {
+		if !s.isVar {
+			b.WriteString(s.text)
+			continue
+		}
+
+		v, ok := variables[s.name]
+		if !ok || !v.check(s.args) {
+			// responses created before variables were introduced are kept
+			// as is
+			b.WriteString(s.text)
+			continue
+		}
+
+		val, err := v.eval(d, s.args)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(val)
+	}
+
+	return b.String(), nil
+}
@@ -3,6 +3,7 @@ package god
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/kvlach/janitorjeff/core"
 	"github.com/kvlach/janitorjeff/frontends/discord"
@@ -49,6 +50,7 @@ func (admin) Parent() core.CommandStatic {
 func (admin) Children() core.CommandsStatic {
 	return core.CommandsStatic{
 		AdminMax,
+		AdminBackend,
 	}
 }
 
@@ -317,3 +319,295 @@ func (adminMaxSet) core(m *core.EventMessage) (int, core.Urr, error) {
 	}
 	return max, nil, MaxSet(here, max)
 }
+
+/////////////
+//         //
+// backend //
+//         //
+/////////////
+
+var AdminBackend = adminBackend{}
+
+type adminBackend struct{}
+
+func (c adminBackend) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c adminBackend) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (adminBackend) Names() []string {
+	return []string{
+		"backend",
+	}
+}
+
+func (adminBackend) Description() string {
+	return "Control which model and server are used to generate responses."
+}
+
+func (c adminBackend) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c adminBackend) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (adminBackend) Examples() []string {
+	return nil
+}
+
+func (adminBackend) Parent() core.CommandStatic {
+	return Admin
+}
+
+func (adminBackend) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdminBackendShow,
+		AdminBackendSet,
+		AdminBackendReset,
+	}
+}
+
+func (adminBackend) Init() error {
+	return nil
+}
+
+func (adminBackend) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func (adminBackend) fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrUnknownSetting:
+		return "Unknown setting, expected one of: " + strings.Join(Settings, ", ")
+	case UrrInvalidURL:
+		return "Invalid URL, expected something like http://localhost:8080/v1"
+	case UrrInvalidTimeout:
+		return "Invalid timeout, expected something like 30s, must be at least 1s."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+//////////////////
+//              //
+// backend show //
+//              //
+//////////////////
+
+var AdminBackendShow = adminBackendShow{}
+
+type adminBackendShow struct{}
+
+func (c adminBackendShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c adminBackendShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (adminBackendShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (adminBackendShow) Description() string {
+	return "Show the backend settings."
+}
+
+func (adminBackendShow) UsageArgs() string {
+	return ""
+}
+
+func (c adminBackendShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (adminBackendShow) Examples() []string {
+	return nil
+}
+
+func (adminBackendShow) Parent() core.CommandStatic {
+	return AdminBackend
+}
+
+func (adminBackendShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (adminBackendShow) Init() error {
+	return nil
+}
+
+func (c adminBackendShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	cfg, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fmt.Sprintf("model: %s | url: %s | timeout: %s", cfg.Model, cfg.BaseURL, cfg.Timeout), nil, nil
+}
+
+func (adminBackendShow) core(m *core.EventMessage) (Config, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return Config{}, err
+	}
+	return ConfigGet(here)
+}
+
+/////////////////
+//             //
+// backend set //
+//             //
+/////////////////
+
+var AdminBackendSet = adminBackendSet{}
+
+type adminBackendSet struct{}
+
+func (c adminBackendSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c adminBackendSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (adminBackendSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (adminBackendSet) Description() string {
+	return "Change one of the backend settings."
+}
+
+func (adminBackendSet) UsageArgs() string {
+	return "(model | url | timeout) <value>"
+}
+
+func (c adminBackendSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (adminBackendSet) Examples() []string {
+	return []string{
+		"model gpt-4o-mini",
+		"url http://localhost:11434/v1",
+		"timeout 1m",
+	}
+}
+
+func (adminBackendSet) Parent() core.CommandStatic {
+	return AdminBackend
+}
+
+func (adminBackendSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (adminBackendSet) Init() error {
+	return nil
+}
+
+func (c adminBackendSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return AdminBackend.fmtUrr(urr), urr, nil
+	}
+	return "Updated the " + strings.ToLower(m.Command.Args[0]) + ".", nil, nil
+}
+
+func (adminBackendSet) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	setting := strings.ToLower(m.Command.Args[0])
+	return ConfigSet(here, setting, m.Command.Args[1])
+}
+
+///////////////////
+//               //
+// backend reset //
+//               //
+///////////////////
+
+var AdminBackendReset = adminBackendReset{}
+
+type adminBackendReset struct{}
+
+func (c adminBackendReset) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c adminBackendReset) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (adminBackendReset) Names() []string {
+	return []string{
+		"reset",
+	}
+}
+
+func (adminBackendReset) Description() string {
+	return "Go back to using the default for one of the backend settings."
+}
+
+func (adminBackendReset) UsageArgs() string {
+	return "(model | url | timeout)"
+}
+
+func (c adminBackendReset) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (adminBackendReset) Examples() []string {
+	return []string{
+		"url",
+	}
+}
+
+func (adminBackendReset) Parent() core.CommandStatic {
+	return AdminBackend
+}
+
+func (adminBackendReset) Children() core.CommandsStatic {
+	return nil
+}
+
+func (adminBackendReset) Init() error {
+	return nil
+}
+
+func (c adminBackendReset) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return AdminBackend.fmtUrr(urr), urr, nil
+	}
+	return "Reset the " + strings.ToLower(m.Command.Args[0]) + " to the default.", nil, nil
+}
+
+func (adminBackendReset) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return ConfigReset(here, strings.ToLower(m.Command.Args[0]))
+}
@@ -1,11 +1,15 @@
 package god
 
 import (
+	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/kvlach/janitorjeff/commands/nick"
+	ctwitch "github.com/kvlach/janitorjeff/commands/twitch"
 	"github.com/kvlach/janitorjeff/core"
 	"github.com/kvlach/janitorjeff/frontends/discord"
 
@@ -63,6 +67,8 @@ func (advanced) Children() core.CommandsStatic {
 		AdvancedAuto,
 		AdvancedRedeem,
 		AdvancedPersonality,
+		AdvancedMemory,
+		AdvancedFilter,
 	}
 }
 
@@ -70,6 +76,17 @@ func (advanced) Init() error {
 	var mu sync.Mutex
 
 	core.EventMessageHooks.Register(func(m *core.EventMessage) {
+		here, err := m.Here.ScopeLogical()
+		if err != nil {
+			return
+		}
+
+		// Done before locking, so that messages sent while waiting for a
+		// reply are still kept
+		if err := contextRecord(m, here); err != nil {
+			log.Error().Err(err).Msg("failed to record chat context")
+		}
+
 		// Due to the fact that Talk can take a couple of seconds to return,
 		// multiple auto-replies can be queued during that period, since
 		// cmd_god_auto_last gets updated after the call.
@@ -77,11 +94,6 @@ func (advanced) Init() error {
 		mu.Lock()
 		defer mu.Unlock()
 
-		here, err := m.Here.ScopeLogical()
-		if err != nil {
-			return
-		}
-
 		tx, err := core.DB.Begin()
 		if err != nil {
 			log.Error().Err(err).Msg("failed to begin transaction")
@@ -144,16 +156,23 @@ func (advanced) Init() error {
 			return
 		}
 
-		// Don't remember conversation as it is meant to be a random response,
-		// not a discussion
-		resp, err := Talk(-1, here, m.Raw)
+		f, err := FilterGet(here)
 		if err != nil {
-			log.Debug().Err(err).Msg("failed to communicate with god")
+			log.Error().Err(err).Msg("failed to get filter")
+			tx.Commit()
 			return
 		}
+		prompt, err := contextPrompt(here, m.Raw, f)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get chat context")
+			prompt = m.Raw
+		}
 
-		if _, err := m.Client.Natural(resp, nil); err != nil {
-			log.Error().Err(err).Msg("failed to send message")
+		// Don't remember conversation as it is meant to be a random response,
+		// not a discussion. Only the message being replied to is filtered and
+		// nobody asked for a reply, so nothing is sent if it gets blocked.
+		if _, err := speak(m.Client, m.Client.Natural, -1, here, prompt, m.Raw, true); err != nil && err != UrrBlocked {
+			log.Debug().Err(err).Msg("failed to communicate with god")
 			return
 		}
 
@@ -202,24 +221,32 @@ func (advanced) Init() error {
 			return
 		}
 
-		resp, err := Talk(author, here, rc.Input)
+		m, err := core.Frontends.CreateMessage(author, here, "")
 		if err != nil {
-			slog.Error().Err(err).Msg("failed to get gpt response")
+			slog.Error().Err(err).Msg("failed to create message")
+			rc.Refund()
 			return
 		}
 
-		slog = slog.With().Int64("person", author).Logger()
-
-		m, err := core.Frontends.CreateMessage(author, here, "")
+		// the viewer only spent their points if they got an actual answer,
+		// so nothing is sent if it gets blocked and the points are refunded
+		resp, err := speak(m.Client, m.Client.Natural, author, here, rc.Input, rc.Input, true)
+		if err == UrrBlocked {
+			slog.Debug().Msg("filter blocked the redemption")
+			rc.Refund()
+			return
+		}
 		if err != nil {
-			slog.Error().Err(err).Msg("failed to create message")
+			slog.Error().Err(err).Msg("failed to get gpt response")
+			rc.Refund()
 			return
 		}
-
-		if _, err = m.Client.Natural(resp, nil); err != nil {
-			slog.Error().Err(err).Msg("failed to write message")
+		if strings.TrimSpace(resp) == "" {
+			slog.Debug().Msg("got empty gpt response")
+			rc.Refund()
 			return
 		}
+		rc.Fulfil()
 	})
 
 	return nil
@@ -229,6 +256,18 @@ func (c advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
 	return m.Usage(), core.UrrMissingArgs, nil
 }
 
+// Shows that a response is being generated, on frontends that support it.
+func typing(m *core.EventMessage) error {
+	if m.Frontend.Type() != discord.Frontend.Type() {
+		return nil
+	}
+	hix, err := m.Here.IDExact()
+	if err != nil {
+		return err
+	}
+	return discord.Client.Session.ChannelTyping(hix)
+}
+
 //////////
 //      //
 // talk //
@@ -352,71 +391,48 @@ func (c advancedTalkDialogue) Run(m *core.EventMessage) (any, core.Urr, error) {
 		return m.Usage(), core.UrrMissingArgs, nil
 	}
 
-	switch m.Frontend.Type() {
-	case discord.Frontend.Type():
-		return c.discord(m)
-	default:
-		return c.text(m)
-	}
-}
-
-func (c advancedTalkDialogue) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	hix, err := m.Here.IDExact()
-	if err != nil {
-		return nil, nil, err
-	}
-	if err := discord.Client.Session.ChannelTyping(hix); err != nil {
-		return nil, nil, err
-	}
-	resp, urr, err := c.core(m)
+	urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	embed := &dg.MessageEmbed{
-		Description: c.fmt(resp, urr),
-	}
-	return embed, urr, nil
-}
-
-func (c advancedTalkDialogue) text(m *core.EventMessage) (string, core.Urr, error) {
-	resp, urr, err := c.core(m)
-	if err != nil {
-		return "", nil, err
+	if urr == nil {
+		// The response has already been sent while it was being generated
+		return nil, nil, core.UrrSilence
 	}
-	return c.fmt(resp, urr), urr, nil
-}
 
-func (advancedTalkDialogue) fmt(resp string, urr core.Urr) string {
-	switch urr {
-	case nil:
-		return resp
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return &dg.MessageEmbed{Description: urr.Error()}, urr, nil
 	default:
-		return urr.Error()
+		return urr.Error(), urr, nil
 	}
 }
 
-func (advancedTalkDialogue) core(m *core.EventMessage) (string, core.Urr, error) {
+func (advancedTalkDialogue) core(m *core.EventMessage) (core.Urr, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	everyone, err := EveryoneGet(here)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	mod, err := m.Author.Moderator()
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	if !everyone && !mod {
-		return "", UrrModOnly, nil
+		return UrrModOnly, nil
 	}
 	author, err := m.Author.Scope()
 	if err != nil {
-		return "", nil, err
+		return nil, err
+	}
+	if err := typing(m); err != nil {
+		return nil, err
 	}
-	resp, err := Talk(author, here, m.RawArgs(0))
-	return resp, nil, err
+	_, err = Speak(m.Client, m.Client.Write, author, here, m.RawArgs(0))
+	return nil, err
 }
 
 ///////////////
@@ -476,67 +492,44 @@ func (c advancedTalkOnce) Run(m *core.EventMessage) (any, core.Urr, error) {
 		return m.Usage(), core.UrrMissingArgs, nil
 	}
 
-	switch m.Frontend.Type() {
-	case discord.Frontend.Type():
-		return c.discord(m)
-	default:
-		return c.text(m)
-	}
-}
-
-func (c advancedTalkOnce) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	hix, err := m.Here.IDExact()
-	if err != nil {
-		return nil, nil, err
-	}
-	if err := discord.Client.Session.ChannelTyping(hix); err != nil {
-		return nil, nil, err
-	}
-	resp, urr, err := c.core(m)
+	urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	embed := &dg.MessageEmbed{
-		Description: c.fmt(resp, urr),
-	}
-	return embed, urr, nil
-}
-
-func (c advancedTalkOnce) text(m *core.EventMessage) (string, core.Urr, error) {
-	resp, urr, err := c.core(m)
-	if err != nil {
-		return "", nil, err
+	if urr == nil {
+		// The response has already been sent while it was being generated
+		return nil, nil, core.UrrSilence
 	}
-	return c.fmt(resp, urr), urr, nil
-}
 
-func (advancedTalkOnce) fmt(resp string, urr core.Urr) string {
-	switch urr {
-	case nil:
-		return resp
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return &dg.MessageEmbed{Description: urr.Error()}, urr, nil
 	default:
-		return urr.Error()
+		return urr.Error(), urr, nil
 	}
 }
 
-func (advancedTalkOnce) core(m *core.EventMessage) (string, core.Urr, error) {
+func (advancedTalkOnce) core(m *core.EventMessage) (core.Urr, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	everyone, err := EveryoneGet(here)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	mod, err := m.Author.Moderator()
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 	if !everyone && !mod {
-		return "", UrrModOnly, nil
+		return UrrModOnly, nil
+	}
+	if err := typing(m); err != nil {
+		return nil, err
 	}
-	resp, err := Talk(-1, here, m.RawArgs(0))
-	return resp, nil, err
+	_, err = Speak(m.Client, m.Client.Write, -1, here, m.RawArgs(0))
+	return nil, err
 }
 
 ///////////////////
@@ -918,6 +911,7 @@ func (advancedAuto) Children() core.CommandsStatic {
 		AdvancedAutoOn,
 		AdvancedAutoOff,
 		AdvancedAutoInterval,
+		AdvancedAutoContext,
 	}
 }
 
@@ -1457,329 +1451,454 @@ func (advancedAutoIntervalSet) core(m *core.EventMessage) (time.Duration, core.U
 	return interval, urr, err
 }
 
-////////////
-//        //
-// redeem //
-//        //
-////////////
+//////////////////
+//              //
+// auto context //
+//              //
+//////////////////
 
-var AdvancedRedeem = advancedRedeem{}
+var AdvancedAutoContext = advancedAutoContext{}
 
-type advancedRedeem struct{}
+type advancedAutoContext struct{}
 
-func (c advancedRedeem) Type() core.CommandType {
+func (c advancedAutoContext) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedRedeem) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContext) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedRedeem) Names() []string {
+func (advancedAutoContext) Names() []string {
 	return []string{
-		"redeem",
+		"context",
+		"ctx",
 	}
 }
 
-func (advancedRedeem) Description() string {
-	return "Control which redeem triggers God."
+func (advancedAutoContext) Description() string {
+	return "Control which recent chat messages God sees when auto-replying."
 }
 
-func (c advancedRedeem) UsageArgs() string {
+func (c advancedAutoContext) UsageArgs() string {
 	return c.Children().Usage()
 }
 
-func (c advancedRedeem) Category() core.CommandCategory {
+func (c advancedAutoContext) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedRedeem) Examples() []string {
+func (advancedAutoContext) Examples() []string {
 	return nil
 }
 
-func (advancedRedeem) Parent() core.CommandStatic {
-	return Advanced
+func (advancedAutoContext) Parent() core.CommandStatic {
+	return AdvancedAuto
 }
 
-func (advancedRedeem) Children() core.CommandsStatic {
+func (advancedAutoContext) Children() core.CommandsStatic {
 	return core.CommandsStatic{
-		AdvancedRedeemShow,
-		AdvancedRedeemSet,
+		AdvancedAutoContextShow,
+		AdvancedAutoContextOn,
+		AdvancedAutoContextOff,
+		AdvancedAutoContextSize,
+		AdvancedAutoContextPeople,
+		AdvancedAutoContextKeywords,
 	}
 }
 
-func (advancedRedeem) Init() error {
+func (advancedAutoContext) Init() error {
 	return nil
 }
 
-func (advancedRedeem) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (advancedAutoContext) Run(m *core.EventMessage) (any, core.Urr, error) {
 	return m.Usage(), core.UrrMissingArgs, nil
 }
 
-/////////////////
-//             //
-// redeem show //
-//             //
-/////////////////
+///////////////////////
+//                   //
+// auto context show //
+//                   //
+///////////////////////
 
-var AdvancedRedeemShow = advancedRedeemShow{}
+var AdvancedAutoContextShow = advancedAutoContextShow{}
 
-type advancedRedeemShow struct{}
+type advancedAutoContextShow struct{}
 
-func (c advancedRedeemShow) Type() core.CommandType {
+func (c advancedAutoContextShow) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedRedeemShow) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextShow) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedRedeemShow) Names() []string {
+func (advancedAutoContextShow) Names() []string {
 	return core.AliasesShow
 }
 
-func (advancedRedeemShow) Description() string {
-	return "Show what the current redeem is set to."
+func (advancedAutoContextShow) Description() string {
+	return "Show if chat context is on or off."
 }
 
-func (advancedRedeemShow) UsageArgs() string {
+func (advancedAutoContextShow) UsageArgs() string {
 	return ""
 }
 
-func (c advancedRedeemShow) Category() core.CommandCategory {
+func (c advancedAutoContextShow) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedRedeemShow) Examples() []string {
+func (advancedAutoContextShow) Examples() []string {
 	return nil
 }
 
-func (advancedRedeemShow) Parent() core.CommandStatic {
-	return AdvancedRedeem
+func (advancedAutoContextShow) Parent() core.CommandStatic {
+	return AdvancedAutoContext
 }
 
-func (advancedRedeemShow) Children() core.CommandsStatic {
+func (advancedAutoContextShow) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedRedeemShow) Init() error {
+func (advancedAutoContextShow) Init() error {
 	return nil
 }
 
-func (c advancedRedeemShow) Run(m *core.EventMessage) (any, core.Urr, error) {
-	u, urr, err := c.core(m)
+func (c advancedAutoContextShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	on, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	return c.fmt(u, urr), urr, nil
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(on),
+	}
+	return embed, nil, nil
 }
 
-func (advancedRedeemShow) fmt(u uuid.UUID, urr core.Urr) string {
-	switch urr {
-	case nil:
-		return "The god redeem is set to: " + u.String()
-	case core.UrrValNil:
-		return "The god redeem has not been set."
-	default:
-		return fmt.Sprint(urr)
+func (c advancedAutoContextShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	on, err := c.core(m)
+	if err != nil {
+		return "", nil, err
 	}
+	return c.fmt(on), nil, nil
 }
 
-func (advancedRedeemShow) core(m *core.EventMessage) (uuid.UUID, core.Urr, error) {
+func (advancedAutoContextShow) fmt(on bool) string {
+	if on {
+		return "Chat context is on, recent messages are used when auto-replying."
+	}
+	return "Chat context is off, only the latest message is used when auto-replying."
+}
+
+func (advancedAutoContextShow) core(m *core.EventMessage) (bool, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return uuid.UUID{}, nil, err
+		return false, err
 	}
-	return RedeemGet(here)
+	return ContextOnGet(here)
 }
 
-////////////////
-//            //
-// redeem set //
-//            //
-////////////////
+/////////////////////
+//                 //
+// auto context on //
+//                 //
+/////////////////////
 
-var AdvancedRedeemSet = advancedRedeemSet{}
+var AdvancedAutoContextOn = advancedAutoContextOn{}
 
-type advancedRedeemSet struct{}
+type advancedAutoContextOn struct{}
 
-func (c advancedRedeemSet) Type() core.CommandType {
+func (c advancedAutoContextOn) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedRedeemSet) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextOn) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedRedeemSet) Names() []string {
-	return core.AliasesSet
+func (advancedAutoContextOn) Names() []string {
+	return core.AliasesOn
 }
 
-func (advancedRedeemSet) Description() string {
-	return "Set the ID of the god redeem."
+func (advancedAutoContextOn) Description() string {
+	return "Start keeping recent chat messages as context."
 }
 
-func (advancedRedeemSet) UsageArgs() string {
-	return "<id>"
+func (advancedAutoContextOn) UsageArgs() string {
+	return ""
 }
 
-func (c advancedRedeemSet) Category() core.CommandCategory {
+func (c advancedAutoContextOn) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedRedeemSet) Examples() []string {
+func (advancedAutoContextOn) Examples() []string {
 	return nil
 }
 
-func (advancedRedeemSet) Parent() core.CommandStatic {
-	return AdvancedRedeem
+func (advancedAutoContextOn) Parent() core.CommandStatic {
+	return AdvancedAutoContext
 }
 
-func (advancedRedeemSet) Children() core.CommandsStatic {
+func (advancedAutoContextOn) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedRedeemSet) Init() error {
+func (advancedAutoContextOn) Init() error {
 	return nil
 }
 
-func (c advancedRedeemSet) Run(m *core.EventMessage) (any, core.Urr, error) {
-	if len(m.Command.Args) < 1 {
-		return m.Usage(), core.UrrMissingArgs, nil
+func (c advancedAutoContextOn) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
 	}
+}
 
+func (c advancedAutoContextOn) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
 	err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	return "Set the god redeem.", nil, nil
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(),
+	}
+	return embed, nil, nil
 }
 
-func (advancedRedeemSet) core(m *core.EventMessage) error {
+func (c advancedAutoContextOn) text(m *core.EventMessage) (string, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(), nil, nil
+}
+
+func (advancedAutoContextOn) fmt() string {
+	return "Chat context has been turned on."
+}
+
+func (advancedAutoContextOn) core(m *core.EventMessage) error {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
 		return err
 	}
-	return RedeemSet(here, m.Command.Args[0])
+	return ContextOnSet(here, true)
 }
 
-/////////////////
-//             //
-// personality //
-//             //
-/////////////////
+//////////////////////
+//                  //
+// auto context off //
+//                  //
+//////////////////////
 
-var AdvancedPersonality = advancedPersonality{}
+var AdvancedAutoContextOff = advancedAutoContextOff{}
 
-type advancedPersonality struct{}
+type advancedAutoContextOff struct{}
 
-func (c advancedPersonality) Type() core.CommandType {
+func (c advancedAutoContextOff) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonality) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextOff) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonality) Names() []string {
+func (advancedAutoContextOff) Names() []string {
+	return core.AliasesOff
+}
+
+func (advancedAutoContextOff) Description() string {
+	return "Stop keeping chat messages and forget the ones kept so far."
+}
+
+func (advancedAutoContextOff) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoContextOff) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextOff) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextOff) Parent() core.CommandStatic {
+	return AdvancedAutoContext
+}
+
+func (advancedAutoContextOff) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextOff) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextOff) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextOff) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedAutoContextOff) text(m *core.EventMessage) (string, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(), nil, nil
+}
+
+func (advancedAutoContextOff) fmt() string {
+	return "Chat context has been turned off."
+}
+
+func (advancedAutoContextOff) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return ContextOnSet(here, false)
+}
+
+///////////////////////
+//                   //
+// auto context size //
+//                   //
+///////////////////////
+
+var AdvancedAutoContextSize = advancedAutoContextSize{}
+
+type advancedAutoContextSize struct{}
+
+func (c advancedAutoContextSize) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextSize) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextSize) Names() []string {
 	return []string{
-		"personality",
-		"mood",
-		"cosplay",
+		"size",
+		"limit",
 	}
 }
 
-func (advancedPersonality) Description() string {
-	return "Control God's personality."
+func (advancedAutoContextSize) Description() string {
+	return "Control how many recent messages are kept."
 }
 
-func (c advancedPersonality) UsageArgs() string {
+func (c advancedAutoContextSize) UsageArgs() string {
 	return c.Children().Usage()
 }
 
-func (c advancedPersonality) Category() core.CommandCategory {
+func (c advancedAutoContextSize) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonality) Examples() []string {
+func (advancedAutoContextSize) Examples() []string {
 	return nil
 }
 
-func (advancedPersonality) Parent() core.CommandStatic {
-	return Advanced
+func (advancedAutoContextSize) Parent() core.CommandStatic {
+	return AdvancedAutoContext
 }
 
-func (advancedPersonality) Children() core.CommandsStatic {
+func (advancedAutoContextSize) Children() core.CommandsStatic {
 	return core.CommandsStatic{
-		AdvancedPersonalityShow,
-		AdvancedPersonalitySet,
-		AdvancedPersonalityAdd,
-		AdvancedPersonalityEdit,
-		AdvancedPersonalityDelete,
-		AdvancedPersonalityInfo,
-		AdvancedPersonalityList,
+		AdvancedAutoContextSizeShow,
+		AdvancedAutoContextSizeSet,
 	}
 }
 
-func (advancedPersonality) Init() error {
+func (advancedAutoContextSize) Init() error {
 	return nil
 }
 
-func (advancedPersonality) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (advancedAutoContextSize) Run(m *core.EventMessage) (any, core.Urr, error) {
 	return m.Usage(), core.UrrMissingArgs, nil
 }
 
-//////////////////////
-//                  //
-// personality show //
-//                  //
-//////////////////////
+////////////////////////////
+//                        //
+// auto context size show //
+//                        //
+////////////////////////////
 
-var AdvancedPersonalityShow = advancedPersonalityShow{}
+var AdvancedAutoContextSizeShow = advancedAutoContextSizeShow{}
 
-type advancedPersonalityShow struct{}
+type advancedAutoContextSizeShow struct{}
 
-func (c advancedPersonalityShow) Type() core.CommandType {
+func (c advancedAutoContextSizeShow) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityShow) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextSizeShow) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityShow) Names() []string {
+func (advancedAutoContextSizeShow) Names() []string {
 	return core.AliasesShow
 }
 
-func (advancedPersonalityShow) Description() string {
-	return "Show God's current personality."
+func (advancedAutoContextSizeShow) Description() string {
+	return "Show how many recent messages are kept."
 }
 
-func (advancedPersonalityShow) UsageArgs() string {
+func (advancedAutoContextSizeShow) UsageArgs() string {
 	return ""
 }
 
-func (c advancedPersonalityShow) Category() core.CommandCategory {
+func (c advancedAutoContextSizeShow) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityShow) Examples() []string {
+func (advancedAutoContextSizeShow) Examples() []string {
 	return nil
 }
 
-func (advancedPersonalityShow) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedAutoContextSizeShow) Parent() core.CommandStatic {
+	return AdvancedAutoContextSize
 }
 
-func (advancedPersonalityShow) Children() core.CommandsStatic {
+func (advancedAutoContextSizeShow) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityShow) Init() error {
+func (advancedAutoContextSizeShow) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (c advancedAutoContextSizeShow) Run(m *core.EventMessage) (any, core.Urr, error) {
 	switch m.Frontend.Type() {
 	case discord.Frontend.Type():
 		return c.discord(m)
@@ -1788,92 +1907,90 @@ func (c advancedPersonalityShow) Run(m *core.EventMessage) (any, core.Urr, error
 	}
 }
 
-func (c advancedPersonalityShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	personality, err := c.core(m)
+func (c advancedAutoContextSizeShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	size, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
 	embed := &dg.MessageEmbed{
-		Description: c.fmt("**" + personality.Name + "**"),
+		Description: c.fmt(fmt.Sprintf("**%d**", size)),
 	}
 	return embed, nil, nil
 }
 
-func (c advancedPersonalityShow) text(m *core.EventMessage) (string, core.Urr, error) {
-	personality, err := c.core(m)
+func (c advancedAutoContextSizeShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	size, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	return c.fmt(personality.Name), nil, nil
+	return c.fmt(strconv.Itoa(size)), nil, nil
 }
 
-func (advancedPersonalityShow) fmt(pName string) string {
-	return "Active personality: " + pName
+func (advancedAutoContextSizeShow) fmt(size string) string {
+	return "Up to " + size + " recent messages are kept as context."
 }
 
-func (advancedPersonalityShow) core(m *core.EventMessage) (Personality, error) {
+func (advancedAutoContextSizeShow) core(m *core.EventMessage) (int, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return Personality{}, err
-	}
-	p, _, err := PersonalityActive(here)
-	if err != nil {
-		return Personality{}, err
+		return 0, err
 	}
-	return p, nil
+	return ContextSizeGet(here)
 }
 
-/////////////////////
-//                 //
-// personality set //
-//                 //
-/////////////////////
+///////////////////////////
+//                       //
+// auto context size set //
+//                       //
+///////////////////////////
 
-var AdvancedPersonalitySet = advancedPersonalitySet{}
+var AdvancedAutoContextSizeSet = advancedAutoContextSizeSet{}
 
-type advancedPersonalitySet struct{}
+type advancedAutoContextSizeSet struct{}
 
-func (c advancedPersonalitySet) Type() core.CommandType {
+func (c advancedAutoContextSizeSet) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalitySet) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextSizeSet) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalitySet) Names() []string {
+func (advancedAutoContextSizeSet) Names() []string {
 	return core.AliasesSet
 }
 
-func (advancedPersonalitySet) Description() string {
-	return "Set God's personality."
+func (advancedAutoContextSizeSet) Description() string {
+	return "Set how many recent messages are kept."
 }
 
-func (c advancedPersonalitySet) UsageArgs() string {
-	return "<name>"
+func (advancedAutoContextSizeSet) UsageArgs() string {
+	return "<messages>"
 }
 
-func (c advancedPersonalitySet) Category() core.CommandCategory {
+func (c advancedAutoContextSizeSet) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalitySet) Examples() []string {
-	return nil
+func (advancedAutoContextSizeSet) Examples() []string {
+	return []string{
+		"10",
+	}
 }
 
-func (advancedPersonalitySet) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedAutoContextSizeSet) Parent() core.CommandStatic {
+	return AdvancedAutoContextSize
 }
 
-func (c advancedPersonalitySet) Children() core.CommandsStatic {
+func (advancedAutoContextSizeSet) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalitySet) Init() error {
+func (advancedAutoContextSizeSet) Init() error {
 	return nil
 }
 
-func (c advancedPersonalitySet) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (c advancedAutoContextSizeSet) Run(m *core.EventMessage) (any, core.Urr, error) {
 	if len(m.Command.Args) < 1 {
 		return m.Usage(), core.UrrMissingArgs, nil
 	}
@@ -1886,96 +2003,185 @@ func (c advancedPersonalitySet) Run(m *core.EventMessage) (any, core.Urr, error)
 	}
 }
 
-func (c advancedPersonalitySet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	name, urr, err := c.core(m)
+func (c advancedAutoContextSizeSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	size, urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
 	embed := &dg.MessageEmbed{
-		Description: c.fmt("**"+name+"**", urr),
+		Description: c.fmt(fmt.Sprintf("**%d**", size), urr),
 	}
 	return embed, urr, nil
 }
 
-func (c advancedPersonalitySet) text(m *core.EventMessage) (string, core.Urr, error) {
-	name, urr, err := c.core(m)
+func (c advancedAutoContextSizeSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	size, urr, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	return c.fmt(name, urr), urr, nil
+	return c.fmt(strconv.Itoa(size), urr), urr, nil
 }
 
-func (advancedPersonalitySet) fmt(name string, urr core.Urr) string {
+func (advancedAutoContextSizeSet) fmt(size string, urr core.Urr) string {
 	switch urr {
 	case nil:
-		return "Set personality to " + name + "."
-	case UrrPersonalityNotFound:
-		return "Couldn't find the " + name + " personality."
+		return "Updated the context size to " + size + " messages."
+	case UrrInvalidSize:
+		return fmt.Sprintf("The context size must be between 1 and %d messages.", ContextSizeMax)
 	default:
-		return fmt.Sprint(urr)
+		return urr.Error()
 	}
 }
 
-func (advancedPersonalitySet) core(m *core.EventMessage) (string, core.Urr, error) {
+func (advancedAutoContextSizeSet) core(m *core.EventMessage) (int, core.Urr, error) {
+	size, err := strconv.Atoi(m.Command.Args[0])
+	if err != nil {
+		return 0, UrrNotInt, nil
+	}
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return "", nil, err
+		return 0, nil, err
 	}
-	return PersonalitySet(here, m.Command.Args[0])
+	urr, err := ContextSizeSet(here, size)
+	return size, urr, err
 }
 
-/////////////////////
-//                 //
-// personality add //
-//                 //
-/////////////////////
+/////////////////////////
+//                     //
+// auto context people //
+//                     //
+/////////////////////////
 
-var AdvancedPersonalityAdd = advancedPersonalityAdd{}
+var AdvancedAutoContextPeople = advancedAutoContextPeople{}
 
-type advancedPersonalityAdd struct{}
+type advancedAutoContextPeople struct{}
 
-func (c advancedPersonalityAdd) Type() core.CommandType {
+func (c advancedAutoContextPeople) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityAdd) Permitted(m *core.EventMessage) bool {
+func (c advancedAutoContextPeople) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityAdd) Names() []string {
+func (advancedAutoContextPeople) Names() []string {
+	return []string{
+		"people",
+		"authors",
+		"users",
+	}
+}
+
+func (advancedAutoContextPeople) Description() string {
+	return "Control whose messages are never kept."
+}
+
+func (c advancedAutoContextPeople) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedAutoContextPeople) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextPeople) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextPeople) Parent() core.CommandStatic {
+	return AdvancedAutoContext
+}
+
+func (advancedAutoContextPeople) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedAutoContextPeopleAdd,
+		AdvancedAutoContextPeopleDelete,
+		AdvancedAutoContextPeopleList,
+	}
+}
+
+func (advancedAutoContextPeople) Init() error {
+	return nil
+}
+
+func (advancedAutoContextPeople) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+// Returns how an excluded person is shown. On Discord the person is mentioned,
+// which doesn't ping them inside of embeds.
+func personName(m *core.EventMessage, person int64) (string, error) {
+	if m.Frontend.Type() == discord.Frontend.Type() {
+		id, err := core.DB.ScopeID(person)
+		if err != nil {
+			return "", err
+		}
+		return "<@" + id + ">", nil
+	}
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+	pm, err := core.Frontends.CreateMessage(person, here, "")
+	if err != nil {
+		return "", err
+	}
+	return pm.Author.DisplayName()
+}
+
+/////////////////////////////
+//                         //
+// auto context people add //
+//                         //
+/////////////////////////////
+
+var AdvancedAutoContextPeopleAdd = advancedAutoContextPeopleAdd{}
+
+type advancedAutoContextPeopleAdd struct{}
+
+func (c advancedAutoContextPeopleAdd) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextPeopleAdd) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextPeopleAdd) Names() []string {
 	return core.AliasesAdd
 }
 
-func (advancedPersonalityAdd) Description() string {
-	return "Add a new God personality."
+func (advancedAutoContextPeopleAdd) Description() string {
+	return "Never keep a person's messages."
 }
 
-func (advancedPersonalityAdd) UsageArgs() string {
-	return "<personality> <instructions...>"
+func (advancedAutoContextPeopleAdd) UsageArgs() string {
+	return "<person>"
 }
 
-func (c advancedPersonalityAdd) Category() core.CommandCategory {
+func (c advancedAutoContextPeopleAdd) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityAdd) Examples() []string {
+func (advancedAutoContextPeopleAdd) Examples() []string {
 	return nil
 }
 
-func (advancedPersonalityAdd) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedAutoContextPeopleAdd) Parent() core.CommandStatic {
+	return AdvancedAutoContextPeople
 }
 
-func (advancedPersonalityAdd) Children() core.CommandsStatic {
+func (advancedAutoContextPeopleAdd) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityAdd) Init() error {
+func (advancedAutoContextPeopleAdd) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
-	if len(m.Command.Args) < 2 {
+func (c advancedAutoContextPeopleAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
 		return m.Usage(), core.UrrMissingArgs, nil
 	}
 
@@ -1987,117 +2193,3530 @@ func (c advancedPersonalityAdd) Run(m *core.EventMessage) (any, core.Urr, error)
 	}
 }
 
-func (c advancedPersonalityAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	name, prompt, urr, err := c.core(m)
+func (c advancedAutoContextPeopleAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	if urr != nil {
-		return &dg.MessageEmbed{
-			Description: c.fmt(urr),
-		}, urr, nil
-	}
 	embed := &dg.MessageEmbed{
-		Title: c.fmt(urr),
-		Fields: []*dg.MessageEmbedField{
-			{
-				Name:   "Personality",
-				Value:  name,
-				Inline: false,
-			},
-			{
-				Name:   "Instructions",
-				Value:  prompt,
-				Inline: false,
-			},
-		},
+		Description: c.fmt(urr),
 	}
-	return embed, nil, nil
+	return embed, urr, nil
 }
 
-func (c advancedPersonalityAdd) text(m *core.EventMessage) (string, core.Urr, error) {
-	_, _, urr, err := c.core(m)
+func (c advancedAutoContextPeopleAdd) text(m *core.EventMessage) (string, core.Urr, error) {
+	urr, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
 	return c.fmt(urr), urr, nil
 }
 
-func (advancedPersonalityAdd) fmt(urr core.Urr) string {
+func (advancedAutoContextPeopleAdd) fmt(urr core.Urr) string {
 	switch urr {
 	case nil:
-		return "Added personality."
+		return "Their messages will no longer be kept as context."
+	case UrrAlreadyExcluded:
+		return "Their messages are already not being kept."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedAutoContextPeopleAdd) core(m *core.EventMessage) (core.Urr, error) {
+	person, err := nick.ParsePersonHere(m, m.Command.Args[0])
+	if err != nil {
+		return UrrPersonNotFound, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return ContextPersonExclude(here, person)
+}
+
+////////////////////////////////
+//                            //
+// auto context people delete //
+//                            //
+////////////////////////////////
+
+var AdvancedAutoContextPeopleDelete = advancedAutoContextPeopleDelete{}
+
+type advancedAutoContextPeopleDelete struct{}
+
+func (c advancedAutoContextPeopleDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextPeopleDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextPeopleDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedAutoContextPeopleDelete) Description() string {
+	return "Start keeping a person's messages again."
+}
+
+func (advancedAutoContextPeopleDelete) UsageArgs() string {
+	return "<person>"
+}
+
+func (c advancedAutoContextPeopleDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextPeopleDelete) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextPeopleDelete) Parent() core.CommandStatic {
+	return AdvancedAutoContextPeople
+}
+
+func (advancedAutoContextPeopleDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextPeopleDelete) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextPeopleDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextPeopleDelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAutoContextPeopleDelete) text(m *core.EventMessage) (string, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (advancedAutoContextPeopleDelete) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Their messages will be kept as context again."
+	case UrrNotExcluded:
+		return "Their messages are already being kept."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedAutoContextPeopleDelete) core(m *core.EventMessage) (core.Urr, error) {
+	person, err := nick.ParsePersonHere(m, m.Command.Args[0])
+	if err != nil {
+		return UrrPersonNotFound, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return ContextPersonInclude(here, person)
+}
+
+//////////////////////////////
+//                          //
+// auto context people list //
+//                          //
+//////////////////////////////
+
+var AdvancedAutoContextPeopleList = advancedAutoContextPeopleList{}
+
+type advancedAutoContextPeopleList struct{}
+
+func (c advancedAutoContextPeopleList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextPeopleList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextPeopleList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedAutoContextPeopleList) Description() string {
+	return "List the people whose messages are never kept."
+}
+
+func (advancedAutoContextPeopleList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoContextPeopleList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextPeopleList) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextPeopleList) Parent() core.CommandStatic {
+	return AdvancedAutoContextPeople
+}
+
+func (advancedAutoContextPeopleList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextPeopleList) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextPeopleList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextPeopleList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	names, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Title:       "Excluded People",
+		Description: c.fmt(names, "\n", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAutoContextPeopleList) text(m *core.EventMessage) (string, core.Urr, error) {
+	names, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(names, ", ", urr), urr, nil
+}
+
+func (advancedAutoContextPeopleList) fmt(names []string, sep string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return strings.Join(names, sep)
+	case UrrNothingExcluded:
+		return "Nobody's messages are being excluded."
 	default:
 		return urr.Error()
 	}
 }
 
-func (advancedPersonalityAdd) core(m *core.EventMessage) (string, string, core.Urr, error) {
-	here, err := m.Here.ScopeLogical()
-	if err != nil {
-		return "", "", nil, err
-	}
-	name := m.Command.Args[0]
-	prompt := m.RawArgs(1)
-	urr, err := PersonalityAdd(here, name, prompt)
-	return name, prompt, urr, err
+func (advancedAutoContextPeopleList) core(m *core.EventMessage) ([]string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	people, urr, err := ContextPeopleExcluded(here)
+	if urr != nil || err != nil {
+		return nil, urr, err
+	}
+	var names []string
+	for _, p := range people {
+		name, err := personName(m, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil, nil
+}
+
+///////////////////////////
+//                       //
+// auto context keywords //
+//                       //
+///////////////////////////
+
+var AdvancedAutoContextKeywords = advancedAutoContextKeywords{}
+
+type advancedAutoContextKeywords struct{}
+
+func (c advancedAutoContextKeywords) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextKeywords) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextKeywords) Names() []string {
+	return []string{
+		"keywords",
+		"keyword",
+		"words",
+	}
+}
+
+func (advancedAutoContextKeywords) Description() string {
+	return "Control which keywords stop a message from being kept."
+}
+
+func (c advancedAutoContextKeywords) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedAutoContextKeywords) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextKeywords) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextKeywords) Parent() core.CommandStatic {
+	return AdvancedAutoContext
+}
+
+func (advancedAutoContextKeywords) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedAutoContextKeywordsAdd,
+		AdvancedAutoContextKeywordsDelete,
+		AdvancedAutoContextKeywordsList,
+	}
+}
+
+func (advancedAutoContextKeywords) Init() error {
+	return nil
+}
+
+func (advancedAutoContextKeywords) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////////////////////
+//                           //
+// auto context keywords add //
+//                           //
+///////////////////////////////
+
+var AdvancedAutoContextKeywordsAdd = advancedAutoContextKeywordsAdd{}
+
+type advancedAutoContextKeywordsAdd struct{}
+
+func (c advancedAutoContextKeywordsAdd) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextKeywordsAdd) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextKeywordsAdd) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedAutoContextKeywordsAdd) Description() string {
+	return "Never keep messages that contain the keyword."
+}
+
+func (advancedAutoContextKeywordsAdd) UsageArgs() string {
+	return "<keyword...>"
+}
+
+func (c advancedAutoContextKeywordsAdd) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextKeywordsAdd) Examples() []string {
+	return []string{
+		"my address",
+	}
+}
+
+func (advancedAutoContextKeywordsAdd) Parent() core.CommandStatic {
+	return AdvancedAutoContextKeywords
+}
+
+func (advancedAutoContextKeywordsAdd) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextKeywordsAdd) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextKeywordsAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextKeywordsAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	keyword, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+keyword+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAutoContextKeywordsAdd) text(m *core.EventMessage) (string, core.Urr, error) {
+	keyword, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(keyword, urr), urr, nil
+}
+
+func (advancedAutoContextKeywordsAdd) fmt(keyword string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Messages containing " + keyword + " will no longer be kept as context."
+	case UrrAlreadyExcluded:
+		return "The keyword " + keyword + " is already excluded."
+	case UrrKeywordTooLong:
+		return fmt.Sprintf("The keyword must be at most %d characters long.", keywordMax)
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedAutoContextKeywordsAdd) core(m *core.EventMessage) (string, core.Urr, error) {
+	keyword := strings.ToLower(m.RawArgs(0))
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	urr, err := ContextKeywordExclude(here, keyword)
+	return keyword, urr, err
+}
+
+//////////////////////////////////
+//                              //
+// auto context keywords delete //
+//                              //
+//////////////////////////////////
+
+var AdvancedAutoContextKeywordsDelete = advancedAutoContextKeywordsDelete{}
+
+type advancedAutoContextKeywordsDelete struct{}
+
+func (c advancedAutoContextKeywordsDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextKeywordsDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextKeywordsDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedAutoContextKeywordsDelete) Description() string {
+	return "Start keeping messages that contain the keyword again."
+}
+
+func (advancedAutoContextKeywordsDelete) UsageArgs() string {
+	return "<keyword...>"
+}
+
+func (c advancedAutoContextKeywordsDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextKeywordsDelete) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextKeywordsDelete) Parent() core.CommandStatic {
+	return AdvancedAutoContextKeywords
+}
+
+func (advancedAutoContextKeywordsDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextKeywordsDelete) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextKeywordsDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextKeywordsDelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	keyword, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+keyword+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAutoContextKeywordsDelete) text(m *core.EventMessage) (string, core.Urr, error) {
+	keyword, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(keyword, urr), urr, nil
+}
+
+func (advancedAutoContextKeywordsDelete) fmt(keyword string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Messages containing " + keyword + " will be kept as context again."
+	case UrrNotExcluded:
+		return "The keyword " + keyword + " isn't excluded."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedAutoContextKeywordsDelete) core(m *core.EventMessage) (string, core.Urr, error) {
+	keyword := strings.ToLower(m.RawArgs(0))
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	urr, err := ContextKeywordInclude(here, keyword)
+	return keyword, urr, err
+}
+
+////////////////////////////////
+//                            //
+// auto context keywords list //
+//                            //
+////////////////////////////////
+
+var AdvancedAutoContextKeywordsList = advancedAutoContextKeywordsList{}
+
+type advancedAutoContextKeywordsList struct{}
+
+func (c advancedAutoContextKeywordsList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoContextKeywordsList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoContextKeywordsList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedAutoContextKeywordsList) Description() string {
+	return "List the keywords that stop a message from being kept."
+}
+
+func (advancedAutoContextKeywordsList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoContextKeywordsList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoContextKeywordsList) Examples() []string {
+	return nil
+}
+
+func (advancedAutoContextKeywordsList) Parent() core.CommandStatic {
+	return AdvancedAutoContextKeywords
+}
+
+func (advancedAutoContextKeywordsList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoContextKeywordsList) Init() error {
+	return nil
+}
+
+func (c advancedAutoContextKeywordsList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedAutoContextKeywordsList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	keywords, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Title:       "Excluded Keywords",
+		Description: c.fmt(keywords, "\n", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedAutoContextKeywordsList) text(m *core.EventMessage) (string, core.Urr, error) {
+	keywords, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(keywords, ", ", urr), urr, nil
+}
+
+func (advancedAutoContextKeywordsList) fmt(keywords []string, sep string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return strings.Join(keywords, sep)
+	case UrrNothingExcluded:
+		return "No keywords are being excluded."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedAutoContextKeywordsList) core(m *core.EventMessage) ([]string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ContextKeywordsExcluded(here)
+}
+
+////////////
+//        //
+// redeem //
+//        //
+////////////
+
+var AdvancedRedeem = advancedRedeem{}
+
+type advancedRedeem struct{}
+
+func (c advancedRedeem) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeem) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeem) Names() []string {
+	return []string{
+		"redeem",
+	}
+}
+
+func (advancedRedeem) Description() string {
+	return "Control which redeem triggers God."
+}
+
+func (c advancedRedeem) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedRedeem) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeem) Examples() []string {
+	return nil
+}
+
+func (advancedRedeem) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedRedeem) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedRedeemShow,
+		AdvancedRedeemSet,
+		AdvancedRedeemCreate,
+	}
+}
+
+func (advancedRedeem) Init() error {
+	return nil
+}
+
+func (advancedRedeem) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+/////////////////
+//             //
+// redeem show //
+//             //
+/////////////////
+
+var AdvancedRedeemShow = advancedRedeemShow{}
+
+type advancedRedeemShow struct{}
+
+func (c advancedRedeemShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedRedeemShow) Description() string {
+	return "Show what the current redeem is set to."
+}
+
+func (advancedRedeemShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedRedeemShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemShow) Examples() []string {
+	return nil
+}
+
+func (advancedRedeemShow) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemShow) Init() error {
+	return nil
+}
+
+func (c advancedRedeemShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	u, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(u, urr), urr, nil
+}
+
+func (advancedRedeemShow) fmt(u uuid.UUID, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "The god redeem is set to: " + u.String()
+	case core.UrrValNil:
+		return "The god redeem has not been set."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedRedeemShow) core(m *core.EventMessage) (uuid.UUID, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return uuid.UUID{}, nil, err
+	}
+	return RedeemGet(here)
+}
+
+////////////////
+//            //
+// redeem set //
+//            //
+////////////////
+
+var AdvancedRedeemSet = advancedRedeemSet{}
+
+type advancedRedeemSet struct{}
+
+func (c advancedRedeemSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedRedeemSet) Description() string {
+	return "Set the ID of the god redeem."
+}
+
+func (advancedRedeemSet) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedRedeemSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemSet) Examples() []string {
+	return nil
+}
+
+func (advancedRedeemSet) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemSet) Init() error {
+	return nil
+}
+
+func (c advancedRedeemSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return "Set the god redeem.", nil, nil
+}
+
+func (advancedRedeemSet) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return RedeemSet(here, m.Command.Args[0])
+}
+
+///////////////////
+//               //
+// redeem create //
+//               //
+///////////////////
+
+var AdvancedRedeemCreate = advancedRedeemCreate{}
+
+type advancedRedeemCreate struct{}
+
+func (c advancedRedeemCreate) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemCreate) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemCreate) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedRedeemCreate) Description() string {
+	return "Create a new channel point reward that asks for the viewer's question and set it as the god redeem."
+}
+
+func (advancedRedeemCreate) UsageArgs() string {
+	return "<cost> <title...>"
+}
+
+func (c advancedRedeemCreate) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemCreate) Examples() []string {
+	return []string{
+		"1000 Ask God",
+	}
+}
+
+func (advancedRedeemCreate) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemCreate) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemCreate) Init() error {
+	return nil
+}
+
+func (c advancedRedeemCreate) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return ctwitch.FmtUrr(urr), urr, nil
+	}
+	return "Created the reward and set it as the god redeem, its ID is: " + id, nil, nil
+}
+
+func (advancedRedeemCreate) core(m *core.EventMessage) (string, core.Urr, error) {
+	// the input is what gets asked
+	id, urr, err := ctwitch.CreateFrom(m, ctwitch.RewardOptions{
+		Prompt:        redeemPrompt,
+		InputRequired: true,
+	})
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	return id, nil, RedeemSet(here, id)
+}
+
+/////////////////
+//             //
+// personality //
+//             //
+/////////////////
+
+var AdvancedPersonality = advancedPersonality{}
+
+type advancedPersonality struct{}
+
+func (c advancedPersonality) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonality) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonality) Names() []string {
+	return []string{
+		"personality",
+		"mood",
+		"cosplay",
+	}
+}
+
+func (advancedPersonality) Description() string {
+	return "Control God's personality."
+}
+
+func (c advancedPersonality) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedPersonality) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonality) Examples() []string {
+	return nil
+}
+
+func (advancedPersonality) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedPersonality) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedPersonalityShow,
+		AdvancedPersonalitySet,
+		AdvancedPersonalityAdd,
+		AdvancedPersonalityEdit,
+		AdvancedPersonalityDelete,
+		AdvancedPersonalityInfo,
+		AdvancedPersonalityList,
+	}
+}
+
+func (advancedPersonality) Init() error {
+	return nil
+}
+
+func (advancedPersonality) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+//////////////////////
+//                  //
+// personality show //
+//                  //
+//////////////////////
+
+var AdvancedPersonalityShow = advancedPersonalityShow{}
+
+type advancedPersonalityShow struct{}
+
+func (c advancedPersonalityShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedPersonalityShow) Description() string {
+	return "Show God's current personality."
+}
+
+func (advancedPersonalityShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedPersonalityShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityShow) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityShow) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityShow) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	personality, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**" + personality.Name + "**"),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedPersonalityShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	personality, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(personality.Name), nil, nil
+}
+
+func (advancedPersonalityShow) fmt(pName string) string {
+	return "Active personality: " + pName
+}
+
+func (advancedPersonalityShow) core(m *core.EventMessage) (Personality, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return Personality{}, err
+	}
+	p, _, err := PersonalityActive(here)
+	if err != nil {
+		return Personality{}, err
+	}
+	return p, nil
+}
+
+/////////////////////
+//                 //
+// personality set //
+//                 //
+/////////////////////
+
+var AdvancedPersonalitySet = advancedPersonalitySet{}
+
+type advancedPersonalitySet struct{}
+
+func (c advancedPersonalitySet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalitySet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalitySet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedPersonalitySet) Description() string {
+	return "Set God's personality."
+}
+
+func (c advancedPersonalitySet) UsageArgs() string {
+	return "<name>"
+}
+
+func (c advancedPersonalitySet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalitySet) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalitySet) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (c advancedPersonalitySet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalitySet) Init() error {
+	return nil
+}
+
+func (c advancedPersonalitySet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalitySet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	name, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+name+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedPersonalitySet) text(m *core.EventMessage) (string, core.Urr, error) {
+	name, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(name, urr), urr, nil
+}
+
+func (advancedPersonalitySet) fmt(name string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Set personality to " + name + "."
+	case UrrPersonalityNotFound:
+		return "Couldn't find the " + name + " personality."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedPersonalitySet) core(m *core.EventMessage) (string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	return PersonalitySet(here, m.Command.Args[0])
+}
+
+/////////////////////
+//                 //
+// personality add //
+//                 //
+/////////////////////
+
+var AdvancedPersonalityAdd = advancedPersonalityAdd{}
+
+type advancedPersonalityAdd struct{}
+
+func (c advancedPersonalityAdd) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityAdd) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityAdd) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedPersonalityAdd) Description() string {
+	return "Add a new God personality."
+}
+
+func (advancedPersonalityAdd) UsageArgs() string {
+	return "<personality> <instructions...>"
+}
+
+func (c advancedPersonalityAdd) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityAdd) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityAdd) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityAdd) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityAdd) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	name, prompt, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return &dg.MessageEmbed{
+			Description: c.fmt(urr),
+		}, urr, nil
+	}
+	embed := &dg.MessageEmbed{
+		Title: c.fmt(urr),
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Personality",
+				Value:  name,
+				Inline: false,
+			},
+			{
+				Name:   "Instructions",
+				Value:  prompt,
+				Inline: false,
+			},
+		},
+	}
+	return embed, nil, nil
+}
+
+func (c advancedPersonalityAdd) text(m *core.EventMessage) (string, core.Urr, error) {
+	_, _, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (advancedPersonalityAdd) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Added personality."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedPersonalityAdd) core(m *core.EventMessage) (string, string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", "", nil, err
+	}
+	name := m.Command.Args[0]
+	prompt := m.RawArgs(1)
+	urr, err := PersonalityAdd(here, name, prompt)
+	return name, prompt, urr, err
+}
+
+//////////////////////
+//                  //
+// personality edit //
+//                  //
+//////////////////////
+
+var AdvancedPersonalityEdit = advancedPersonalityEdit{}
+
+type advancedPersonalityEdit struct{}
+
+func (c advancedPersonalityEdit) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityEdit) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityEdit) Names() []string {
+	return core.AliasesEdit
+}
+
+func (advancedPersonalityEdit) Description() string {
+	return "Edit one of God's personalities."
+}
+
+func (advancedPersonalityEdit) UsageArgs() string {
+	return "<personality> <instructions...>"
+}
+
+func (c advancedPersonalityEdit) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityEdit) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityEdit) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityEdit) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityEdit) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityEdit) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityEdit) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	name, prompt, old, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return &dg.MessageEmbed{
+			Description: c.fmt("**"+name+"**", urr),
+		}, urr, nil
+	}
+	embed := &dg.MessageEmbed{
+		Title: c.fmt(name, urr),
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Personality",
+				Value:  name,
+				Inline: false,
+			},
+			{
+				Name:   "New Instructions",
+				Value:  prompt,
+				Inline: false,
+			},
+			{
+				Name:   "Old Instructions",
+				Value:  old,
+				Inline: false,
+			},
+		},
+	}
+	return embed, nil, nil
+}
+
+func (c advancedPersonalityEdit) text(m *core.EventMessage) (string, core.Urr, error) {
+	name, _, _, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt("'"+name+"'", urr), urr, nil
+}
+
+func (advancedPersonalityEdit) fmt(pName string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Edited personality."
+	case UrrGlobalPersonality:
+		return pName + " is a global personality, can't edit."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedPersonalityEdit) core(m *core.EventMessage) (string, string, string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", "", "", nil, err
+	}
+	name := m.Command.Args[0]
+	prompt := m.RawArgs(1)
+	old, urr, err := PersonalityEdit(here, name, prompt)
+	return name, prompt, old, urr, err
+}
+
+////////////////////////
+//                    //
+// personality delete //
+//                    //
+////////////////////////
+
+var AdvancedPersonalityDelete = advancedPersonalityDelete{}
+
+type advancedPersonalityDelete struct{}
+
+func (c advancedPersonalityDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedPersonalityDelete) Description() string {
+	return "Delete one of God's personalities."
+}
+
+func (advancedPersonalityDelete) UsageArgs() string {
+	return "<personality>"
+}
+
+func (c advancedPersonalityDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityDelete) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityDelete) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityDelete) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityDelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedPersonalityDelete) text(m *core.EventMessage) (string, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (advancedPersonalityDelete) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Deleted personality."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedPersonalityDelete) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return PersonalityDelete(here, m.Command.Args[0])
+}
+
+//////////////////////
+//                  //
+// personality info //
+//                  //
+//////////////////////
+
+var AdvancedPersonalityInfo = advancedPersonalityInfo{}
+
+type advancedPersonalityInfo struct{}
+
+func (c advancedPersonalityInfo) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityInfo) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityInfo) Names() []string {
+	return []string{
+		"info",
+	}
+}
+
+func (advancedPersonalityInfo) Description() string {
+	return "View information on the specified personality."
+}
+
+func (advancedPersonalityInfo) UsageArgs() string {
+	return "<personality>"
+}
+
+func (c advancedPersonalityInfo) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityInfo) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityInfo) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityInfo) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityInfo) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityInfo) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityInfo) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	personality, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		embed := &dg.MessageEmbed{
+			Description: urr.Error(),
+		}
+		return embed, urr, nil
+	}
+	embed := &dg.MessageEmbed{
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Personality",
+				Value:  personality.Name,
+				Inline: false,
+			},
+			{
+				Name:   "Instructions",
+				Value:  personality.Prompt,
+				Inline: false,
+			},
+		},
+	}
+	return embed, nil, nil
+}
+
+func (c advancedPersonalityInfo) text(m *core.EventMessage) (string, core.Urr, error) {
+	personality, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	if urr != nil {
+		return urr.Error(), urr, nil
+	}
+	return personality.Name + ": " + personality.Prompt, nil, nil
+}
+
+func (advancedPersonalityInfo) core(m *core.EventMessage) (Personality, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return Personality{}, nil, err
+	}
+	return PersonalityGet(here, m.Command.Args[0])
+}
+
+//////////////////////
+//                  //
+// personality list //
+//                  //
+//////////////////////
+
+var AdvancedPersonalityList = advancedPersonalityList{}
+
+type advancedPersonalityList struct{}
+
+func (c advancedPersonalityList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPersonalityList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPersonalityList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedPersonalityList) Description() string {
+	return "List all the available personalities."
+}
+
+func (advancedPersonalityList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedPersonalityList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPersonalityList) Examples() []string {
+	return nil
+}
+
+func (advancedPersonalityList) Parent() core.CommandStatic {
+	return AdvancedPersonality
+}
+
+func (advancedPersonalityList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPersonalityList) Init() error {
+	return nil
+}
+
+func (c advancedPersonalityList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedPersonalityList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	active, personalities, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	var b strings.Builder
+	for _, p := range personalities {
+		if p.Name == active.Name {
+			p.Name = "**" + p.Name + "**"
+		}
+		b.WriteString("- ")
+		b.WriteString(p.Name)
+		b.WriteString("\n")
+	}
+	embed := &dg.MessageEmbed{
+		Description: b.String(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedPersonalityList) text(m *core.EventMessage) (string, core.Urr, error) {
+	_, personalities, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	var names []string
+	for _, p := range personalities {
+		names = append(names, p.Name)
+	}
+	return "Personalities: " + strings.Join(names, ", "), nil, nil
+}
+
+func (advancedPersonalityList) core(m *core.EventMessage) (Personality, []Personality, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return Personality{}, nil, err
+	}
+	ps, err := PersonalitiesList(here)
+	if err != nil {
+		return Personality{}, nil, err
+	}
+	active, _, err := PersonalityActive(here)
+	if err != nil {
+		return Personality{}, nil, err
+	}
+	return active, ps, nil
+}
+
+////////////
+//        //
+// memory //
+//        //
+////////////
+
+var AdvancedMemory = advancedMemory{}
+
+type advancedMemory struct{}
+
+func (c advancedMemory) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedMemory) Permitted(*core.EventMessage) bool {
+	return true
+}
+
+func (advancedMemory) Names() []string {
+	return []string{
+		"memory",
+		"memories",
+		"mem",
+	}
+}
+
+func (advancedMemory) Description() string {
+	return "Control what God remembers of your conversations."
+}
+
+func (c advancedMemory) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedMemory) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemory) Examples() []string {
+	return nil
+}
+
+func (advancedMemory) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedMemory) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedMemoryShow,
+		AdvancedMemoryClear,
+		AdvancedMemoryExport,
+		AdvancedMemoryRetention,
+		AdvancedMemoryBudget,
+	}
+}
+
+func (advancedMemory) Init() error {
+	return nil
+}
+
+func (advancedMemory) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+/////////////////
+//             //
+// memory show //
+//             //
+/////////////////
+
+var AdvancedMemoryShow = advancedMemoryShow{}
+
+type advancedMemoryShow struct{}
+
+func (c advancedMemoryShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedMemoryShow) Description() string {
+	return "Show what God remembers of your conversation."
+}
+
+func (advancedMemoryShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedMemoryShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryShow) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryShow) Parent() core.CommandStatic {
+	return AdvancedMemory
+}
+
+func (advancedMemoryShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryShow) Init() error {
+	return nil
+}
+
+func (c advancedMemoryShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+// The number of latest turns that are shown.
+const memoryShowTurns = 6
+
+func (c advancedMemoryShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	mem, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return &dg.MessageEmbed{Description: urr.Error()}, urr, nil
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       "Memory",
+		Description: mem.Summary,
+		Footer: &dg.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d messages remembered", len(mem.Turns)),
+		},
+	}
+	for _, t := range mem.Turns[max(0, len(mem.Turns)-memoryShowTurns):] {
+		name := "You"
+		if t.Role == RoleAssistant {
+			name = "God"
+		}
+		embed.Fields = append(embed.Fields, &dg.MessageEmbedField{
+			Name:  name,
+			Value: c.truncate(t.Content, 1024),
+		})
+	}
+	return embed, nil, nil
+}
+
+func (c advancedMemoryShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	mem, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	if urr != nil {
+		return urr.Error(), urr, nil
+	}
+	s := fmt.Sprintf("God remembers %d messages of your conversation.", len(mem.Turns))
+	if mem.Summary != "" {
+		s += " Summary of what came before: " + mem.Summary
+	}
+	return s, nil, nil
+}
+
+// Embed fields have a length limit.
+func (advancedMemoryShow) truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
+
+func (advancedMemoryShow) core(m *core.EventMessage) (Memory, core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return Memory{}, nil, err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return Memory{}, nil, err
+	}
+	return MemoryShow(author, here)
+}
+
+//////////////////
+//              //
+// memory clear //
+//              //
+//////////////////
+
+var AdvancedMemoryClear = advancedMemoryClear{}
+
+type advancedMemoryClear struct{}
+
+func (c advancedMemoryClear) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryClear) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryClear) Names() []string {
+	return []string{
+		"clear",
+		"forget",
+		"reset",
+	}
+}
+
+func (advancedMemoryClear) Description() string {
+	return "Make God forget your conversation."
+}
+
+func (advancedMemoryClear) UsageArgs() string {
+	return ""
+}
+
+func (c advancedMemoryClear) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryClear) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryClear) Parent() core.CommandStatic {
+	return AdvancedMemory
+}
+
+func (advancedMemoryClear) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryClear) Init() error {
+	return nil
+}
+
+func (c advancedMemoryClear) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryClear) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	if err := c.core(m); err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedMemoryClear) text(m *core.EventMessage) (string, core.Urr, error) {
+	if err := c.core(m); err != nil {
+		return "", nil, err
+	}
+	return c.fmt(), nil, nil
+}
+
+func (advancedMemoryClear) fmt() string {
+	return "God has forgotten your conversation."
+}
+
+func (advancedMemoryClear) core(m *core.EventMessage) error {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return MemoryClear(author, here)
+}
+
+///////////////////
+//               //
+// memory export //
+//               //
+///////////////////
+
+var AdvancedMemoryExport = advancedMemoryExport{}
+
+type advancedMemoryExport struct{}
+
+func (c advancedMemoryExport) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryExport) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryExport) Names() []string {
+	return []string{
+		"export",
+		"download",
+	}
+}
+
+func (advancedMemoryExport) Description() string {
+	return "Export your whole conversation with God as JSON."
+}
+
+func (advancedMemoryExport) UsageArgs() string {
+	return ""
+}
+
+func (c advancedMemoryExport) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryExport) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryExport) Parent() core.CommandStatic {
+	return AdvancedMemory
+}
+
+func (advancedMemoryExport) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryExport) Init() error {
+	return nil
+}
+
+func (c advancedMemoryExport) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryExport) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	b, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return &dg.MessageEmbed{Description: urr.Error()}, urr, nil
+	}
+	hix, err := m.Here.IDExact()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, err = discord.Client.Session.ChannelFileSend(hix, "memory.json", bytes.NewReader(b))
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, core.UrrSilence
+}
+
+// There's no way to send a file, and the JSON would be way too long to send
+// as a message.
+func (advancedMemoryExport) text(*core.EventMessage) (string, core.Urr, error) {
+	return UrrExportUnsupported.Error(), UrrExportUnsupported, nil
+}
+
+func (advancedMemoryExport) core(m *core.EventMessage) ([]byte, core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return nil, nil, err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return MemoryExport(author, here)
+}
+
+//////////////////////
+//                  //
+// memory retention //
+//                  //
+//////////////////////
+
+var AdvancedMemoryRetention = advancedMemoryRetention{}
+
+type advancedMemoryRetention struct{}
+
+func (c advancedMemoryRetention) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedMemoryRetention) Permitted(m *core.EventMessage) bool {
+	return Advanced.Permitted(m)
+}
+
+func (advancedMemoryRetention) Names() []string {
+	return []string{
+		"retention",
+	}
+}
+
+func (advancedMemoryRetention) Description() string {
+	return "Control how long conversations are remembered for."
+}
+
+func (c advancedMemoryRetention) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedMemoryRetention) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryRetention) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryRetention) Parent() core.CommandStatic {
+	return AdvancedMemory
+}
+
+func (advancedMemoryRetention) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedMemoryRetentionShow,
+		AdvancedMemoryRetentionSet,
+	}
+}
+
+func (advancedMemoryRetention) Init() error {
+	return nil
+}
+
+func (advancedMemoryRetention) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+// Formats a retention for showing, zero means forever.
+func fmtRetention(retention time.Duration) string {
+	if retention == 0 {
+		return "forever"
+	}
+	return "for " + retention.String()
+}
+
+///////////////////////////
+//                       //
+// memory retention show //
+//                       //
+///////////////////////////
+
+var AdvancedMemoryRetentionShow = advancedMemoryRetentionShow{}
+
+type advancedMemoryRetentionShow struct{}
+
+func (c advancedMemoryRetentionShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryRetentionShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryRetentionShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedMemoryRetentionShow) Description() string {
+	return "Show how long conversations are remembered for."
+}
+
+func (advancedMemoryRetentionShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedMemoryRetentionShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryRetentionShow) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryRetentionShow) Parent() core.CommandStatic {
+	return AdvancedMemoryRetention
+}
+
+func (advancedMemoryRetentionShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryRetentionShow) Init() error {
+	return nil
+}
+
+func (c advancedMemoryRetentionShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryRetentionShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	retention, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**" + fmtRetention(retention) + "**"),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedMemoryRetentionShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	retention, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(fmtRetention(retention)), nil, nil
+}
+
+func (advancedMemoryRetentionShow) fmt(retention string) string {
+	return "Conversations are remembered " + retention + "."
+}
+
+func (advancedMemoryRetentionShow) core(m *core.EventMessage) (time.Duration, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, err
+	}
+	return RetentionGet(here)
+}
+
+//////////////////////////
+//                      //
+// memory retention set //
+//                      //
+//////////////////////////
+
+var AdvancedMemoryRetentionSet = advancedMemoryRetentionSet{}
+
+type advancedMemoryRetentionSet struct{}
+
+func (c advancedMemoryRetentionSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryRetentionSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryRetentionSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedMemoryRetentionSet) Description() string {
+	return "Set how long conversations are remembered for, 0 means forever."
+}
+
+func (advancedMemoryRetentionSet) UsageArgs() string {
+	return "<duration>"
+}
+
+func (c advancedMemoryRetentionSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryRetentionSet) Examples() []string {
+	return []string{
+		"1h",
+		"168h",
+		"0",
+	}
+}
+
+func (advancedMemoryRetentionSet) Parent() core.CommandStatic {
+	return AdvancedMemoryRetention
+}
+
+func (advancedMemoryRetentionSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryRetentionSet) Init() error {
+	return nil
+}
+
+func (c advancedMemoryRetentionSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryRetentionSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	retention, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+fmtRetention(retention)+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedMemoryRetentionSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	retention, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(fmtRetention(retention), urr), urr, nil
+}
+
+func (advancedMemoryRetentionSet) fmt(retention string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Conversations will now be remembered " + retention + "."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedMemoryRetentionSet) core(m *core.EventMessage) (time.Duration, core.Urr, error) {
+	retention, err := time.ParseDuration(m.Command.Args[0])
+	if err != nil {
+		return 0, UrrInvalidRetention, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, nil, err
+	}
+	urr, err := RetentionSet(here, retention)
+	return retention, urr, err
+}
+
+///////////////////
+//               //
+// memory budget //
+//               //
+///////////////////
+
+var AdvancedMemoryBudget = advancedMemoryBudget{}
+
+type advancedMemoryBudget struct{}
+
+func (c advancedMemoryBudget) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedMemoryBudget) Permitted(m *core.EventMessage) bool {
+	return Advanced.Permitted(m)
+}
+
+func (advancedMemoryBudget) Names() []string {
+	return []string{
+		"budget",
+		"tokens",
+	}
+}
+
+func (advancedMemoryBudget) Description() string {
+	return "Control how many tokens a conversation can take up before older messages get summarized."
+}
+
+func (c advancedMemoryBudget) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedMemoryBudget) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryBudget) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryBudget) Parent() core.CommandStatic {
+	return AdvancedMemory
+}
+
+func (advancedMemoryBudget) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedMemoryBudgetShow,
+		AdvancedMemoryBudgetSet,
+	}
+}
+
+func (advancedMemoryBudget) Init() error {
+	return nil
+}
+
+func (advancedMemoryBudget) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+////////////////////////
+//                    //
+// memory budget show //
+//                    //
+////////////////////////
+
+var AdvancedMemoryBudgetShow = advancedMemoryBudgetShow{}
+
+type advancedMemoryBudgetShow struct{}
+
+func (c advancedMemoryBudgetShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryBudgetShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryBudgetShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedMemoryBudgetShow) Description() string {
+	return "Show the token budget of conversations."
+}
+
+func (advancedMemoryBudgetShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedMemoryBudgetShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryBudgetShow) Examples() []string {
+	return nil
+}
+
+func (advancedMemoryBudgetShow) Parent() core.CommandStatic {
+	return AdvancedMemoryBudget
+}
+
+func (advancedMemoryBudgetShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryBudgetShow) Init() error {
+	return nil
+}
+
+func (c advancedMemoryBudgetShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryBudgetShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	budget, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(fmt.Sprintf("**%d**", budget)),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedMemoryBudgetShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	budget, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(strconv.Itoa(budget)), nil, nil
+}
+
+func (advancedMemoryBudgetShow) fmt(budget string) string {
+	return "Conversations can take up " + budget + " tokens before older messages get summarized."
+}
+
+func (advancedMemoryBudgetShow) core(m *core.EventMessage) (int, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, err
+	}
+	return BudgetGet(here)
+}
+
+///////////////////////
+//                   //
+// memory budget set //
+//                   //
+///////////////////////
+
+var AdvancedMemoryBudgetSet = advancedMemoryBudgetSet{}
+
+type advancedMemoryBudgetSet struct{}
+
+func (c advancedMemoryBudgetSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedMemoryBudgetSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedMemoryBudgetSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedMemoryBudgetSet) Description() string {
+	return "Set the token budget of conversations."
+}
+
+func (advancedMemoryBudgetSet) UsageArgs() string {
+	return "<tokens>"
+}
+
+func (c advancedMemoryBudgetSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedMemoryBudgetSet) Examples() []string {
+	return []string{
+		"1000",
+	}
+}
+
+func (advancedMemoryBudgetSet) Parent() core.CommandStatic {
+	return AdvancedMemoryBudget
+}
+
+func (advancedMemoryBudgetSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedMemoryBudgetSet) Init() error {
+	return nil
+}
+
+func (c advancedMemoryBudgetSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedMemoryBudgetSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	budget, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(fmt.Sprintf("**%d**", budget), urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedMemoryBudgetSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	budget, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(strconv.Itoa(budget), urr), urr, nil
+}
+
+func (advancedMemoryBudgetSet) fmt(budget string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Updated the token budget to " + budget + "."
+	case UrrBudgetTooLow:
+		return fmt.Sprintf("The token budget must be at least %d.", MinBudget)
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedMemoryBudgetSet) core(m *core.EventMessage) (int, core.Urr, error) {
+	budget, err := strconv.Atoi(m.Command.Args[0])
+	if err != nil {
+		return 0, UrrNotInt, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, nil, err
+	}
+	urr, err := BudgetSet(here, budget)
+	return budget, urr, err
+}
+
+////////////
+//        //
+// filter //
+//        //
+////////////
+
+var AdvancedFilter = advancedFilter{}
+
+type advancedFilter struct{}
+
+func (c advancedFilter) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilter) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilter) Names() []string {
+	return []string{
+		"filter",
+	}
+}
+
+func (advancedFilter) Description() string {
+	return "Control what God is allowed to respond to and say."
+}
+
+func (c advancedFilter) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedFilter) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilter) Examples() []string {
+	return nil
+}
+
+func (advancedFilter) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedFilter) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedFilterTerms,
+		AdvancedFilterModeration,
+		AdvancedFilterFallback,
+		AdvancedFilterLog,
+	}
+}
+
+func (advancedFilter) Init() error {
+	return nil
+}
+
+func (advancedFilter) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+//////////////////
+//              //
+// filter terms //
+//              //
+//////////////////
+
+var AdvancedFilterTerms = advancedFilterTerms{}
+
+type advancedFilterTerms struct{}
+
+func (c advancedFilterTerms) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterTerms) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterTerms) Names() []string {
+	return []string{
+		"terms",
+		"term",
+		"banned",
+	}
+}
+
+func (advancedFilterTerms) Description() string {
+	return "Control the banned terms."
+}
+
+func (c advancedFilterTerms) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedFilterTerms) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterTerms) Examples() []string {
+	return nil
+}
+
+func (advancedFilterTerms) Parent() core.CommandStatic {
+	return AdvancedFilter
+}
+
+func (advancedFilterTerms) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedFilterTermsAdd,
+		AdvancedFilterTermsDelete,
+		AdvancedFilterTermsList,
+	}
+}
+
+func (advancedFilterTerms) Init() error {
+	return nil
+}
+
+func (advancedFilterTerms) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+//////////////////////
+//                  //
+// filter terms add //
+//                  //
+//////////////////////
+
+var AdvancedFilterTermsAdd = advancedFilterTermsAdd{}
+
+type advancedFilterTermsAdd struct{}
+
+func (c advancedFilterTermsAdd) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterTermsAdd) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterTermsAdd) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedFilterTermsAdd) Description() string {
+	return "Ban a term, God will neither respond to nor say anything containing it."
+}
+
+func (advancedFilterTermsAdd) UsageArgs() string {
+	return "<term...>"
+}
+
+func (c advancedFilterTermsAdd) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterTermsAdd) Examples() []string {
+	return nil
+}
+
+func (advancedFilterTermsAdd) Parent() core.CommandStatic {
+	return AdvancedFilterTerms
+}
+
+func (advancedFilterTermsAdd) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterTermsAdd) Init() error {
+	return nil
+}
+
+func (c advancedFilterTermsAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterTermsAdd) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	term, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+term+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedFilterTermsAdd) text(m *core.EventMessage) (string, core.Urr, error) {
+	term, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(term, urr), urr, nil
+}
+
+func (advancedFilterTermsAdd) fmt(term string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Banned the term " + term + "."
+	case UrrTermExists:
+		return "The term " + term + " is already banned."
+	case UrrTermTooLong:
+		return fmt.Sprintf("The term must be at most %d characters long.", termMax)
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedFilterTermsAdd) core(m *core.EventMessage) (string, core.Urr, error) {
+	term := strings.ToLower(m.RawArgs(0))
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	urr, err := TermAdd(here, term)
+	return term, urr, err
+}
+
+/////////////////////////
+//                     //
+// filter terms delete //
+//                     //
+/////////////////////////
+
+var AdvancedFilterTermsDelete = advancedFilterTermsDelete{}
+
+type advancedFilterTermsDelete struct{}
+
+func (c advancedFilterTermsDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterTermsDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterTermsDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedFilterTermsDelete) Description() string {
+	return "Unban a term."
+}
+
+func (advancedFilterTermsDelete) UsageArgs() string {
+	return "<term...>"
+}
+
+func (c advancedFilterTermsDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterTermsDelete) Examples() []string {
+	return nil
+}
+
+func (advancedFilterTermsDelete) Parent() core.CommandStatic {
+	return AdvancedFilterTerms
+}
+
+func (advancedFilterTermsDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterTermsDelete) Init() error {
+	return nil
+}
+
+func (c advancedFilterTermsDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterTermsDelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	term, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt("**"+term+"**", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedFilterTermsDelete) text(m *core.EventMessage) (string, core.Urr, error) {
+	term, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(term, urr), urr, nil
+}
+
+func (advancedFilterTermsDelete) fmt(term string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Unbanned the term " + term + "."
+	case UrrTermNotFound:
+		return "The term " + term + " isn't banned."
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedFilterTermsDelete) core(m *core.EventMessage) (string, core.Urr, error) {
+	term := strings.ToLower(m.RawArgs(0))
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	urr, err := TermDelete(here, term)
+	return term, urr, err
+}
+
+///////////////////////
+//                   //
+// filter terms list //
+//                   //
+///////////////////////
+
+var AdvancedFilterTermsList = advancedFilterTermsList{}
+
+type advancedFilterTermsList struct{}
+
+func (c advancedFilterTermsList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterTermsList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterTermsList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedFilterTermsList) Description() string {
+	return "List the banned terms."
+}
+
+func (advancedFilterTermsList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedFilterTermsList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterTermsList) Examples() []string {
+	return nil
+}
+
+func (advancedFilterTermsList) Parent() core.CommandStatic {
+	return AdvancedFilterTerms
+}
+
+func (advancedFilterTermsList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterTermsList) Init() error {
+	return nil
+}
+
+func (c advancedFilterTermsList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterTermsList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	terms, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Spoilered, so that the terms aren't shown to everyone by default
+	for i, t := range terms {
+		terms[i] = "||" + t + "||"
+	}
+	embed := &dg.MessageEmbed{
+		Title:       "Banned Terms",
+		Description: c.fmt(terms, "\n", urr),
+	}
+	return embed, urr, nil
+}
+
+func (c advancedFilterTermsList) text(m *core.EventMessage) (string, core.Urr, error) {
+	terms, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(terms, ", ", urr), urr, nil
+}
+
+func (advancedFilterTermsList) fmt(terms []string, sep string, urr core.Urr) string {
+	switch urr {
+	case nil:
+		return strings.Join(terms, sep)
+	default:
+		return urr.Error()
+	}
+}
+
+func (advancedFilterTermsList) core(m *core.EventMessage) ([]string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return TermsList(here)
+}
+
+///////////////////////
+//                   //
+// filter moderation //
+//                   //
+///////////////////////
+
+var AdvancedFilterModeration = advancedFilterModeration{}
+
+type advancedFilterModeration struct{}
+
+func (c advancedFilterModeration) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterModeration) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterModeration) Names() []string {
+	return []string{
+		"moderation",
+		"api",
+	}
+}
+
+func (advancedFilterModeration) Description() string {
+	return "Control whether the backend's moderation is also used."
+}
+
+func (c advancedFilterModeration) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedFilterModeration) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterModeration) Examples() []string {
+	return nil
+}
+
+func (advancedFilterModeration) Parent() core.CommandStatic {
+	return AdvancedFilter
+}
+
+func (advancedFilterModeration) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedFilterModerationShow,
+		AdvancedFilterModerationOn,
+		AdvancedFilterModerationOff,
+	}
+}
+
+func (advancedFilterModeration) Init() error {
+	return nil
+}
+
+func (advancedFilterModeration) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+////////////////////////////
+//                        //
+// filter moderation show //
+//                        //
+////////////////////////////
+
+var AdvancedFilterModerationShow = advancedFilterModerationShow{}
+
+type advancedFilterModerationShow struct{}
+
+func (c advancedFilterModerationShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterModerationShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterModerationShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedFilterModerationShow) Description() string {
+	return "Show if the backend's moderation is used."
+}
+
+func (advancedFilterModerationShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedFilterModerationShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterModerationShow) Examples() []string {
+	return nil
+}
+
+func (advancedFilterModerationShow) Parent() core.CommandStatic {
+	return AdvancedFilterModeration
+}
+
+func (advancedFilterModerationShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterModerationShow) Init() error {
+	return nil
+}
+
+func (c advancedFilterModerationShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterModerationShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	on, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(on),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedFilterModerationShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	on, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(on), nil, nil
+}
+
+func (advancedFilterModerationShow) fmt(on bool) string {
+	if on {
+		return "Moderation is on."
+	}
+	return "Moderation is off, only the banned terms are checked."
+}
+
+func (advancedFilterModerationShow) core(m *core.EventMessage) (bool, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return false, err
+	}
+	return ModerationGet(here)
+}
+
+//////////////////////////
+//                      //
+// filter moderation on //
+//                      //
+//////////////////////////
+
+var AdvancedFilterModerationOn = advancedFilterModerationOn{}
+
+type advancedFilterModerationOn struct{}
+
+func (c advancedFilterModerationOn) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterModerationOn) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterModerationOn) Names() []string {
+	return core.AliasesOn
+}
+
+func (advancedFilterModerationOn) Description() string {
+	return "Start using the backend's moderation."
+}
+
+func (advancedFilterModerationOn) UsageArgs() string {
+	return ""
+}
+
+func (c advancedFilterModerationOn) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterModerationOn) Examples() []string {
+	return nil
+}
+
+func (advancedFilterModerationOn) Parent() core.CommandStatic {
+	return AdvancedFilterModeration
+}
+
+func (advancedFilterModerationOn) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterModerationOn) Init() error {
+	return nil
+}
+
+func (c advancedFilterModerationOn) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterModerationOn) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedFilterModerationOn) text(m *core.EventMessage) (string, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(), nil, nil
+}
+
+func (advancedFilterModerationOn) fmt() string {
+	return "Moderation has been turned on."
+}
+
+func (advancedFilterModerationOn) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return ModerationSet(here, true)
+}
+
+///////////////////////////
+//                       //
+// filter moderation off //
+//                       //
+///////////////////////////
+
+var AdvancedFilterModerationOff = advancedFilterModerationOff{}
+
+type advancedFilterModerationOff struct{}
+
+func (c advancedFilterModerationOff) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterModerationOff) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterModerationOff) Names() []string {
+	return core.AliasesOff
+}
+
+func (advancedFilterModerationOff) Description() string {
+	return "Stop using the backend's moderation."
+}
+
+func (advancedFilterModerationOff) UsageArgs() string {
+	return ""
+}
+
+func (c advancedFilterModerationOff) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterModerationOff) Examples() []string {
+	return nil
+}
+
+func (advancedFilterModerationOff) Parent() core.CommandStatic {
+	return AdvancedFilterModeration
+}
+
+func (advancedFilterModerationOff) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFilterModerationOff) Init() error {
+	return nil
+}
+
+func (c advancedFilterModerationOff) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFilterModerationOff) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedFilterModerationOff) text(m *core.EventMessage) (string, core.Urr, error) {
+	err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(), nil, nil
+}
+
+func (advancedFilterModerationOff) fmt() string {
+	return "Moderation has been turned off."
+}
+
+func (advancedFilterModerationOff) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return ModerationSet(here, false)
+}
+
+/////////////////////
+//                 //
+// filter fallback //
+//                 //
+/////////////////////
+
+var AdvancedFilterFallback = advancedFilterFallback{}
+
+type advancedFilterFallback struct{}
+
+func (c advancedFilterFallback) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFilterFallback) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFilterFallback) Names() []string {
+	return []string{
+		"fallback",
+	}
+}
+
+func (advancedFilterFallback) Description() string {
+	return "Control the reply that is sent instead of anything that gets blocked."
+}
+
+func (c advancedFilterFallback) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedFilterFallback) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFilterFallback) Examples() []string {
+	return nil
+}
+
+func (advancedFilterFallback) Parent() core.CommandStatic {
+	return AdvancedFilter
+}
+
+func (advancedFilterFallback) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedFilterFallbackShow,
+		AdvancedFilterFallbackSet,
+		AdvancedFilterFallbackReset,
+	}
+}
+
+func (advancedFilterFallback) Init() error {
+	return nil
+}
+
+func (advancedFilterFallback) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
 }
 
-//////////////////////
-//                  //
-// personality edit //
-//                  //
-//////////////////////
+//////////////////////////
+//                      //
+// filter fallback show //
+//                      //
+//////////////////////////
 
-var AdvancedPersonalityEdit = advancedPersonalityEdit{}
+var AdvancedFilterFallbackShow = advancedFilterFallbackShow{}
 
-type advancedPersonalityEdit struct{}
+type advancedFilterFallbackShow struct{}
 
-func (c advancedPersonalityEdit) Type() core.CommandType {
+func (c advancedFilterFallbackShow) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityEdit) Permitted(m *core.EventMessage) bool {
+func (c advancedFilterFallbackShow) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityEdit) Names() []string {
-	return core.AliasesEdit
+func (advancedFilterFallbackShow) Names() []string {
+	return core.AliasesShow
 }
 
-func (advancedPersonalityEdit) Description() string {
-	return "Edit one of God's personalities."
+func (advancedFilterFallbackShow) Description() string {
+	return "Show the fallback reply."
 }
 
-func (advancedPersonalityEdit) UsageArgs() string {
-	return "<personality> <instructions...>"
+func (advancedFilterFallbackShow) UsageArgs() string {
+	return ""
 }
 
-func (c advancedPersonalityEdit) Category() core.CommandCategory {
+func (c advancedFilterFallbackShow) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityEdit) Examples() []string {
+func (advancedFilterFallbackShow) Examples() []string {
 	return nil
 }
 
-func (advancedPersonalityEdit) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedFilterFallbackShow) Parent() core.CommandStatic {
+	return AdvancedFilterFallback
 }
 
-func (advancedPersonalityEdit) Children() core.CommandsStatic {
+func (advancedFilterFallbackShow) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityEdit) Init() error {
+func (advancedFilterFallbackShow) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityEdit) Run(m *core.EventMessage) (any, core.Urr, error) {
-	if len(m.Command.Args) < 2 {
-		return m.Usage(), core.UrrMissingArgs, nil
-	}
-
+func (c advancedFilterFallbackShow) Run(m *core.EventMessage) (any, core.Urr, error) {
 	switch m.Frontend.Type() {
 	case discord.Frontend.Type():
 		return c.discord(m)
@@ -2106,120 +5725,90 @@ func (c advancedPersonalityEdit) Run(m *core.EventMessage) (any, core.Urr, error
 	}
 }
 
-func (c advancedPersonalityEdit) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	name, prompt, old, urr, err := c.core(m)
+func (c advancedFilterFallbackShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	fallback, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	if urr != nil {
-		return &dg.MessageEmbed{
-			Description: c.fmt("**"+name+"**", urr),
-		}, urr, nil
-	}
 	embed := &dg.MessageEmbed{
-		Title: c.fmt(name, urr),
-		Fields: []*dg.MessageEmbedField{
-			{
-				Name:   "Personality",
-				Value:  name,
-				Inline: false,
-			},
-			{
-				Name:   "New Instructions",
-				Value:  prompt,
-				Inline: false,
-			},
-			{
-				Name:   "Old Instructions",
-				Value:  old,
-				Inline: false,
-			},
-		},
+		Description: c.fmt("**" + fallback + "**"),
 	}
 	return embed, nil, nil
 }
 
-func (c advancedPersonalityEdit) text(m *core.EventMessage) (string, core.Urr, error) {
-	name, _, _, urr, err := c.core(m)
+func (c advancedFilterFallbackShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	fallback, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	return c.fmt("'"+name+"'", urr), urr, nil
+	return c.fmt(fallback), nil, nil
 }
 
-func (advancedPersonalityEdit) fmt(pName string, urr core.Urr) string {
-	switch urr {
-	case nil:
-		return "Edited personality."
-	case UrrGlobalPersonality:
-		return pName + " is a global personality, can't edit."
-	default:
-		return urr.Error()
-	}
+func (advancedFilterFallbackShow) fmt(fallback string) string {
+	return "The fallback reply is: " + fallback
 }
 
-func (advancedPersonalityEdit) core(m *core.EventMessage) (string, string, string, core.Urr, error) {
+func (advancedFilterFallbackShow) core(m *core.EventMessage) (string, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return "", "", "", nil, err
+		return "", err
 	}
-	name := m.Command.Args[0]
-	prompt := m.RawArgs(1)
-	old, urr, err := PersonalityEdit(here, name, prompt)
-	return name, prompt, old, urr, err
+	return FallbackGet(here)
 }
 
-////////////////////////
-//                    //
-// personality delete //
-//                    //
-////////////////////////
+/////////////////////////
+//                     //
+// filter fallback set //
+//                     //
+/////////////////////////
 
-var AdvancedPersonalityDelete = advancedPersonalityDelete{}
+var AdvancedFilterFallbackSet = advancedFilterFallbackSet{}
 
-type advancedPersonalityDelete struct{}
+type advancedFilterFallbackSet struct{}
 
-func (c advancedPersonalityDelete) Type() core.CommandType {
+func (c advancedFilterFallbackSet) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityDelete) Permitted(m *core.EventMessage) bool {
+func (c advancedFilterFallbackSet) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityDelete) Names() []string {
-	return core.AliasesDelete
+func (advancedFilterFallbackSet) Names() []string {
+	return core.AliasesSet
 }
 
-func (advancedPersonalityDelete) Description() string {
-	return "Delete one of God's personalities."
+func (advancedFilterFallbackSet) Description() string {
+	return "Set the fallback reply."
 }
 
-func (advancedPersonalityDelete) UsageArgs() string {
-	return "<personality>"
+func (advancedFilterFallbackSet) UsageArgs() string {
+	return "<reply...>"
 }
 
-func (c advancedPersonalityDelete) Category() core.CommandCategory {
+func (c advancedFilterFallbackSet) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityDelete) Examples() []string {
-	return nil
+func (advancedFilterFallbackSet) Examples() []string {
+	return []string{
+		"Let's talk about something else.",
+	}
 }
 
-func (advancedPersonalityDelete) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedFilterFallbackSet) Parent() core.CommandStatic {
+	return AdvancedFilterFallback
 }
 
-func (advancedPersonalityDelete) Children() core.CommandsStatic {
+func (advancedFilterFallbackSet) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityDelete) Init() error {
+func (advancedFilterFallbackSet) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (c advancedFilterFallbackSet) Run(m *core.EventMessage) (any, core.Urr, error) {
 	if len(m.Command.Args) < 1 {
 		return m.Usage(), core.UrrMissingArgs, nil
 	}
@@ -2232,99 +5821,92 @@ func (c advancedPersonalityDelete) Run(m *core.EventMessage) (any, core.Urr, err
 	}
 }
 
-func (c advancedPersonalityDelete) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	urr, err := c.core(m)
+func (c advancedFilterFallbackSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	fallback, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
 	embed := &dg.MessageEmbed{
-		Description: c.fmt(urr),
+		Description: c.fmt("**" + fallback + "**"),
 	}
-	return embed, urr, nil
+	return embed, nil, nil
 }
 
-func (c advancedPersonalityDelete) text(m *core.EventMessage) (string, core.Urr, error) {
-	urr, err := c.core(m)
+func (c advancedFilterFallbackSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	fallback, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	return c.fmt(urr), urr, nil
+	return c.fmt(fallback), nil, nil
 }
 
-func (advancedPersonalityDelete) fmt(urr core.Urr) string {
-	switch urr {
-	case nil:
-		return "Deleted personality."
-	default:
-		return urr.Error()
-	}
+func (advancedFilterFallbackSet) fmt(fallback string) string {
+	return "Updated the fallback reply to: " + fallback
 }
 
-func (advancedPersonalityDelete) core(m *core.EventMessage) (core.Urr, error) {
+func (advancedFilterFallbackSet) core(m *core.EventMessage) (string, error) {
+	fallback := m.RawArgs(0)
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return PersonalityDelete(here, m.Command.Args[0])
+	return fallback, FallbackSet(here, fallback)
 }
 
-//////////////////////
-//                  //
-// personality info //
-//                  //
-//////////////////////
+///////////////////////////
+//                       //
+// filter fallback reset //
+//                       //
+///////////////////////////
 
-var AdvancedPersonalityInfo = advancedPersonalityInfo{}
+var AdvancedFilterFallbackReset = advancedFilterFallbackReset{}
 
-type advancedPersonalityInfo struct{}
+type advancedFilterFallbackReset struct{}
 
-func (c advancedPersonalityInfo) Type() core.CommandType {
+func (c advancedFilterFallbackReset) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityInfo) Permitted(m *core.EventMessage) bool {
+func (c advancedFilterFallbackReset) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityInfo) Names() []string {
+func (advancedFilterFallbackReset) Names() []string {
 	return []string{
-		"info",
+		"reset",
+		"default",
 	}
 }
 
-func (advancedPersonalityInfo) Description() string {
-	return "View information on the specified personality."
+func (advancedFilterFallbackReset) Description() string {
+	return "Go back to using the default fallback reply."
 }
 
-func (advancedPersonalityInfo) UsageArgs() string {
-	return "<personality>"
+func (advancedFilterFallbackReset) UsageArgs() string {
+	return ""
 }
 
-func (c advancedPersonalityInfo) Category() core.CommandCategory {
+func (c advancedFilterFallbackReset) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityInfo) Examples() []string {
+func (advancedFilterFallbackReset) Examples() []string {
 	return nil
 }
 
-func (advancedPersonalityInfo) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedFilterFallbackReset) Parent() core.CommandStatic {
+	return AdvancedFilterFallback
 }
 
-func (advancedPersonalityInfo) Children() core.CommandsStatic {
+func (advancedFilterFallbackReset) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityInfo) Init() error {
+func (advancedFilterFallbackReset) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityInfo) Run(m *core.EventMessage) (any, core.Urr, error) {
-	if len(m.Command.Args) < 1 {
-		return m.Usage(), core.UrrMissingArgs, nil
-	}
-
+func (c advancedFilterFallbackReset) Run(m *core.EventMessage) (any, core.Urr, error) {
 	switch m.Frontend.Type() {
 	case discord.Frontend.Type():
 		return c.discord(m)
@@ -2333,104 +5915,92 @@ func (c advancedPersonalityInfo) Run(m *core.EventMessage) (any, core.Urr, error
 	}
 }
 
-func (c advancedPersonalityInfo) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	personality, urr, err := c.core(m)
+func (c advancedFilterFallbackReset) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
-	if urr != nil {
-		embed := &dg.MessageEmbed{
-			Description: urr.Error(),
-		}
-		return embed, urr, nil
-	}
 	embed := &dg.MessageEmbed{
-		Fields: []*dg.MessageEmbedField{
-			{
-				Name:   "Personality",
-				Value:  personality.Name,
-				Inline: false,
-			},
-			{
-				Name:   "Instructions",
-				Value:  personality.Prompt,
-				Inline: false,
-			},
-		},
+		Description: c.fmt(),
 	}
 	return embed, nil, nil
 }
 
-func (c advancedPersonalityInfo) text(m *core.EventMessage) (string, core.Urr, error) {
-	personality, urr, err := c.core(m)
+func (c advancedFilterFallbackReset) text(m *core.EventMessage) (string, core.Urr, error) {
+	err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	if urr != nil {
-		return urr.Error(), urr, nil
-	}
-	return personality.Name + ": " + personality.Prompt, nil, nil
+	return c.fmt(), nil, nil
 }
 
-func (advancedPersonalityInfo) core(m *core.EventMessage) (Personality, core.Urr, error) {
+func (advancedFilterFallbackReset) fmt() string {
+	return "The fallback reply has been reset to the default."
+}
+
+func (advancedFilterFallbackReset) core(m *core.EventMessage) error {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return Personality{}, nil, err
+		return err
 	}
-	return PersonalityGet(here, m.Command.Args[0])
+	return FallbackReset(here)
 }
 
-//////////////////////
-//                  //
-// personality list //
-//                  //
-//////////////////////
+////////////////
+//            //
+// filter log //
+//            //
+////////////////
 
-var AdvancedPersonalityList = advancedPersonalityList{}
+var AdvancedFilterLog = advancedFilterLog{}
 
-type advancedPersonalityList struct{}
+type advancedFilterLog struct{}
 
-func (c advancedPersonalityList) Type() core.CommandType {
+func (c advancedFilterLog) Type() core.CommandType {
 	return c.Parent().Type()
 }
 
-func (c advancedPersonalityList) Permitted(m *core.EventMessage) bool {
+func (c advancedFilterLog) Permitted(m *core.EventMessage) bool {
 	return c.Parent().Permitted(m)
 }
 
-func (advancedPersonalityList) Names() []string {
-	return core.AliasesList
+func (advancedFilterLog) Names() []string {
+	return []string{
+		"log",
+		"blocked",
+		"review",
+	}
 }
 
-func (advancedPersonalityList) Description() string {
-	return "List all the available personalities."
+func (advancedFilterLog) Description() string {
+	return "Review the latest messages that were blocked."
 }
 
-func (advancedPersonalityList) UsageArgs() string {
+func (advancedFilterLog) UsageArgs() string {
 	return ""
 }
 
-func (c advancedPersonalityList) Category() core.CommandCategory {
+func (c advancedFilterLog) Category() core.CommandCategory {
 	return c.Parent().Category()
 }
 
-func (advancedPersonalityList) Examples() []string {
+func (advancedFilterLog) Examples() []string {
 	return nil
 }
 
-func (advancedPersonalityList) Parent() core.CommandStatic {
-	return AdvancedPersonality
+func (advancedFilterLog) Parent() core.CommandStatic {
+	return AdvancedFilter
 }
 
-func (advancedPersonalityList) Children() core.CommandsStatic {
+func (advancedFilterLog) Children() core.CommandsStatic {
 	return nil
 }
 
-func (advancedPersonalityList) Init() error {
+func (advancedFilterLog) Init() error {
 	return nil
 }
 
-func (c advancedPersonalityList) Run(m *core.EventMessage) (any, core.Urr, error) {
+func (c advancedFilterLog) Run(m *core.EventMessage) (any, core.Urr, error) {
 	switch m.Frontend.Type() {
 	case discord.Frontend.Type():
 		return c.discord(m)
@@ -2439,50 +6009,75 @@ func (c advancedPersonalityList) Run(m *core.EventMessage) (any, core.Urr, error
 	}
 }
 
-func (c advancedPersonalityList) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	active, personalities, err := c.core(m)
+func (c advancedFilterLog) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	blocked, urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
+	if urr != nil {
+		return &dg.MessageEmbed{Description: urr.Error()}, urr, nil
+	}
+
 	var b strings.Builder
-	for _, p := range personalities {
-		if p.Name == active.Name {
-			p.Name = "**" + p.Name + "**"
+	for _, bl := range blocked {
+		who, err := c.who(m, bl.Person)
+		if err != nil {
+			return nil, nil, err
 		}
-		b.WriteString("- ")
-		b.WriteString(p.Name)
-		b.WriteString("\n")
+		// Spoilered, since it's what got blocked in the first place
+		fmt.Fprintf(&b, "**%s** by %s <t:%d:R>, %s\n||%s||\n\n",
+			bl.Kind, who, bl.When.Unix(), bl.Reason, c.shorten(bl.Text, 200))
 	}
+
 	embed := &dg.MessageEmbed{
+		Title:       "Blocked Messages",
 		Description: b.String(),
 	}
 	return embed, nil, nil
 }
 
-func (c advancedPersonalityList) text(m *core.EventMessage) (string, core.Urr, error) {
-	_, personalities, err := c.core(m)
+func (c advancedFilterLog) text(m *core.EventMessage) (string, core.Urr, error) {
+	blocked, urr, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
-	var names []string
-	for _, p := range personalities {
-		names = append(names, p.Name)
+	if urr != nil {
+		return urr.Error(), urr, nil
 	}
-	return "Personalities: " + strings.Join(names, ", "), nil, nil
+
+	var entries []string
+	for _, bl := range blocked {
+		who, err := c.who(m, bl.Person)
+		if err != nil {
+			return "", nil, err
+		}
+		// The text itself isn't shown, since everyone in chat would see it
+		entries = append(entries, fmt.Sprintf("%s by %s (%s)", bl.Kind, who, bl.Reason))
+	}
+	return strings.Join(entries, " | "), nil, nil
 }
 
-func (advancedPersonalityList) core(m *core.EventMessage) (Personality, []Personality, error) {
-	here, err := m.Here.ScopeLogical()
-	if err != nil {
-		return Personality{}, nil, err
+// Returns who the blocked message was from or for.
+func (advancedFilterLog) who(m *core.EventMessage, person int64) (string, error) {
+	if person == -1 {
+		return "auto-reply", nil
 	}
-	ps, err := PersonalitiesList(here)
-	if err != nil {
-		return Personality{}, nil, err
+	return personName(m, person)
+}
+
+// Cuts the text off after n characters.
+func (advancedFilterLog) shorten(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
 	}
-	active, _, err := PersonalityActive(here)
+	return string(runes[:n]) + "…"
+}
+
+func (advancedFilterLog) core(m *core.EventMessage) ([]Blocked, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return Personality{}, nil, err
+		return nil, nil, err
 	}
-	return active, ps, nil
+	return BlockedList(here)
 }
@@ -0,0 +1,384 @@
+package god
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+var (
+	UrrUnknownSetting = core.UrrNew("unknown setting")
+	UrrInvalidURL     = core.UrrNew("invalid url")
+	UrrInvalidTimeout = core.UrrNew("invalid timeout")
+)
+
+// The roles a message in a conversation can have.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single message in a conversation. The JSON field names are the
+// same as OpenAI's, so that saved conversations can be passed along as is.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is what gets passed to a Backend in order to generate a reply.
+type Request struct {
+	Model     string
+	MaxTokens int
+	Messages  []Message
+}
+
+// ErrTruncated is returned along with the reply when it was cut off because it
+// reached the maximum number of tokens.
+var ErrTruncated = errors.New("reply was truncated")
+
+// Backend generates replies, it is what Talk uses behind the scenes. The
+// context is canceled once the place's timeout is reached.
+type Backend interface {
+	Complete(ctx context.Context, cfg Config, req Request) (string, error)
+}
+
+// Streamer is implemented by backends that can send the reply as it's being
+// generated, delta gets called with each new piece of text.
+type Streamer interface {
+	Stream(ctx context.Context, cfg Config, req Request, delta func(string)) (string, error)
+}
+
+// Moderator is implemented by backends that can check whether text is
+// harmful. Returns why the text was flagged, or an empty string if it wasn't.
+type Moderator interface {
+	Moderate(ctx context.Context, cfg Config, text string) (string, error)
+}
+
+var (
+	backendLock sync.RWMutex
+	backend     Backend = OpenAI{}
+)
+
+// SetBackend changes the backend that is used to generate replies and returns
+// the previous one. Mainly useful for swapping in a Fake during tests.
+func SetBackend(b Backend) Backend {
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	prev := backend
+	backend = b
+	return prev
+}
+
+func getBackend() Backend {
+	backendLock.RLock()
+	defer backendLock.RUnlock()
+	return backend
+}
+
+////////////
+//        //
+// config //
+//        //
+////////////
+
+// The per-place backend settings.
+const (
+	SettingModel   = "model"
+	SettingURL     = "url"
+	SettingTimeout = "timeout"
+)
+
+var Settings = []string{
+	SettingModel,
+	SettingURL,
+	SettingTimeout,
+}
+
+// Config holds the settings used to reach the backend in a place. Anything that
+// hasn't been set for the place falls back to the global default.
+type Config struct {
+	Model   string
+	BaseURL string
+	Timeout time.Duration
+}
+
+// ConfigGet returns the backend settings for the place.
+func ConfigGet(place int64) (Config, error) {
+	cfg := Config{
+		Model:   core.GodModel,
+		BaseURL: core.GodBaseURL,
+		Timeout: core.GodTimeout,
+	}
+
+	model, urr, err := core.DB.PlaceGet("cmd_god_model", place).StrNil()
+	if err != nil {
+		return Config{}, err
+	}
+	if urr == nil {
+		cfg.Model = model
+	}
+
+	baseURL, urr, err := core.DB.PlaceGet("cmd_god_base_url", place).StrNil()
+	if err != nil {
+		return Config{}, err
+	}
+	if urr == nil {
+		cfg.BaseURL = baseURL
+	}
+
+	timeout, err := core.DB.PlaceGet("cmd_god_timeout", place).Duration()
+	if err != nil {
+		return Config{}, err
+	}
+	if timeout != 0 {
+		cfg.Timeout = timeout
+	}
+
+	return cfg, nil
+}
+
+// ConfigSet changes one of the place's backend settings, which must be one of
+// Settings.
+func ConfigSet(place int64, setting, value string) (core.Urr, error) {
+	switch setting {
+	case SettingModel:
+		return nil, core.DB.PlaceSet("cmd_god_model", place, value)
+	case SettingURL:
+		if !core.IsValidURL(value) {
+			return UrrInvalidURL, nil
+		}
+		return nil, core.DB.PlaceSet("cmd_god_base_url", place, value)
+	case SettingTimeout:
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			return UrrInvalidTimeout, nil
+		}
+		return nil, core.DB.PlaceSet("cmd_god_timeout", place, int(d.Seconds()))
+	default:
+		return UrrUnknownSetting, nil
+	}
+}
+
+// ConfigReset makes the place use the global default for the setting again.
+func ConfigReset(place int64, setting string) (core.Urr, error) {
+	switch setting {
+	case SettingModel:
+		return nil, core.DB.PlaceSet("cmd_god_model", place, nil)
+	case SettingURL:
+		return nil, core.DB.PlaceSet("cmd_god_base_url", place, nil)
+	case SettingTimeout:
+		return nil, core.DB.PlaceSet("cmd_god_timeout", place, 0)
+	default:
+		return UrrUnknownSetting, nil
+	}
+}
+
+////////////
+//        //
+// openai //
+//        //
+////////////
+
+// OpenAI is the backend for OpenAI's API, it also works with any server that
+// implements the same API, e.g. llama.cpp, vLLM or Ollama.
+type OpenAI struct{}
+
+func (OpenAI) client(cfg Config) *openai.Client {
+	oc := openai.DefaultConfig(core.OpenAIKey)
+	oc.BaseURL = cfg.BaseURL
+	return openai.NewClientWithConfig(oc)
+}
+
+func (OpenAI) request(req Request) openai.ChatCompletionRequest {
+	msgs := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		msgs[i] = openai.ChatCompletionMessage{
+			Role:    m.Role,
+			Content: m.Content,
+		}
+	}
+	return openai.ChatCompletionRequest{
+		Model:     req.Model,
+		MaxTokens: req.MaxTokens,
+		Messages:  msgs,
+	}
+}
+
+func (o OpenAI) Complete(ctx context.Context, cfg Config, req Request) (string, error) {
+	resp, err := o.client(cfg).CreateChatCompletion(ctx, o.request(req))
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", errors.New("response was empty")
+	}
+	choice := resp.Choices[0]
+	if choice.FinishReason == openai.FinishReasonLength {
+		return choice.Message.Content, ErrTruncated
+	}
+	return choice.Message.Content, nil
+}
+
+func (o OpenAI) Stream(ctx context.Context, cfg Config, req Request, delta func(string)) (string, error) {
+	stream, err := o.client(cfg).CreateChatCompletionStream(ctx, o.request(req))
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var reply strings.Builder
+	var truncated bool
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			continue
+		}
+		choice := resp.Choices[0]
+		reply.WriteString(choice.Delta.Content)
+		delta(choice.Delta.Content)
+		truncated = choice.FinishReason == openai.FinishReasonLength
+	}
+	if truncated {
+		return reply.String(), ErrTruncated
+	}
+	return reply.String(), nil
+}
+
+// Returns whether the error means that the server doesn't have the endpoint
+// that was requested, which is common for servers that are only partially
+// compatible with OpenAI's API.
+func endpointMissing(err error) bool {
+	var status int
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+	return status == http.StatusNotFound ||
+		status == http.StatusMethodNotAllowed ||
+		status == http.StatusNotImplemented
+}
+
+// Moderate uses OpenAI's moderation endpoint, which means that the place's
+// server must also implement it, if it doesn't ErrModerationUnsupported is
+// returned.
+func (o OpenAI) Moderate(ctx context.Context, cfg Config, text string) (string, error) {
+	resp, err := o.client(cfg).Moderations(ctx, openai.ModerationRequest{
+		Input: text,
+	})
+	if endpointMissing(err) {
+		return "", ErrModerationUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Results) == 0 || !resp.Results[0].Flagged {
+		return "", nil
+	}
+
+	// The categories are only available as struct fields, their JSON names
+	// are the ones that OpenAI uses
+	b, err := json.Marshal(resp.Results[0].Categories)
+	if err != nil {
+		return "", err
+	}
+	var categories map[string]bool
+	if err := json.Unmarshal(b, &categories); err != nil {
+		return "", err
+	}
+	var flagged []string
+	for c, ok := range categories {
+		if ok {
+			flagged = append(flagged, c)
+		}
+	}
+	if len(flagged) == 0 {
+		return "flagged by moderation", nil
+	}
+	slices.Sort(flagged)
+	return "flagged for " + strings.Join(flagged, ", "), nil
+}
+
+//////////
+//      //
+// fake //
+//      //
+//////////
+
+// Fake is a backend that doesn't make any network requests. It replies using
+// Reply, or by echoing the last message if Reply is nil, and keeps track of
+// every request it was given. When streaming, the reply is sent one word at a
+// time. Text is only flagged by moderation if Flag is set and returns a
+// reason.
+type Fake struct {
+	Reply func(req Request) (string, error)
+	Flag  func(text string) string
+
+	lock     sync.Mutex
+	requests []Request
+}
+
+func (f *Fake) Complete(ctx context.Context, _ Config, req Request) (string, error) {
+	f.lock.Lock()
+	f.requests = append(f.requests, req)
+	f.lock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.Reply != nil {
+		return f.Reply(req)
+	}
+	if len(req.Messages) == 0 {
+		return "", errors.New("no messages given")
+	}
+	return req.Messages[len(req.Messages)-1].Content, nil
+}
+
+func (f *Fake) Stream(ctx context.Context, cfg Config, req Request, delta func(string)) (string, error) {
+	reply, err := f.Complete(ctx, cfg, req)
+	if err != nil && !errors.Is(err, ErrTruncated) {
+		return "", err
+	}
+	for _, word := range strings.SplitAfter(reply, " ") {
+		delta(word)
+	}
+	return reply, err
+}
+
+func (f *Fake) Moderate(ctx context.Context, _ Config, text string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if f.Flag == nil {
+		return "", nil
+	}
+	return f.Flag(text), nil
+}
+
+// Requests returns the requests the backend has received, oldest first.
+func (f *Fake) Requests() []Request {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]Request(nil), f.requests...)
+}
@@ -0,0 +1,461 @@
+package god
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrInvalidSize     = core.UrrNew("The context size is out of range.")
+	UrrAlreadyExcluded = core.UrrNew("This is already excluded.")
+	UrrNotExcluded     = core.UrrNew("This isn't excluded.")
+	UrrKeywordTooLong  = core.UrrNew("The keyword is too long.")
+	UrrPersonNotFound  = core.UrrNew("Was unable to find that person.")
+	UrrNothingExcluded = core.UrrNew("Nothing has been excluded.")
+)
+
+// The limits of the chat context that is kept for auto-replies.
+const (
+	// The most messages a place can keep.
+	ContextSizeMax = 50
+	// Longer messages get cut off.
+	contextTextMax = 300
+	// If nobody has said anything for this long the context gets dropped,
+	// since it's probably no longer relevant.
+	contextTTL = time.Hour
+	// Keywords are saved as VARCHAR(255).
+	keywordMax = 255
+)
+
+// ChatLine is a message that was sent in a place, kept as context for
+// auto-replies.
+type ChatLine struct {
+	Person int64  `json:"person"`
+	Name   string `json:"name"`
+	Text   string `json:"text"`
+}
+
+func contextKey(place int64) string {
+	return fmt.Sprintf("cmd_god-context-%d", place)
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbContextPersonExclude(place, person int64) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		INSERT INTO cmd_god_context_excluded_people (place, person)
+		VALUES ($1, $2)
+		ON CONFLICT (place, person) DO NOTHING
+	`, place, person)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int64("person", person).
+		Msg("excluded person from context")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbContextPersonInclude(place, person int64) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_god_context_excluded_people
+		WHERE place = $1 AND person = $2
+	`, place, person)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int64("person", person).
+		Msg("included person in context")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbContextPeopleExcluded(place int64) ([]int64, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var people []int64
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(ARRAY_AGG(person ORDER BY person), '{}')
+		FROM cmd_god_context_excluded_people
+		WHERE place = $1
+	`, place).Scan(pq.Array(&people))
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Ints64("people", people).
+		Msg("got people excluded from context")
+
+	return people, err
+}
+
+func dbContextKeywordExclude(place int64, keyword string) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		INSERT INTO cmd_god_context_excluded_keywords (place, keyword)
+		VALUES ($1, $2)
+		ON CONFLICT (place, keyword) DO NOTHING
+	`, place, keyword)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("keyword", keyword).
+		Msg("excluded keyword from context")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbContextKeywordInclude(place int64, keyword string) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_god_context_excluded_keywords
+		WHERE place = $1 AND keyword = $2
+	`, place, keyword)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("keyword", keyword).
+		Msg("included keyword in context")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbContextKeywordsExcluded(place int64) ([]string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var keywords []string
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(ARRAY_AGG(keyword ORDER BY keyword), '{}')
+		FROM cmd_god_context_excluded_keywords
+		WHERE place = $1
+	`, place).Scan(pq.Array(&keywords))
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Strs("keywords", keywords).
+		Msg("got keywords excluded from context")
+
+	return keywords, err
+}
+
+/////////////
+//         //
+// context //
+//         //
+/////////////
+
+// ContextOnGet returns whether recent chat messages are used as context for
+// auto-replies in the place.
+func ContextOnGet(place int64) (bool, error) {
+	return core.DB.PlaceGet("cmd_god_context_on", place).Bool()
+}
+
+// ContextOnSet turns the chat context on or off in the place. Turning it off
+// also drops any messages that have been kept so far.
+func ContextOnSet(place int64, on bool) error {
+	if !on {
+		if err := core.RDB.Del(context.Background(), contextKey(place)).Err(); err != nil {
+			return err
+		}
+	}
+	return core.DB.PlaceSet("cmd_god_context_on", place, on)
+}
+
+// ContextSizeGet returns the maximum number of messages kept as context in the
+// place.
+func ContextSizeGet(place int64) (int, error) {
+	return core.DB.PlaceGet("cmd_god_context_size", place).Int()
+}
+
+// ContextSizeSet sets the maximum number of messages kept as context in the
+// place. Returns UrrInvalidSize if it's not between 1 and ContextSizeMax.
+func ContextSizeSet(place int64, size int) (core.Urr, error) {
+	if size < 1 || size > ContextSizeMax {
+		return UrrInvalidSize, nil
+	}
+	err := core.RDB.LTrim(context.Background(), contextKey(place), int64(-size), -1).Err()
+	if err != nil {
+		return nil, err
+	}
+	return nil, core.DB.PlaceSet("cmd_god_context_size", place, size)
+}
+
+// ContextPersonExclude makes it so that the person's messages are never kept
+// as context in the place. Returns UrrAlreadyExcluded if they already were.
+func ContextPersonExclude(place, person int64) (core.Urr, error) {
+	added, err := dbContextPersonExclude(place, person)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return UrrAlreadyExcluded, nil
+	}
+	return nil, nil
+}
+
+// ContextPersonInclude undoes ContextPersonExclude. Returns UrrNotExcluded if
+// the person wasn't excluded.
+func ContextPersonInclude(place, person int64) (core.Urr, error) {
+	deleted, err := dbContextPersonInclude(place, person)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return UrrNotExcluded, nil
+	}
+	return nil, nil
+}
+
+// ContextPeopleExcluded returns the people whose messages are never kept as
+// context in the place. Returns UrrNothingExcluded if there are none.
+func ContextPeopleExcluded(place int64) ([]int64, core.Urr, error) {
+	people, err := dbContextPeopleExcluded(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(people) == 0 {
+		return nil, UrrNothingExcluded, nil
+	}
+	return people, nil, nil
+}
+
+// ContextKeywordExclude makes it so that messages containing the keyword are
+// never kept as context in the place, case-insensitively. Returns
+// UrrAlreadyExcluded if it already was.
+func ContextKeywordExclude(place int64, keyword string) (core.Urr, error) {
+	keyword = strings.ToLower(keyword)
+	if len(keyword) > keywordMax {
+		return UrrKeywordTooLong, nil
+	}
+	added, err := dbContextKeywordExclude(place, keyword)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return UrrAlreadyExcluded, nil
+	}
+	return nil, nil
+}
+
+// ContextKeywordInclude undoes ContextKeywordExclude. Returns UrrNotExcluded if
+// the keyword wasn't excluded.
+func ContextKeywordInclude(place int64, keyword string) (core.Urr, error) {
+	deleted, err := dbContextKeywordInclude(place, strings.ToLower(keyword))
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return UrrNotExcluded, nil
+	}
+	return nil, nil
+}
+
+// ContextKeywordsExcluded returns the keywords that prevent a message from
+// being kept as context in the place. Returns UrrNothingExcluded if there are
+// none.
+func ContextKeywordsExcluded(place int64) ([]string, core.Urr, error) {
+	keywords, err := dbContextKeywordsExcluded(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(keywords) == 0 {
+		return nil, UrrNothingExcluded, nil
+	}
+	return keywords, nil, nil
+}
+
+// Returns whether the line shouldn't be used as context.
+func contextExcluded(line ChatLine, people []int64, keywords []string) bool {
+	for _, p := range people {
+		if line.Person == p {
+			return true
+		}
+	}
+	text := strings.ToLower(line.Text)
+	for _, k := range keywords {
+		if strings.Contains(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Keeps the message as context, if the place has turned the context on and
+// the message isn't excluded. Commands are skipped, they're not part of the
+// conversation.
+func contextRecord(m *core.EventMessage, place int64) error {
+	on, err := ContextOnGet(place)
+	if err != nil || !on {
+		return err
+	}
+
+	prefixes, _, err := m.Prefixes()
+	if err != nil {
+		return err
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(m.Raw, p.Prefix) {
+			return nil
+		}
+	}
+
+	person, err := m.Author.Scope()
+	if err != nil {
+		return err
+	}
+	name, err := m.Author.DisplayName()
+	if err != nil {
+		return err
+	}
+	text := []rune(strings.TrimSpace(m.Raw))
+	if len(text) == 0 {
+		return nil
+	}
+	if len(text) > contextTextMax {
+		text = append(text[:contextTextMax], '…')
+	}
+	line := ChatLine{
+		Person: person,
+		Name:   name,
+		Text:   string(text),
+	}
+
+	people, err := dbContextPeopleExcluded(place)
+	if err != nil {
+		return err
+	}
+	keywords, err := dbContextKeywordsExcluded(place)
+	if err != nil {
+		return err
+	}
+	if contextExcluded(line, people, keywords) {
+		return nil
+	}
+
+	size, err := ContextSizeGet(place)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	key := contextKey(place)
+	_, err = core.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.RPush(ctx, key, b)
+		pipe.LTrim(ctx, key, int64(-size), -1)
+		pipe.Expire(ctx, key, contextTTL)
+		return nil
+	})
+	return err
+}
+
+// ContextGet returns the messages kept as context in the place, oldest first.
+// Messages that were excluded after being kept are left out.
+func ContextGet(place int64) ([]ChatLine, error) {
+	raw, err := core.RDB.LRange(context.Background(), contextKey(place), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	people, err := dbContextPeopleExcluded(place)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := dbContextKeywordsExcluded(place)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []ChatLine
+	for _, r := range raw {
+		var line ChatLine
+		if err := json.Unmarshal([]byte(r), &line); err != nil {
+			return nil, err
+		}
+		if !contextExcluded(line, people, keywords) {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// Returns the prompt used for an auto-reply to the message. If there is any
+// chat context, the model is given the recent messages instead of just the
+// one being replied to. Messages that contain any of the filter's banned terms
+// are left out, as only the message being replied to goes through the filter.
+func contextPrompt(place int64, msg string, f Filter) (string, error) {
+	on, err := ContextOnGet(place)
+	if err != nil || !on {
+		return msg, err
+	}
+	lines, err := ContextGet(place)
+	if err != nil || len(lines) == 0 {
+		return msg, err
+	}
+
+	var b strings.Builder
+	b.WriteString("These are the latest messages in the chat, oldest first:\n")
+	for _, l := range lines {
+		if f.banned(l.Text) != "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\n", l.Name, l.Text)
+	}
+	b.WriteString("\nJoin in on the conversation with a single message.")
+	return b.String(), nil
+}
@@ -3,18 +3,16 @@ package god
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
-	"fmt"
+	"math"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/kvlach/janitorjeff/core"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
-	openai "github.com/sashabaranov/go-openai"
 )
 
 var (
@@ -29,97 +27,266 @@ var (
 	UrrPromptSame          = core.UrrNew("Provided instructions are exactly the same as the already set ones.")
 )
 
-// Talk returns GPT3.5's response to a user prompt.
-// The system prompt will be the active personality for place.
-// If person equals -1 then the conversation will not be kept track of.
-// If the active personality changes, then the saved dialogue is cleared.
+// Talk returns the backend's response to a user prompt, using the place's
+// backend settings. The system prompt will be the active personality for place.
+// If person equals -1 then the conversation will not be kept track of,
+// otherwise it's remembered for as long as the place's retention allows, see
+// MemoryGet. Both the prompt and the response go through the place's filter,
+// if either gets blocked the filter's fallback is returned instead.
 func Talk(person, place int64, userPrompt string) (string, error) {
+	return TalkStream(person, place, userPrompt, nil)
+}
+
+// TalkStream is the same as Talk, except that delta is called with each new
+// piece of the response as it's being generated. If the backend doesn't
+// support streaming, or if the place's filter is active, delta is called once
+// with the whole response. If the response reaches the maximum number of
+// tokens, the returned response only includes the sentences that were
+// completed.
+func TalkStream(person, place int64, userPrompt string, delta func(string)) (string, error) {
+	return talk(person, place, userPrompt, userPrompt, false, delta)
+}
+
+// The same as TalkStream, except that on the prompt's side only filtered goes
+// through the filter, which is the part of the prompt that was written by
+// someone, e.g. the message that is being auto-replied to. If silent is true,
+// the fallback isn't sent when something gets blocked and UrrBlocked is
+// returned instead.
+func talk(person, place int64, userPrompt, filtered string, silent bool, delta func(string)) (string, error) {
 	slog := log.With().
 		Int64("person", person).
 		Int64("place", place).
 		Logger()
 
-	ctx := context.Background()
-	key := fmt.Sprintf("cmd_god-dialogue-%d-%d", person, place)
-
-	var dialogue []openai.ChatCompletionMessage
-
 	p, max, err := PersonalityActive(place)
 	if err != nil {
 		return "", err
 	}
+	cfg, err := ConfigGet(place)
+	if err != nil {
+		return "", err
+	}
+	f, err := FilterGet(place)
+	if err != nil {
+		return "", err
+	}
+
+	reason, err := f.check(cfg, filtered)
+	if err != nil {
+		return "", err
+	}
+	if reason != "" {
+		return f.block(person, place, KindPrompt, filtered, reason, silent, delta)
+	}
+
+	// The system prompt isn't remembered, so that the conversation carries
+	// over when the active personality changes
+	dialogue := []Message{{
+		Role:    RoleSystem,
+		Content: p.Prompt,
+	}}
 
 	if person != -1 {
-		dialogueBytes, err := core.RDB.Get(ctx, key).Bytes()
-		if err != nil && err != redis.Nil {
+		mem, err := MemoryGet(person, place)
+		if err != nil {
 			return "", err
 		}
-		// Can't unmarshal what's not there
-		if err != redis.Nil {
-			if err := json.Unmarshal(dialogueBytes, &dialogue); err != nil {
-				return "", err
-			}
-			// Personality changed which means we clear the dialogue
-			if dialogue[0].Content != p.Prompt {
-				dialogue = dialogue[:0]
-			}
-		}
-	}
-
-	// If not present in cache, set system prompt using active personality
-	// otherwise just use the cached dialogue
-	if len(dialogue) == 0 {
-		dialogue = append(dialogue, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleSystem,
-			Content: p.Prompt,
-		})
+		dialogue = append(dialogue, mem.Messages()...)
 	}
 
 	slog.Debug().Interface("dialogue", dialogue).Msg("got dialogue")
 
-	dialogue = append(dialogue, openai.ChatCompletionMessage{
-		Role:    openai.ChatMessageRoleUser,
+	dialogue = append(dialogue, Message{
+		Role:    RoleUser,
 		Content: userPrompt,
 	})
 
-	resp, err := openai.NewClient(core.OpenAIKey).CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model:     openai.GPT3Dot5Turbo,
-			MaxTokens: max,
-			Messages:  dialogue,
-		},
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	req := Request{
+		Model:     cfg.Model,
+		MaxTokens: max,
+		Messages:  dialogue,
+	}
+
+	// Whatever has been streamed can't be taken back, so if there's something
+	// to filter the whole response has to be checked before it's sent
+	var reply string
+	s, streamed := getBackend().(Streamer)
+	streamed = streamed && delta != nil && !f.Active()
+	if streamed {
+		reply, err = s.Stream(ctx, cfg, req, delta)
+	} else {
+		reply, err = getBackend().Complete(ctx, cfg, req)
+	}
+	if errors.Is(err, ErrTruncated) {
+		// Better to stop at the last complete sentence than mid-sentence
+		if sentences, _ := core.Sentences(reply); len(sentences) != 0 {
+			reply = strings.TrimSpace(strings.Join(sentences, ""))
+		}
+		err = nil
+	}
 	if err != nil {
 		return "", err
 	}
-	if len(resp.Choices) == 0 {
-		return "", errors.New("response was empty")
-	}
 
-	reply := resp.Choices[0].Message.Content
+	reason, err = f.check(cfg, reply)
+	if err != nil {
+		return "", err
+	}
+	if reason != "" {
+		return f.block(person, place, KindReply, reply, reason, silent, delta)
+	}
+	if !streamed && delta != nil {
+		delta(reply)
+	}
 
 	if person != -1 {
-		dialogue = append(dialogue, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleAssistant,
-			Content: reply,
-		})
-		dialogueBytes, err := json.Marshal(dialogue)
-		if err != nil {
-			return "", err
-		}
-		err = core.RDB.Set(ctx, key, string(dialogueBytes), 5*time.Minute).Err()
-		if err != nil {
+		if err := remember(cfg, person, place, userPrompt, reply); err != nil {
 			return "", err
 		}
-		slog.Debug().
-			Interface("dialogue", dialogue).
-			Msg("saved new dialogue")
 	}
 
 	return reply, nil
 }
 
+// How often a message that is being streamed can be edited, editing it every
+// time a sentence is completed would quickly get rate limited.
+const editInterval = time.Second
+
+// stream sends a response as it's being generated. Text is only sent once a
+// sentence has been completed and is cut so that each message fits within the
+// frontend's length limit. If the frontend supports it, instead of sending a
+// new message for every part, a single message is progressively edited until
+// it's full.
+type stream struct {
+	send   func(msg any, urr core.Urr) (*core.EventMessage, error)
+	edit   bool
+	lenCnt func(string) int
+	lenLim int
+
+	// Every completed sentence.
+	text string
+	// The sentence that is still being generated.
+	pending string
+	// The number of parts that are full and have already been sent.
+	sent int
+	// The message that is being edited and what it currently says.
+	msg    *core.EventMessage
+	shown  string
+	edited time.Time
+}
+
+func newStream(client core.Messenger, send func(any, core.Urr) (*core.EventMessage, error)) *stream {
+	s := &stream{
+		send:   send,
+		lenCnt: utf8.RuneCountInString,
+		lenLim: math.MaxInt,
+	}
+	if l, ok := client.(core.Limiter); ok {
+		s.lenCnt, s.lenLim = l.Limit()
+	}
+	_, s.edit = client.(core.Editor)
+	return s
+}
+
+func (s *stream) write(delta string) error {
+	s.pending += delta
+	sentences, rest := core.Sentences(s.pending)
+	if len(sentences) == 0 {
+		return nil
+	}
+	s.text += strings.Join(sentences, "")
+	s.pending = rest
+	return s.flush(false)
+}
+
+// Sends whatever hasn't been sent yet from the final response, which is what
+// has been written so far, minus any incomplete sentence if it got truncated.
+func (s *stream) close(reply string) error {
+	s.text, s.pending = reply, ""
+	return s.flush(true)
+}
+
+func (s *stream) flush(final bool) error {
+	parts := core.SplitSentences(s.text, s.lenCnt, s.lenLim)
+
+	// Every part except the last one is full, which means it's not going to
+	// change anymore.
+	for ; s.sent < len(parts)-1; s.sent++ {
+		if err := s.put(parts[s.sent]); err != nil {
+			return err
+		}
+		s.msg, s.shown = nil, ""
+	}
+
+	if final || (s.edit && time.Since(s.edited) >= editInterval) {
+		return s.put(parts[len(parts)-1])
+	}
+	return nil
+}
+
+// Shows the part, either by editing the current message or by sending a new
+// one.
+func (s *stream) put(part string) error {
+	if part == "" || part == s.shown {
+		return nil
+	}
+
+	var resp *core.EventMessage
+	var err error
+	if e, ok := s.editor(); ok {
+		resp, err = e.Edit(s.msg.ID, part)
+	} else {
+		resp, err = s.send(part, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.edited = time.Now()
+	if s.edit {
+		s.msg, s.shown = resp, part
+	}
+	return nil
+}
+
+func (s *stream) editor() (core.Editor, bool) {
+	if s.msg == nil {
+		return nil, false
+	}
+	e, ok := s.msg.Client.(core.Editor)
+	return e, ok
+}
+
+// Speak streams the response to a user prompt using send, which is usually
+// either the client's Write or Natural. See stream for how the response is
+// split up and Talk for how person and place are used. Returns the whole
+// response.
+func Speak(client core.Messenger, send func(any, core.Urr) (*core.EventMessage, error), person, place int64, userPrompt string) (string, error) {
+	return speak(client, send, person, place, userPrompt, userPrompt, false)
+}
+
+// The same as Speak, see talk for what filtered and silent do.
+func speak(client core.Messenger, send func(any, core.Urr) (*core.EventMessage, error), person, place int64, userPrompt, filtered string, silent bool) (string, error) {
+	s := newStream(client, send)
+
+	// If sending fails there's no point in trying to send the rest
+	var serr error
+	reply, err := talk(person, place, userPrompt, filtered, silent, func(delta string) {
+		if serr == nil {
+			serr = s.write(delta)
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	if serr != nil {
+		return "", serr
+	}
+	return reply, s.close(reply)
+}
+
 // ReplyOnGet returns whether auto-replying is on or off (true or false) in the
 // specified place.
 func ReplyOnGet(place int64) (bool, error) {
@@ -147,6 +314,9 @@ func ReplyIntervalSet(place int64, dur time.Duration) (error, error) {
 	return nil, core.DB.PlaceSet("cmd_god_auto_interval", place, int(dur.Seconds()))
 }
 
+// Shown to viewers when they redeem the rewards that get created for god.
+const redeemPrompt = "Ask God anything."
+
 func RedeemSet(place int64, id string) error {
 	u, err := uuid.Parse(id)
 	if err != nil {
@@ -197,9 +367,14 @@ func personalityActive(tx *core.Tx, place int64) (Personality, int, error) {
 }
 
 // PersonalityActive returns the currently selected personality for place, along
-// with the maximum allowed tokens to be passed in the OpenAI request.
+// with the maximum allowed tokens to be passed to the backend.
 // Assumes that at least one exists, as it probably does because of globals.
 func PersonalityActive(place int64) (Personality, int, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return Personality{}, 0, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return Personality{}, 0, err
@@ -239,6 +414,11 @@ func personalitySet(tx *core.Tx, place int64, name string) (string, core.Urr, er
 // PersonalitySet updates the active personality in place.
 // Returns UrrPersonalityNotFound if name doesn't correspond to one.
 func PersonalitySet(place int64, name string) (string, core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return "", nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return "", nil, err
@@ -280,6 +460,11 @@ func personalityGlobal(tx *core.Tx, place int64, name string) (bool, error) {
 // Returns UrrPersonalityExists if one by that name already exists in place.
 // To edit an existing personality's prompt use PersonalityEdit.
 func PersonalityAdd(place int64, name, prompt string) (core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -318,6 +503,11 @@ func PersonalityAdd(place int64, name, prompt string) (core.Urr, error) {
 // Returns UrrGlobalPersonality if it's global and not place-defined.
 // Returns UrrPromptSame if the old prompt and newPrompt are equal.
 func PersonalityEdit(place int64, name, newPrompt string) (string, core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return "", nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return "", nil, err
@@ -374,6 +564,11 @@ func PersonalityEdit(place int64, name, newPrompt string) (string, core.Urr, err
 // Returns UrrPersonalityNotFound if name doesn't correspond to any.
 // Returns UrrGlobalPersonality if it's global and not place-defined.
 func PersonalityDelete(place int64, name string) (core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return nil, err
@@ -432,6 +627,11 @@ func PersonalityDelete(place int64, name string) (core.Urr, error) {
 // PersonalityGet returns the specified personality in place.
 // Returns UrrPersonalityNotFound if name doesn't correspond to any.
 func PersonalityGet(place int64, name string) (Personality, core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return Personality{}, nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return Personality{}, nil, err
@@ -501,6 +701,11 @@ func personalitiesList(tx *core.Tx, place int64) ([]Personality, error) {
 // PersonalitiesList returns the list of all available personalities available
 // in place, includes both global and place-defined ones.
 func PersonalitiesList(place int64) ([]Personality, error) {
+	place, err := core.DB.PlaceShared(place, core.SharePersonalities)
+	if err != nil {
+		return nil, err
+	}
+
 	tx, err := core.DB.Begin()
 	if err != nil {
 		return nil, err
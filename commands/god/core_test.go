@@ -0,0 +1,376 @@
+package god_test
+
+import (
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kvlach/janitorjeff/commands/god"
+	"github.com/kvlach/janitorjeff/core"
+	_ "github.com/kvlach/janitorjeff/internal/testing_init"
+	"github.com/kvlach/janitorjeff/internal/testkit"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	place  int64
+	person int64
+	fake   = &god.Fake{}
+)
+
+const model = "test-model"
+
+// Returns the latest request the fake backend received.
+func lastRequest(t *testing.T) god.Request {
+	reqs := fake.Requests()
+	if len(reqs) == 0 {
+		t.Fatal("expected the backend to have received a request")
+	}
+	return reqs[len(reqs)-1]
+}
+
+func TestTalk(t *testing.T) {
+	reply, err := god.Talk(-1, place, "hello")
+	if err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if reply != "hello" {
+		t.Fatalf("expected reply 'hello', got '%s'", reply)
+	}
+
+	p, _, err := god.PersonalityActive(place)
+	if err != nil {
+		t.Fatalf("failed to get active personality: %v", err)
+	}
+
+	req := lastRequest(t)
+	if req.Model != model {
+		t.Fatalf("expected model '%s', got '%s'", model, req.Model)
+	}
+	if len(req.Messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d: %v", len(req.Messages), req.Messages)
+	}
+	if req.Messages[0].Role != god.RoleSystem || req.Messages[0].Content != p.Prompt {
+		t.Fatalf("expected the personality's prompt as the system message, got %v", req.Messages[0])
+	}
+}
+
+func TestTalkDialogue(t *testing.T) {
+	if _, err := god.Talk(person, place, "first"); err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if _, err := god.Talk(person, place, "second"); err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+
+	req := lastRequest(t)
+	expected := []god.Message{
+		{Role: god.RoleUser, Content: "first"},
+		{Role: god.RoleAssistant, Content: "first"},
+		{Role: god.RoleUser, Content: "second"},
+	}
+	if len(req.Messages) != len(expected)+1 {
+		t.Fatalf("expected %d messages, got %d: %v", len(expected)+1, len(req.Messages), req.Messages)
+	}
+	for i, msg := range expected {
+		if req.Messages[i+1] != msg {
+			t.Fatalf("expected message %v, got %v", msg, req.Messages[i+1])
+		}
+	}
+}
+
+// Keeps track of every message sent, each of which can be at most 20 bytes
+// long.
+type messenger struct {
+	core.Messenger
+	sent []string
+}
+
+func (m *messenger) Limit() (func(string) int, int) {
+	return func(s string) int { return len(s) }, 20
+}
+
+func (m *messenger) Send(msg any, _ core.Urr) (*core.EventMessage, error) {
+	m.sent = append(m.sent, msg.(string))
+	return nil, nil
+}
+
+func TestSpeak(t *testing.T) {
+	tests := []struct {
+		reply string
+		err   error
+		sent  []string
+	}{
+		{"First sentence. Second one here. Third.", nil, []string{
+			"First sentence.",
+			"Second one here.",
+			"Third.",
+		}},
+		// The incomplete sentence at the end gets dropped.
+		{"One. Two. Three fo", god.ErrTruncated, []string{
+			"One. Two.",
+		}},
+	}
+
+	defer func() { fake.Reply = nil }()
+
+	for _, test := range tests {
+		fake.Reply = func(god.Request) (string, error) {
+			return test.reply, test.err
+		}
+
+		m := &messenger{}
+		if _, err := god.Speak(m, m.Send, -1, place, "hello"); err != nil {
+			t.Fatalf("failed to speak: %v", err)
+		}
+		if !slices.Equal(m.sent, test.sent) {
+			t.Fatalf("expected %#v to be sent, got %#v", test.sent, m.sent)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	if err := god.MemoryClear(person, place); err != nil {
+		t.Fatalf("failed to clear memory: %v", err)
+	}
+	if urr, err := god.BudgetSet(place, god.MinBudget); urr != nil || err != nil {
+		t.Fatalf("failed to set budget: urr = %v, err = %v", urr, err)
+	}
+	defer god.BudgetSet(place, 1000)
+
+	// Each exchange takes up about 100 tokens, since the reply is the same as
+	// the prompt, which means that the second one goes over the budget.
+	prompt := strings.Repeat("abcd", 50)
+	for i := 0; i < 2; i++ {
+		if _, err := god.Talk(person, place, prompt); err != nil {
+			t.Fatalf("failed to talk: %v", err)
+		}
+	}
+
+	// Summarizing happens in the background
+	var mem god.Memory
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		var urr core.Urr
+		var err error
+		mem, urr, err = god.MemoryShow(person, place)
+		if urr != nil || err != nil {
+			t.Fatalf("failed to get memory: urr = %v, err = %v", urr, err)
+		}
+		if mem.Summary != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the first exchange to have been summarized")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(mem.Turns) != 2 {
+		t.Fatalf("expected only the latest exchange to be kept, got %d turns", len(mem.Turns))
+	}
+
+	if err := god.MemoryClear(person, place); err != nil {
+		t.Fatalf("failed to clear memory: %v", err)
+	}
+	if _, urr, err := god.MemoryShow(person, place); urr != god.UrrNoMemory || err != nil {
+		t.Fatalf("expected NoMemory user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	if urr, err := god.RetentionSet(place, time.Second); urr != god.UrrRetentionTooLow || err != nil {
+		t.Fatalf("expected RetentionTooLow user error, got: urr = %v, err = %v", urr, err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if urr, err := god.ContextSizeSet(place, 0); urr != god.UrrInvalidSize || err != nil {
+		t.Fatalf("expected InvalidSize user error, got: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := god.ContextSizeSet(place, god.ContextSizeMax+1); urr != god.UrrInvalidSize || err != nil {
+		t.Fatalf("expected InvalidSize user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	if urr, err := god.ContextKeywordExclude(place, "Secret"); urr != nil || err != nil {
+		t.Fatalf("failed to exclude keyword: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := god.ContextKeywordExclude(place, "secret"); urr != god.UrrAlreadyExcluded || err != nil {
+		t.Fatalf("expected AlreadyExcluded user error, got: urr = %v, err = %v", urr, err)
+	}
+	keywords, urr, err := god.ContextKeywordsExcluded(place)
+	if urr != nil || err != nil {
+		t.Fatalf("failed to get excluded keywords: urr = %v, err = %v", urr, err)
+	}
+	if !slices.Equal(keywords, []string{"secret"}) {
+		t.Fatalf("expected the keyword to be saved in lowercase, got %v", keywords)
+	}
+	if urr, err := god.ContextKeywordInclude(place, "SECRET"); urr != nil || err != nil {
+		t.Fatalf("failed to include keyword: urr = %v, err = %v", urr, err)
+	}
+	if _, urr, err := god.ContextKeywordsExcluded(place); urr != god.UrrNothingExcluded || err != nil {
+		t.Fatalf("expected NothingExcluded user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	if urr, err := god.ContextPersonExclude(place, person); urr != nil || err != nil {
+		t.Fatalf("failed to exclude person: urr = %v, err = %v", urr, err)
+	}
+	people, urr, err := god.ContextPeopleExcluded(place)
+	if urr != nil || err != nil {
+		t.Fatalf("failed to get excluded people: urr = %v, err = %v", urr, err)
+	}
+	if !slices.Equal(people, []int64{person}) {
+		t.Fatalf("expected %d to be excluded, got %v", person, people)
+	}
+	if urr, err := god.ContextPersonInclude(place, person); urr != nil || err != nil {
+		t.Fatalf("failed to include person: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := god.ContextPersonInclude(place, person); urr != god.UrrNotExcluded || err != nil {
+		t.Fatalf("expected NotExcluded user error, got: urr = %v, err = %v", urr, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	if urr, err := god.TermAdd(place, "Forbidden"); urr != nil || err != nil {
+		t.Fatalf("failed to ban term: urr = %v, err = %v", urr, err)
+	}
+	defer god.TermDelete(place, "forbidden")
+	if urr, err := god.TermAdd(place, "forbidden"); urr != god.UrrTermExists || err != nil {
+		t.Fatalf("expected TermExists user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	// A blocked prompt never reaches the backend
+	n := len(fake.Requests())
+	reply, err := god.Talk(-1, place, "say something FORBIDDEN")
+	if err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if reply != god.DefaultFallback {
+		t.Fatalf("expected the fallback reply, got '%s'", reply)
+	}
+	if len(fake.Requests()) != n {
+		t.Fatal("expected the blocked prompt to not be sent to the backend")
+	}
+
+	// A blocked reply isn't remembered
+	if err := god.MemoryClear(person, place); err != nil {
+		t.Fatalf("failed to clear memory: %v", err)
+	}
+	fake.Reply = func(god.Request) (string, error) {
+		return "That is forbidden knowledge.", nil
+	}
+	reply, err = god.Talk(person, place, "tell me a secret")
+	fake.Reply = nil
+	if err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if reply != god.DefaultFallback {
+		t.Fatalf("expected the fallback reply, got '%s'", reply)
+	}
+	if _, urr, err := god.MemoryShow(person, place); urr != god.UrrNoMemory || err != nil {
+		t.Fatalf("expected NoMemory user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	if err := god.ModerationSet(place, true); err != nil {
+		t.Fatalf("failed to turn moderation on: %v", err)
+	}
+	defer god.ModerationSet(place, false)
+	if err := god.FallbackSet(place, "No."); err != nil {
+		t.Fatalf("failed to set fallback: %v", err)
+	}
+	defer god.FallbackReset(place)
+	fake.Flag = func(text string) string {
+		if strings.Contains(text, "flag me") {
+			return "flagged for testing"
+		}
+		return ""
+	}
+	defer func() { fake.Flag = nil }()
+
+	reply, err = god.Talk(-1, place, "please flag me")
+	if err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if reply != "No." {
+		t.Fatalf("expected the place's fallback reply, got '%s'", reply)
+	}
+	if reply, err = god.Talk(-1, place, "hello"); err != nil || reply != "hello" {
+		t.Fatalf("expected reply 'hello', got '%s' with err = %v", reply, err)
+	}
+
+	blocked, urr, err := god.BlockedList(place)
+	if urr != nil || err != nil {
+		t.Fatalf("failed to get blocked messages: urr = %v, err = %v", urr, err)
+	}
+	if len(blocked) != 3 {
+		t.Fatalf("expected 3 blocked messages, got %d: %v", len(blocked), blocked)
+	}
+	// newest first
+	if b := blocked[0]; b.Person != -1 || b.Kind != god.KindPrompt || b.Reason != "flagged for testing" {
+		t.Fatalf("unexpected blocked prompt: %v", b)
+	}
+	if b := blocked[1]; b.Person != person || b.Kind != god.KindReply {
+		t.Fatalf("unexpected blocked reply: %v", b)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if urr, err := god.ConfigSet(place, god.SettingModel, "other-model"); urr != nil || err != nil {
+		t.Fatalf("failed to set model: urr = %v, err = %v", urr, err)
+	}
+	if _, err := god.Talk(-1, place, "hello"); err != nil {
+		t.Fatalf("failed to talk: %v", err)
+	}
+	if req := lastRequest(t); req.Model != "other-model" {
+		t.Fatalf("expected model 'other-model', got '%s'", req.Model)
+	}
+
+	if urr, err := god.ConfigSet(place, god.SettingURL, "not a url"); urr != god.UrrInvalidURL || err != nil {
+		t.Fatalf("expected InvalidURL user error, got: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := god.ConfigSet(place, god.SettingTimeout, "0s"); urr != god.UrrInvalidTimeout || err != nil {
+		t.Fatalf("expected InvalidTimeout user error, got: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := god.ConfigSet(place, "nope", "value"); urr != god.UrrUnknownSetting || err != nil {
+		t.Fatalf("expected UnknownSetting user error, got: urr = %v, err = %v", urr, err)
+	}
+
+	if urr, err := god.ConfigReset(place, god.SettingModel); urr != nil || err != nil {
+		t.Fatalf("failed to reset model: urr = %v, err = %v", urr, err)
+	}
+	cfg, err := god.ConfigGet(place)
+	if err != nil {
+		t.Fatalf("failed to get config: %v", err)
+	}
+	if cfg.Model != model {
+		t.Fatalf("expected model to be reset to '%s', got '%s'", model, cfg.Model)
+	}
+}
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	tdb := testkit.NewTestDB()
+	msg := testkit.NewTestMessage().DiscordRandom()
+
+	core.GodModel = model
+	core.GodBaseURL = "http://localhost"
+	core.GodTimeout = 10 * time.Second
+	god.SetBackend(fake)
+
+	var err error
+
+	place, err = msg.Here.ScopeLogical()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	person, err = msg.Author.Scope()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	code := m.Run()
+	tdb.Delete()
+	os.Exit(code)
+}
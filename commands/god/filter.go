@@ -0,0 +1,383 @@
+package god
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrTermExists     = core.UrrNew("This term is already banned.")
+	UrrTermNotFound   = core.UrrNew("This term isn't banned.")
+	UrrTermTooLong    = core.UrrNew("The term is too long.")
+	UrrNoTerms        = core.UrrNew("No terms have been banned.")
+	UrrNothingBlocked = core.UrrNew("Nothing has been blocked.")
+	UrrBlocked        = core.UrrNew("The filter blocked the message.")
+)
+
+// ErrModerationUnsupported is returned by a Moderator when the place's server
+// doesn't support moderation. Places that have moderation turned on while
+// it's not supported are only filtered by their banned terms.
+var ErrModerationUnsupported = errors.New("backend doesn't support moderation")
+
+// DefaultFallback is the reply that is sent in place of anything that gets
+// blocked, unless the place has set its own.
+const DefaultFallback = "I'd rather not talk about that."
+
+// Terms are saved as VARCHAR(255).
+const termMax = 255
+
+// BlockedShown is how many of the latest blocked messages can be reviewed.
+const BlockedShown = 10
+
+// What was blocked, either what was said to God or what God said.
+const (
+	KindPrompt = "prompt"
+	KindReply  = "reply"
+)
+
+// Blocked is a message that got caught by a place's filter.
+type Blocked struct {
+	// -1 if it was an auto-reply.
+	Person int64
+	Kind   string
+	Text   string
+	Reason string
+	When   time.Time
+}
+
+// Filter decides what God is allowed to respond to and say in a place.
+type Filter struct {
+	// Lowercase, matched case-insensitively anywhere in the text.
+	Terms []string
+	// Whether the backend's moderation is also used, see Moderator.
+	Moderation bool
+	// Sent in place of anything that gets blocked.
+	Fallback string
+}
+
+// Active returns whether there is anything to filter.
+func (f Filter) Active() bool {
+	return len(f.Terms) != 0 || f.Moderation
+}
+
+// Returns the banned term that the text contains, or an empty string if it
+// doesn't contain any.
+func (f Filter) banned(text string) string {
+	lower := strings.ToLower(text)
+	for _, t := range f.Terms {
+		if strings.Contains(lower, t) {
+			return t
+		}
+	}
+	return ""
+}
+
+// Returns why the text should be blocked, or an empty string if it shouldn't.
+func (f Filter) check(cfg Config, text string) (string, error) {
+	if t := f.banned(text); t != "" {
+		return fmt.Sprintf("banned term %q", t), nil
+	}
+
+	if !f.Moderation {
+		return "", nil
+	}
+	m, ok := getBackend().(Moderator)
+	if !ok {
+		log.Warn().Msg("backend doesn't support moderation, only filtering banned terms")
+		return "", nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	reason, err := m.Moderate(ctx, cfg, text)
+	if errors.Is(err, ErrModerationUnsupported) {
+		log.Warn().
+			Str("base-url", cfg.BaseURL).
+			Msg("server doesn't support moderation, only filtering banned terms")
+		return "", nil
+	}
+	return reason, err
+}
+
+// Logs the text so that moderators can review it and returns the fallback
+// reply that should be sent instead. If silent is true, nothing should be
+// sent, so UrrBlocked is returned instead.
+func (f Filter) block(person, place int64, kind, text, reason string, silent bool, delta func(string)) (string, error) {
+	log.Debug().
+		Int64("person", person).
+		Int64("place", place).
+		Str("kind", kind).
+		Str("reason", reason).
+		Msg("filter blocked message")
+
+	if err := dbBlockedAdd(person, place, kind, text, reason); err != nil {
+		return "", err
+	}
+	if silent {
+		return "", UrrBlocked
+	}
+	if delta != nil {
+		delta(f.Fallback)
+	}
+	return f.Fallback, nil
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbTermAdd(place int64, term string) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		INSERT INTO cmd_god_filter_terms (place, term)
+		VALUES ($1, $2)
+		ON CONFLICT (place, term) DO NOTHING
+	`, place, term)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("term", term).
+		Msg("banned term")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbTermDelete(place int64, term string) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_god_filter_terms
+		WHERE place = $1 AND term = $2
+	`, place, term)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("term", term).
+		Msg("unbanned term")
+
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n != 0, err
+}
+
+func dbTermsList(place int64) ([]string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var terms []string
+	err := db.DB.QueryRow(`
+		SELECT COALESCE(ARRAY_AGG(term ORDER BY term), '{}')
+		FROM cmd_god_filter_terms
+		WHERE place = $1
+	`, place).Scan(pq.Array(&terms))
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Strs("terms", terms).
+		Msg("got banned terms")
+
+	return terms, err
+}
+
+func dbBlockedAdd(person, place int64, kind, text, reason string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	// Auto-replies aren't sent to anyone in particular
+	p := sql.NullInt64{Int64: person, Valid: person != -1}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO cmd_god_filter_blocked (person, place, kind, text, reason, time)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, p, place, kind, text, reason, time.Now().UTC().Unix())
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Int64("place", place).
+		Str("kind", kind).
+		Str("reason", reason).
+		Msg("logged blocked message")
+
+	return err
+}
+
+func dbBlockedList(place int64, n int) ([]Blocked, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT COALESCE(person, -1), kind, text, reason, time
+		FROM cmd_god_filter_blocked
+		WHERE place = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, place, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocked []Blocked
+	for rows.Next() {
+		var b Blocked
+		var when int64
+		if err := rows.Scan(&b.Person, &b.Kind, &b.Text, &b.Reason, &when); err != nil {
+			return nil, err
+		}
+		b.When = time.Unix(when, 0).UTC()
+		blocked = append(blocked, b)
+	}
+
+	log.Debug().
+		Err(rows.Err()).
+		Int64("place", place).
+		Int("blocked", len(blocked)).
+		Msg("got blocked messages")
+
+	return blocked, rows.Err()
+}
+
+////////////
+//        //
+// filter //
+//        //
+////////////
+
+// FilterGet returns the place's filter.
+func FilterGet(place int64) (Filter, error) {
+	terms, err := dbTermsList(place)
+	if err != nil {
+		return Filter{}, err
+	}
+	moderation, err := ModerationGet(place)
+	if err != nil {
+		return Filter{}, err
+	}
+	fallback, err := FallbackGet(place)
+	if err != nil {
+		return Filter{}, err
+	}
+	return Filter{
+		Terms:      terms,
+		Moderation: moderation,
+		Fallback:   fallback,
+	}, nil
+}
+
+// TermAdd bans the term in the place, case-insensitively. Returns
+// UrrTermExists if it already was.
+func TermAdd(place int64, term string) (core.Urr, error) {
+	term = strings.ToLower(term)
+	if len(term) > termMax {
+		return UrrTermTooLong, nil
+	}
+	added, err := dbTermAdd(place, term)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return UrrTermExists, nil
+	}
+	return nil, nil
+}
+
+// TermDelete unbans the term in the place. Returns UrrTermNotFound if it
+// wasn't banned.
+func TermDelete(place int64, term string) (core.Urr, error) {
+	deleted, err := dbTermDelete(place, strings.ToLower(term))
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return UrrTermNotFound, nil
+	}
+	return nil, nil
+}
+
+// TermsList returns the terms banned in the place. Returns UrrNoTerms if there
+// are none.
+func TermsList(place int64) ([]string, core.Urr, error) {
+	terms, err := dbTermsList(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(terms) == 0 {
+		return nil, UrrNoTerms, nil
+	}
+	return terms, nil, nil
+}
+
+// ModerationGet returns whether the backend's moderation is used in the place.
+func ModerationGet(place int64) (bool, error) {
+	return core.DB.PlaceGet("cmd_god_filter_moderation", place).Bool()
+}
+
+// ModerationSet turns the backend's moderation on or off in the place.
+func ModerationSet(place int64, on bool) error {
+	return core.DB.PlaceSet("cmd_god_filter_moderation", place, on)
+}
+
+// FallbackGet returns the reply that is sent in place of anything that gets
+// blocked in the place.
+func FallbackGet(place int64) (string, error) {
+	fallback, urr, err := core.DB.PlaceGet("cmd_god_filter_fallback", place).StrNil()
+	if err != nil {
+		return "", err
+	}
+	if urr != nil {
+		return DefaultFallback, nil
+	}
+	return fallback, nil
+}
+
+// FallbackSet sets the reply that is sent in place of anything that gets
+// blocked in the place.
+func FallbackSet(place int64, fallback string) error {
+	return core.DB.PlaceSet("cmd_god_filter_fallback", place, fallback)
+}
+
+// FallbackReset makes the place use DefaultFallback again.
+func FallbackReset(place int64) error {
+	return core.DB.PlaceSet("cmd_god_filter_fallback", place, nil)
+}
+
+// BlockedList returns the latest BlockedShown messages that were blocked in the
+// place, newest first. Returns UrrNothingBlocked if there are none.
+func BlockedList(place int64) ([]Blocked, core.Urr, error) {
+	blocked, err := dbBlockedList(place, BlockedShown)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(blocked) == 0 {
+		return nil, UrrNothingBlocked, nil
+	}
+	return blocked, nil, nil
+}
@@ -0,0 +1,419 @@
+package god
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kvlach/janitorjeff/core"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrInvalidRetention  = core.UrrNew("Expected a retention in the form of 1h30m, or 0 to never forget.")
+	UrrRetentionTooLow   = core.UrrNew("The retention must be at least a minute.")
+	UrrBudgetTooLow      = core.UrrNew("The token budget is too low.")
+	UrrNoMemory          = core.UrrNew("God doesn't remember talking to you.")
+	UrrExportUnsupported = core.UrrNew("Exporting is only supported on Discord.")
+)
+
+// MinBudget is the lowest token budget a place can set, anything lower
+// wouldn't even fit a single exchange.
+const MinBudget = 100
+
+// The prompt used when summarizing the turns that no longer fit in the token
+// budget.
+const summaryPrompt = "Summarize the following conversation between a user and an assistant. Keep any facts, names and preferences that might be useful later on. Respond with only the summary."
+
+// Turn is a single message from either the user or the assistant that is part
+// of a person's conversation.
+type Turn struct {
+	ID      int64     `json:"-"`
+	Role    string    `json:"role"`
+	Content string    `json:"content"`
+	When    time.Time `json:"time"`
+}
+
+// Memory is what God remembers of a conversation. Turns that didn't fit in the
+// place's token budget get summarized.
+type Memory struct {
+	Summary string `json:"summary,omitempty"`
+	Turns   []Turn `json:"turns"`
+}
+
+// Messages returns the memory in the form that gets passed to the backend.
+func (mem Memory) Messages() []Message {
+	var msgs []Message
+	if mem.Summary != "" {
+		msgs = append(msgs, Message{
+			Role:    RoleSystem,
+			Content: "Summary of the conversation so far: " + mem.Summary,
+		})
+	}
+	for _, t := range mem.Turns {
+		msgs = append(msgs, Message{
+			Role:    t.Role,
+			Content: t.Content,
+		})
+	}
+	return msgs
+}
+
+// Very rough estimate of the number of tokens, it's about 4 characters per
+// token for english text. Good enough for keeping the memory in check, without
+// pulling in a tokenizer for every model that might be used.
+func tokens(s string) int {
+	return (utf8.RuneCountInString(s) + 3) / 4
+}
+
+func (mem Memory) tokens() int {
+	n := tokens(mem.Summary)
+	for _, t := range mem.Turns {
+		n += tokens(t.Content)
+	}
+	return n
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbMemoryGet(person, place int64) (Memory, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var mem Memory
+
+	err := db.DB.QueryRow(`
+		SELECT summary
+		FROM cmd_god_memory_summaries
+		WHERE person = $1 AND place = $2
+	`, person, place).Scan(&mem.Summary)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Memory{}, err
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, role, content, time
+		FROM cmd_god_memory
+		WHERE person = $1 AND place = $2
+		ORDER BY id ASC
+	`, person, place)
+	if err != nil {
+		return Memory{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t Turn
+		var when int64
+		if err := rows.Scan(&t.ID, &t.Role, &t.Content, &when); err != nil {
+			return Memory{}, err
+		}
+		t.When = time.Unix(when, 0).UTC()
+		mem.Turns = append(mem.Turns, t)
+	}
+
+	log.Debug().
+		Err(rows.Err()).
+		Int64("person", person).
+		Int64("place", place).
+		Interface("memory", mem).
+		Msg("got memory")
+
+	return mem, rows.Err()
+}
+
+func dbMemoryAdd(person, place int64, turns ...Turn) error {
+	tx, err := core.DB.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	for _, t := range turns {
+		_, err := tx.Tx.Exec(`
+			INSERT INTO cmd_god_memory (person, place, role, content, time)
+			VALUES ($1, $2, $3, $4, $5)
+		`, person, place, t.Role, t.Content, t.When.Unix())
+
+		log.Debug().
+			Err(err).
+			Int64("person", person).
+			Int64("place", place).
+			Interface("turn", t).
+			Msg("added turn to memory")
+
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Replaces every turn up to and including the one with the given ID with the
+// summary.
+func dbMemorySummarize(person, place, until int64, summary string) error {
+	tx, err := core.DB.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM cmd_god_memory
+		WHERE person = $1 AND place = $2 AND id <= $3
+	`, person, place, until)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		INSERT INTO cmd_god_memory_summaries (person, place, summary, time)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (person, place) DO UPDATE SET summary = $3, time = $4
+	`, person, place, summary, time.Now().UTC().Unix())
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Int64("place", place).
+		Int64("until", until).
+		Str("summary", summary).
+		Msg("summarized memory")
+
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Deletes everything that is older than the given unix timestamp. If before
+// is -1 then everything is deleted.
+func dbMemoryForget(person, place, before int64) error {
+	tx, err := core.DB.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM cmd_god_memory
+		WHERE person = $1 AND place = $2 AND ($3 = -1 OR time < $3)
+	`, person, place, before)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM cmd_god_memory_summaries
+		WHERE person = $1 AND place = $2 AND ($3 = -1 OR time < $3)
+	`, person, place, before)
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Int64("place", place).
+		Int64("before", before).
+		Msg("forgot memory")
+
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+////////////
+//        //
+// memory //
+//        //
+////////////
+
+// MemoryGet returns what God remembers of the person's conversation in the
+// place. Anything older than the place's retention is forgotten first.
+func MemoryGet(person, place int64) (Memory, error) {
+	retention, err := RetentionGet(place)
+	if err != nil {
+		return Memory{}, err
+	}
+	if retention != 0 {
+		before := time.Now().UTC().Add(-retention).Unix()
+		if err := dbMemoryForget(person, place, before); err != nil {
+			return Memory{}, err
+		}
+	}
+	return dbMemoryGet(person, place)
+}
+
+// MemoryShow is the same as MemoryGet, except that it returns UrrNoMemory if
+// there is nothing to show.
+func MemoryShow(person, place int64) (Memory, core.Urr, error) {
+	mem, err := MemoryGet(person, place)
+	if err != nil {
+		return Memory{}, nil, err
+	}
+	if mem.Summary == "" && len(mem.Turns) == 0 {
+		return Memory{}, UrrNoMemory, nil
+	}
+	return mem, nil, nil
+}
+
+// MemoryClear makes God forget the person's conversation in the place.
+func MemoryClear(person, place int64) error {
+	return dbMemoryForget(person, place, -1)
+}
+
+// MemoryExport returns the person's conversation in the place as JSON.
+// Returns UrrNoMemory if there is nothing to export.
+func MemoryExport(person, place int64) ([]byte, core.Urr, error) {
+	mem, urr, err := MemoryShow(person, place)
+	if urr != nil || err != nil {
+		return nil, urr, err
+	}
+	b, err := json.MarshalIndent(mem, "", "  ")
+	return b, nil, err
+}
+
+// Adds the exchange to the person's memory and, if that goes over the place's
+// token budget, summarizes the oldest turns. Summarizing is another request to
+// the backend, so it happens in the background in order to not hold back the
+// reply.
+func remember(cfg Config, person, place int64, userPrompt, reply string) error {
+	now := time.Now().UTC()
+	err := dbMemoryAdd(person, place,
+		Turn{Role: RoleUser, Content: userPrompt, When: now},
+		Turn{Role: RoleAssistant, Content: reply, When: now},
+	)
+	if err != nil {
+		return err
+	}
+	go compact(cfg, person, place)
+	return nil
+}
+
+// The memories that are currently being summarized, so that the same turns
+// don't get summarized twice if the person keeps talking in the meantime.
+var compacting sync.Map
+
+func compact(cfg Config, person, place int64) {
+	key := [2]int64{person, place}
+	if _, busy := compacting.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+	defer compacting.Delete(key)
+
+	if err := summarizeOldest(cfg, person, place); err != nil {
+		log.Error().
+			Err(err).
+			Int64("person", person).
+			Int64("place", place).
+			Msg("failed to compact memory")
+	}
+}
+
+// Summarizes the oldest turns of the person's memory if it's over the place's
+// token budget.
+func summarizeOldest(cfg Config, person, place int64) error {
+	budget, err := BudgetGet(place)
+	if err != nil {
+		return err
+	}
+	mem, err := dbMemoryGet(person, place)
+	if err != nil {
+		return err
+	}
+	if mem.tokens() <= budget {
+		return nil
+	}
+
+	// Summarize the oldest turns until the rest take up at most half of the
+	// budget, leaving the other half for the summary. The latest exchange is
+	// always kept as is.
+	n, rest := 0, mem.tokens()-tokens(mem.Summary)
+	for n < len(mem.Turns)-2 && rest > budget/2 {
+		rest -= tokens(mem.Turns[n].Content)
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+
+	summary, err := summarize(cfg, mem.Summary, mem.Turns[:n], budget/2)
+	if err != nil {
+		// The turns are kept, so they can be summarized next time instead
+		// of being lost.
+		log.Error().Err(err).Msg("failed to summarize memory")
+		return nil
+	}
+	return dbMemorySummarize(person, place, mem.Turns[n-1].ID, summary)
+}
+
+// Asks the backend to summarize the turns, along with the previous summary.
+func summarize(cfg Config, summary string, turns []Turn, max int) (string, error) {
+	var b strings.Builder
+	if summary != "" {
+		fmt.Fprintf(&b, "Summary of the earlier conversation: %s\n\n", summary)
+	}
+	for _, t := range turns {
+		fmt.Fprintf(&b, "%s: %s\n", t.Role, t.Content)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	s, err := getBackend().Complete(ctx, cfg, Request{
+		Model:     cfg.Model,
+		MaxTokens: max,
+		Messages: []Message{
+			{Role: RoleSystem, Content: summaryPrompt},
+			{Role: RoleUser, Content: b.String()},
+		},
+	})
+	// A summary that got cut off is still better than none
+	if err != nil && !errors.Is(err, ErrTruncated) {
+		return "", err
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// RetentionGet returns how long conversations are remembered for in the place,
+// zero means forever.
+func RetentionGet(place int64) (time.Duration, error) {
+	return core.DB.PlaceGet("cmd_god_memory_retention", place).Duration()
+}
+
+// RetentionSet sets how long conversations are remembered for in the place.
+// Returns UrrRetentionTooLow if it's non-zero and less than a minute.
+func RetentionSet(place int64, retention time.Duration) (core.Urr, error) {
+	if retention < 0 || (retention != 0 && retention < time.Minute) {
+		return UrrRetentionTooLow, nil
+	}
+	return nil, core.DB.PlaceSet("cmd_god_memory_retention", place, int(retention.Seconds()))
+}
+
+// BudgetGet returns the maximum number of tokens a conversation can take up in
+// the place, before the oldest parts of it start getting summarized.
+func BudgetGet(place int64) (int, error) {
+	return core.DB.PlaceGet("cmd_god_memory_budget", place).Int()
+}
+
+// BudgetSet sets the place's token budget for conversations. Returns
+// UrrBudgetTooLow if it's less than MinBudget.
+func BudgetSet(place int64, budget int) (core.Urr, error) {
+	if budget < MinBudget {
+		return UrrBudgetTooLow, nil
+	}
+	return nil, core.DB.PlaceSet("cmd_god_memory_budget", place, budget)
+}
@@ -0,0 +1,672 @@
+package group
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	admin, err := m.Author.Admin()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is admin")
+		return false
+	}
+	return admin
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"group",
+	}
+}
+
+func (advanced) Description() string {
+	return "Share custom commands, personalities and reminders with places on other platforms."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedShow,
+		AdvancedCreate,
+		AdvancedInvite,
+		AdvancedJoin,
+		AdvancedLeave,
+		AdvancedShare,
+		AdvancedUnshare,
+	}
+}
+
+func (advanced) Init() error {
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrInGroup:
+		return "This place is already part of a group, leave it first."
+	case UrrNotInGroup:
+		return "This place isn't part of a group."
+	case UrrNotAuthority:
+		return "Only the place that created the group can do this."
+	case UrrInvalidCode:
+		return "The invite code is invalid or has expired."
+	case UrrInvalidFeature:
+		return "Unknown feature, expected one of: " + strings.Join(core.ShareFeatures, ", ")
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+//////////
+//      //
+// show //
+//      //
+//////////
+
+var AdvancedShow = advancedShow{}
+
+type advancedShow struct{}
+
+func (c advancedShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedShow) Description() string {
+	return "Show the places in the group and what they share."
+}
+
+func (advancedShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedShow) Examples() []string {
+	return nil
+}
+
+func (advancedShow) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedShow) Init() error {
+	return nil
+}
+
+func (c advancedShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	members, shared, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if urr != nil {
+		embed := &dg.MessageEmbed{
+			Description: fmtUrr(urr),
+		}
+		return embed, urr, nil
+	}
+
+	embed := &dg.MessageEmbed{
+		Title: "Place Group",
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:  "Places",
+				Value: strings.Join(members, "\n"),
+			},
+			{
+				Name:  "Shared",
+				Value: shared,
+			},
+		},
+	}
+	return embed, nil, nil
+}
+
+func (c advancedShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	members, shared, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return fmt.Sprintf("Places: %s | Shared: %s", strings.Join(members, ", "), shared), nil, nil
+}
+
+func (advancedShow) core(m *core.EventMessage) ([]string, string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	g, urr, err := Show(here)
+	if urr != nil || err != nil {
+		return nil, "", urr, err
+	}
+
+	var members []string
+	for i, place := range g.Members {
+		name, err := placeName(place)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if i == 0 {
+			name += " [authority]"
+		}
+		members = append(members, name)
+	}
+
+	shared := "nothing"
+	if len(g.Shared) != 0 {
+		shared = strings.Join(g.Shared, ", ")
+	}
+
+	return members, shared, nil, nil
+}
+
+////////////
+//        //
+// create //
+//        //
+////////////
+
+var AdvancedCreate = advancedCreate{}
+
+type advancedCreate struct{}
+
+func (c advancedCreate) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCreate) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCreate) Names() []string {
+	return []string{
+		"create",
+		"new",
+	}
+}
+
+func (advancedCreate) Description() string {
+	return "Create a group, this place becomes the one whose data is shared."
+}
+
+func (advancedCreate) UsageArgs() string {
+	return ""
+}
+
+func (c advancedCreate) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCreate) Examples() []string {
+	return nil
+}
+
+func (advancedCreate) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedCreate) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCreate) Init() error {
+	return nil
+}
+
+func (c advancedCreate) Run(m *core.EventMessage) (any, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	invite := core.FormatQuote(AdvancedInvite, m.Command.Prefix, m.Client)
+	return fmt.Sprintf("Created a group, use %s to add other places to it.", invite), nil, nil
+}
+
+func (advancedCreate) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Create(here)
+}
+
+////////////
+//        //
+// invite //
+//        //
+////////////
+
+var AdvancedInvite = advancedInvite{}
+
+type advancedInvite struct{}
+
+func (c advancedInvite) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedInvite) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedInvite) Names() []string {
+	return []string{
+		"invite",
+	}
+}
+
+func (advancedInvite) Description() string {
+	return "Get a code that another place can use to join the group."
+}
+
+func (advancedInvite) UsageArgs() string {
+	return ""
+}
+
+func (c advancedInvite) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedInvite) Examples() []string {
+	return nil
+}
+
+func (advancedInvite) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedInvite) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedInvite) Init() error {
+	return nil
+}
+
+func (c advancedInvite) Run(m *core.EventMessage) (any, core.Urr, error) {
+	code, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	join := core.FormatQuote(AdvancedJoin, m.Command.Prefix, m.Client)
+	return fmt.Sprintf("An admin of the other place has to run %s with the code %s within %d minutes.",
+		join, code, int(inviteExpiry.Minutes())), nil, nil
+}
+
+func (advancedInvite) core(m *core.EventMessage) (string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	return Invite(here)
+}
+
+//////////
+//      //
+// join //
+//      //
+//////////
+
+var AdvancedJoin = advancedJoin{}
+
+type advancedJoin struct{}
+
+func (c advancedJoin) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedJoin) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedJoin) Names() []string {
+	return []string{
+		"join",
+	}
+}
+
+func (advancedJoin) Description() string {
+	return "Join a group using an invite code."
+}
+
+func (advancedJoin) UsageArgs() string {
+	return "<code>"
+}
+
+func (c advancedJoin) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedJoin) Examples() []string {
+	return []string{
+		"K7MPX2QD",
+	}
+}
+
+func (advancedJoin) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedJoin) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedJoin) Init() error {
+	return nil
+}
+
+func (c advancedJoin) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Joined the group.", nil, nil
+}
+
+func (advancedJoin) core(m *core.EventMessage) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Join(here, strings.ToUpper(m.Command.Args[0]))
+}
+
+///////////
+//       //
+// leave //
+//       //
+///////////
+
+var AdvancedLeave = advancedLeave{}
+
+type advancedLeave struct{}
+
+func (c advancedLeave) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedLeave) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedLeave) Names() []string {
+	return []string{
+		"leave",
+	}
+}
+
+func (advancedLeave) Description() string {
+	return "Leave the group, if this place created it then the group is deleted."
+}
+
+func (advancedLeave) UsageArgs() string {
+	return ""
+}
+
+func (c advancedLeave) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedLeave) Examples() []string {
+	return nil
+}
+
+func (advancedLeave) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedLeave) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedLeave) Init() error {
+	return nil
+}
+
+func (c advancedLeave) Run(m *core.EventMessage) (any, core.Urr, error) {
+	deleted, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	if deleted {
+		return "Deleted the group, every place is back to using its own data.", nil, nil
+	}
+	return "Left the group, this place is back to using its own data.", nil, nil
+}
+
+func (advancedLeave) core(m *core.EventMessage) (bool, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return false, nil, err
+	}
+	return Leave(here)
+}
+
+///////////
+//       //
+// share //
+//       //
+///////////
+
+var AdvancedShare = advancedShare{}
+
+type advancedShare struct{}
+
+func (c advancedShare) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedShare) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedShare) Names() []string {
+	return []string{
+		"share",
+	}
+}
+
+func (advancedShare) Description() string {
+	return "Make every place in the group use this place's data for a feature."
+}
+
+func (advancedShare) UsageArgs() string {
+	return "(" + strings.Join(core.ShareFeatures, " | ") + ")"
+}
+
+func (c advancedShare) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedShare) Examples() []string {
+	return []string{
+		core.ShareCustomCommands,
+	}
+}
+
+func (advancedShare) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedShare) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedShare) Init() error {
+	return nil
+}
+
+func (c advancedShare) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	feature := strings.ToLower(m.Command.Args[0])
+	urr, err := c.core(m, feature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return fmt.Sprintf("Every place in the group now uses this place's %s.", feature), nil, nil
+}
+
+func (advancedShare) core(m *core.EventMessage, feature string) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Share(here, feature, true)
+}
+
+/////////////
+//         //
+// unshare //
+//         //
+/////////////
+
+var AdvancedUnshare = advancedUnshare{}
+
+type advancedUnshare struct{}
+
+func (c advancedUnshare) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedUnshare) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedUnshare) Names() []string {
+	return []string{
+		"unshare",
+	}
+}
+
+func (advancedUnshare) Description() string {
+	return "Stop sharing a feature, every place goes back to its own data."
+}
+
+func (advancedUnshare) UsageArgs() string {
+	return AdvancedShare.UsageArgs()
+}
+
+func (c advancedUnshare) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedUnshare) Examples() []string {
+	return []string{
+		core.ShareReminders,
+	}
+}
+
+func (advancedUnshare) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedUnshare) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedUnshare) Init() error {
+	return nil
+}
+
+func (c advancedUnshare) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	feature := strings.ToLower(m.Command.Args[0])
+	urr, err := c.core(m, feature)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return fmt.Sprintf("Stopped sharing %s.", feature), nil, nil
+}
+
+func (advancedUnshare) core(m *core.EventMessage, feature string) (core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Share(here, feature, false)
+}
@@ -0,0 +1,437 @@
+package group
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrInGroup        = core.UrrNew("this place is already part of a group")
+	UrrNotInGroup     = core.UrrNew("this place isn't part of a group")
+	UrrNotAuthority   = core.UrrNew("only the place that created the group can do this")
+	UrrInvalidCode    = core.UrrNew("the code is invalid or has expired")
+	UrrInvalidFeature = core.UrrNew("unknown feature")
+)
+
+// How long an invite code lasts.
+const inviteExpiry = 10 * time.Minute
+
+const inviteCodeLength = 8
+
+func inviteKey(code string) string {
+	return "cmd_group-invite-" + code
+}
+
+type group struct {
+	ID        int64
+	Authority int64
+	// Includes the authority, which is always first.
+	Members []int64
+	Shared  []string
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+// Returns the ID of the group the place is in, if it's not in one then it
+// returns false.
+func dbGroupOf(place int64) (int64, bool, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var grp int64
+	err := db.DB.QueryRow(`
+		SELECT grp
+		FROM place_group_members
+		WHERE place = $1
+	`, place).Scan(&grp)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int64("group", grp).
+		Msg("got place's group")
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, false, nil
+	}
+	return grp, err == nil, err
+}
+
+func dbGet(grp int64) (group, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	g := group{ID: grp}
+
+	err := db.DB.QueryRow(`
+		SELECT authority
+		FROM place_groups
+		WHERE id = $1
+	`, grp).Scan(&g.Authority)
+	if err != nil {
+		return g, err
+	}
+	g.Members = []int64{g.Authority}
+
+	rows, err := db.DB.Query(`
+		SELECT place
+		FROM place_group_members
+		WHERE grp = $1 AND place != $2
+		ORDER BY place
+	`, grp, g.Authority)
+	if err != nil {
+		return g, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var place int64
+		if err := rows.Scan(&place); err != nil {
+			return g, err
+		}
+		g.Members = append(g.Members, place)
+	}
+	if err := rows.Err(); err != nil {
+		return g, err
+	}
+
+	rows, err = db.DB.Query(`
+		SELECT feature
+		FROM place_group_features
+		WHERE grp = $1
+		ORDER BY feature
+	`, grp)
+	if err != nil {
+		return g, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feature string
+		if err := rows.Scan(&feature); err != nil {
+			return g, err
+		}
+		g.Shared = append(g.Shared, feature)
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Interface("group", g).
+		Msg("got place group")
+
+	return g, err
+}
+
+func dbCreate(authority int64) (int64, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return -1, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	var grp int64
+	err = tx.QueryRow(`
+		INSERT INTO place_groups (authority)
+		VALUES ($1)
+		RETURNING id
+	`, authority).Scan(&grp)
+	if err != nil {
+		return -1, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO place_group_members (place, grp)
+		VALUES ($1, $2)
+	`, authority, grp)
+
+	log.Debug().
+		Err(err).
+		Int64("authority", authority).
+		Int64("group", grp).
+		Msg("created place group")
+
+	if err != nil {
+		return -1, err
+	}
+	return grp, tx.Commit()
+}
+
+func dbJoin(grp, place int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO place_group_members (place, grp)
+		VALUES ($1, $2)
+	`, place, grp)
+
+	log.Debug().
+		Err(err).
+		Int64("group", grp).
+		Int64("place", place).
+		Msg("place joined group")
+
+	return err
+}
+
+func dbLeave(place int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		DELETE FROM place_group_members
+		WHERE place = $1
+	`, place)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Msg("place left group")
+
+	return err
+}
+
+// Deletes the group, the members and shared features are deleted along with
+// it.
+func dbDelete(grp int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		DELETE FROM place_groups
+		WHERE id = $1
+	`, grp)
+
+	log.Debug().
+		Err(err).
+		Int64("group", grp).
+		Msg("deleted place group")
+
+	return err
+}
+
+func dbShare(grp int64, feature string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO place_group_features (grp, feature)
+		VALUES ($1, $2)
+		ON CONFLICT (grp, feature) DO NOTHING
+	`, grp, feature)
+
+	log.Debug().
+		Err(err).
+		Int64("group", grp).
+		Str("feature", feature).
+		Msg("shared feature")
+
+	return err
+}
+
+func dbUnshare(grp int64, feature string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		DELETE FROM place_group_features
+		WHERE grp = $1 AND feature = $2
+	`, grp, feature)
+
+	log.Debug().
+		Err(err).
+		Int64("group", grp).
+		Str("feature", feature).
+		Msg("unshared feature")
+
+	return err
+}
+
+/////////
+//     //
+// run //
+//     //
+/////////
+
+// Returns the group that the place is the authority of.
+func authorityGroup(place int64) (group, core.Urr, error) {
+	grp, ok, err := dbGroupOf(place)
+	if err != nil {
+		return group{}, nil, err
+	}
+	if !ok {
+		return group{}, UrrNotInGroup, nil
+	}
+	g, err := dbGet(grp)
+	if err != nil {
+		return group{}, nil, err
+	}
+	if g.Authority != place {
+		return group{}, UrrNotAuthority, nil
+	}
+	return g, nil, nil
+}
+
+// Create creates a new group with the place as its authority. Nothing is
+// shared by default.
+func Create(place int64) (core.Urr, error) {
+	_, ok, err := dbGroupOf(place)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return UrrInGroup, nil
+	}
+	_, err = dbCreate(place)
+	return nil, err
+}
+
+// Invite returns a code which can be used by another place to join the group.
+// Only the group's authority can invite.
+func Invite(place int64) (string, core.Urr, error) {
+	g, urr, err := authorityGroup(place)
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+
+	code, err := core.GenerateCode(inviteCodeLength)
+	if err != nil {
+		return "", nil, err
+	}
+	err = core.RDB.Set(context.Background(), inviteKey(code), g.ID, inviteExpiry).Err()
+	return code, nil, err
+}
+
+// Join adds the place to the group that the invite code belongs to.
+func Join(place int64, code string) (core.Urr, error) {
+	_, ok, err := dbGroupOf(place)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return UrrInGroup, nil
+	}
+
+	grp, err := core.RDB.GetDel(context.Background(), inviteKey(code)).Int64()
+	if err == redis.Nil {
+		return UrrInvalidCode, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, dbJoin(grp, place)
+}
+
+// Leave removes the place from its group. If the place is the group's
+// authority, then the whole group is deleted, since there is nowhere for the
+// shared data to come from anymore. Returns true if the group was deleted.
+func Leave(place int64) (bool, core.Urr, error) {
+	grp, ok, err := dbGroupOf(place)
+	if err != nil {
+		return false, nil, err
+	}
+	if !ok {
+		return false, UrrNotInGroup, nil
+	}
+	g, err := dbGet(grp)
+	if err != nil {
+		return false, nil, err
+	}
+	if g.Authority == place {
+		return true, nil, dbDelete(grp)
+	}
+	return false, nil, dbLeave(place)
+}
+
+// Share turns sharing the feature on or off for the whole group, the feature
+// must be one of core.ShareFeatures. Only the group's authority can change
+// what is shared.
+func Share(place int64, feature string, on bool) (core.Urr, error) {
+	if !slices.Contains(core.ShareFeatures, feature) {
+		return UrrInvalidFeature, nil
+	}
+	g, urr, err := authorityGroup(place)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	if on {
+		return nil, dbShare(g.ID, feature)
+	}
+	return nil, dbUnshare(g.ID, feature)
+}
+
+// Show returns the group that the place is in.
+func Show(place int64) (group, core.Urr, error) {
+	grp, ok, err := dbGroupOf(place)
+	if err != nil {
+		return group{}, nil, err
+	}
+	if !ok {
+		return group{}, UrrNotInGroup, nil
+	}
+	g, err := dbGet(grp)
+	return g, nil, err
+}
+
+// Returns the place's name along with the frontend it's on.
+func placeName(place int64) (string, error) {
+	frontendType, err := core.DB.ScopeFrontend(place)
+	if err != nil {
+		return "", err
+	}
+	id, err := core.DB.ScopeID(place)
+	if err != nil {
+		return "", err
+	}
+
+	switch core.FrontendType(frontendType) {
+	case discord.Frontend.Type():
+		g, err := discord.Client.Session.Guild(id)
+		if err != nil {
+			// could be a DM, in which case there is no guild
+			return fmt.Sprintf("%s (%s)", id, discord.Frontend.Name()), nil
+		}
+		return fmt.Sprintf("%s (%s)", g.Name, discord.Frontend.Name()), nil
+	case twitch.Frontend.Type():
+		hx, err := twitch.Frontend.Helix()
+		if err != nil {
+			return "", err
+		}
+		u, err := hx.GetUser(id)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s (%s)", u.DisplayName, twitch.Frontend.Name()), nil
+	default:
+		return id, nil
+	}
+}
@@ -0,0 +1,168 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	UrrInvalidCode   = core.UrrNew("the code is invalid or has expired")
+	UrrSameAccount   = core.UrrNew("can't link an account with itself")
+	UrrAlreadyLinked = core.UrrNew("the accounts are already linked")
+	UrrNoPending     = core.UrrNew("there is no link waiting to be confirmed")
+	UrrNotLinked     = core.UrrNew("the account isn't linked with any other accounts")
+)
+
+// How long the codes and the pending links last before they have to be
+// created again.
+const expiry = 10 * time.Minute
+
+const codeLength = 8
+
+func codeKey(code string) string {
+	return "cmd_link-code-" + code
+}
+
+func pendingKey(person int64) string {
+	return fmt.Sprintf("cmd_link-pending-%d", person)
+}
+
+// Start is the first step of linking two accounts. It returns a code that must
+// be claimed from the other account.
+func Start(person int64) (string, error) {
+	ctx := context.Background()
+
+	code, err := core.GenerateCode(codeLength)
+	if err != nil {
+		return "", err
+	}
+	return code, core.RDB.Set(ctx, codeKey(code), person, expiry).Err()
+}
+
+// Claim is the second step of linking two accounts, the person claims the code
+// that was generated from the other account. The link still has to be
+// confirmed from the account that generated the code, since the code may have
+// been seen by anyone. Returns the person that generated the code.
+func Claim(person int64, code string) (int64, core.Urr, error) {
+	ctx := context.Background()
+
+	initiator, err := core.RDB.GetDel(ctx, codeKey(code)).Int64()
+	if err == redis.Nil {
+		return -1, UrrInvalidCode, nil
+	}
+	if err != nil {
+		return -1, nil, err
+	}
+
+	if initiator == person {
+		return -1, UrrSameAccount, nil
+	}
+
+	linked, err := core.DB.PersonLinked(person)
+	if err != nil {
+		return -1, nil, err
+	}
+	for _, p := range linked {
+		if p == initiator {
+			return -1, UrrAlreadyLinked, nil
+		}
+	}
+
+	return initiator, nil, core.RDB.Set(ctx, pendingKey(initiator), person, expiry).Err()
+}
+
+// Confirm is the last step of linking two accounts, it must be done from the
+// account that generated the code. If keepThis is true, then the person's
+// info is kept in places where both accounts have info, otherwise the other
+// account's info is kept. Returns the account that was linked.
+func Confirm(person int64, keepThis bool) (int64, core.Urr, error) {
+	ctx := context.Background()
+
+	other, err := core.RDB.GetDel(ctx, pendingKey(person)).Int64()
+	if err == redis.Nil {
+		return -1, UrrNoPending, nil
+	}
+	if err != nil {
+		return -1, nil, err
+	}
+	return other, nil, core.DB.PersonLink(person, other, keepThis)
+}
+
+// List returns all the accounts the person is linked with, including the
+// person. The account whose info is used comes first.
+func List(person int64) ([]int64, core.Urr, error) {
+	linked, err := core.DB.PersonLinked(person)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(linked) == 1 {
+		return nil, UrrNotLinked, nil
+	}
+	return linked, nil, nil
+}
+
+// Unlink removes the person from the accounts it's linked with. The shared
+// info stays with the other accounts.
+func Unlink(person int64) (core.Urr, error) {
+	unlinked, err := core.DB.PersonUnlink(person)
+	if err != nil {
+		return nil, err
+	}
+	if !unlinked {
+		return UrrNotLinked, nil
+	}
+	return nil, nil
+}
+
+// Main makes the person's account the one whose info is used by all the
+// accounts it's linked with.
+func Main(person int64) (core.Urr, error) {
+	linked, err := core.DB.PersonLinked(person)
+	if err != nil {
+		return nil, err
+	}
+	if len(linked) == 1 {
+		return UrrNotLinked, nil
+	}
+	return nil, core.DB.PersonPromote(person)
+}
+
+// Returns the name of the frontend and the name of the person's account on it.
+func accountName(person int64) (string, string, error) {
+	frontendType, err := core.DB.ScopeFrontend(person)
+	if err != nil {
+		return "", "", err
+	}
+	id, err := core.DB.ScopeID(person)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch core.FrontendType(frontendType) {
+	case discord.Frontend.Type():
+		u, err := discord.Client.Session.User(id)
+		if err != nil {
+			return "", "", err
+		}
+		return discord.Frontend.Name(), u.Username, nil
+	case twitch.Frontend.Type():
+		hx, err := twitch.Frontend.Helix()
+		if err != nil {
+			return "", "", err
+		}
+		u, err := hx.GetUser(id)
+		if err != nil {
+			return "", "", err
+		}
+		return twitch.Frontend.Name(), u.DisplayName, nil
+	default:
+		return "unknown", id, nil
+	}
+}
@@ -0,0 +1,576 @@
+package link
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var Normal = normal{}
+
+type normal struct{}
+
+func (normal) Type() core.CommandType {
+	return core.Normal
+}
+
+func (normal) Permitted(*core.EventMessage) bool {
+	return true
+}
+
+func (normal) Names() []string {
+	return []string{
+		"link",
+	}
+}
+
+func (normal) Description() string {
+	return "Link your accounts on different platforms so that they share the same info."
+}
+
+func (c normal) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (normal) Category() core.CommandCategory {
+	return core.CommandCategoryOther
+}
+
+func (normal) Examples() []string {
+	return nil
+}
+
+func (normal) Parent() core.CommandStatic {
+	return nil
+}
+
+func (normal) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		NormalStart,
+		NormalClaim,
+		NormalConfirm,
+		NormalList,
+		NormalUnlink,
+		NormalMain,
+	}
+}
+
+func (normal) Init() error {
+	return nil
+}
+
+func (normal) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////
+//       //
+// start //
+//       //
+///////////
+
+var NormalStart = normalStart{}
+
+type normalStart struct{}
+
+func (c normalStart) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalStart) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalStart) Names() []string {
+	return []string{
+		"start",
+	}
+}
+
+func (normalStart) Description() string {
+	return "Get a code to enter from your other account."
+}
+
+func (normalStart) UsageArgs() string {
+	return ""
+}
+
+func (c normalStart) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalStart) Examples() []string {
+	return nil
+}
+
+func (normalStart) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalStart) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalStart) Init() error {
+	return nil
+}
+
+func (c normalStart) Run(m *core.EventMessage) (any, core.Urr, error) {
+	code, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	claim := core.FormatQuote(NormalClaim, m.Command.Prefix, m.Client)
+	return fmt.Sprintf("From your other account run %s with the code %s within %d minutes.",
+		claim, code, int(expiry.Minutes())), nil, nil
+}
+
+func (normalStart) core(m *core.EventMessage) (string, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return "", err
+	}
+	return Start(author)
+}
+
+///////////
+//       //
+// claim //
+//       //
+///////////
+
+var NormalClaim = normalClaim{}
+
+type normalClaim struct{}
+
+func (c normalClaim) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalClaim) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalClaim) Names() []string {
+	return []string{
+		"claim",
+		"code",
+	}
+}
+
+func (normalClaim) Description() string {
+	return "Enter the code you got from your other account."
+}
+
+func (normalClaim) UsageArgs() string {
+	return "<code>"
+}
+
+func (c normalClaim) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalClaim) Examples() []string {
+	return []string{
+		"K7MPX2QD",
+	}
+}
+
+func (normalClaim) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalClaim) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalClaim) Init() error {
+	return nil
+}
+
+func (c normalClaim) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(urr, m), urr, nil
+}
+
+func (normalClaim) fmt(urr core.Urr, m *core.EventMessage) string {
+	switch urr {
+	case nil:
+		confirm := core.FormatQuote(NormalConfirm, m.Command.Prefix, m.Client)
+		return fmt.Sprintf("Almost done, run %s from your other account to finish linking.", confirm)
+	case UrrInvalidCode:
+		return "The code is invalid or has expired."
+	case UrrSameAccount:
+		return "The code has to be entered from a different account."
+	case UrrAlreadyLinked:
+		return "These accounts are already linked."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (normalClaim) core(m *core.EventMessage) (core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return nil, err
+	}
+	code := strings.ToUpper(m.Command.Args[0])
+	_, urr, err := Claim(author, code)
+	return urr, err
+}
+
+/////////////
+//         //
+// confirm //
+//         //
+/////////////
+
+var NormalConfirm = normalConfirm{}
+
+type normalConfirm struct{}
+
+func (c normalConfirm) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalConfirm) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalConfirm) Names() []string {
+	return []string{
+		"confirm",
+	}
+}
+
+func (normalConfirm) Description() string {
+	return "Finish linking, decide whose info wins where both accounts have some."
+}
+
+func (normalConfirm) UsageArgs() string {
+	return "[this | other]"
+}
+
+func (c normalConfirm) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalConfirm) Examples() []string {
+	return []string{
+		"",
+		"other",
+	}
+}
+
+func (normalConfirm) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalConfirm) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalConfirm) Init() error {
+	return nil
+}
+
+func (c normalConfirm) Run(m *core.EventMessage) (any, core.Urr, error) {
+	keepThis := true
+	if len(m.Command.Args) > 0 {
+		switch strings.ToLower(m.Command.Args[0]) {
+		case "this":
+		case "other":
+			keepThis = false
+		default:
+			return m.Usage(), core.UrrMissingArgs, nil
+		}
+	}
+
+	other, urr, err := c.core(m, keepThis)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return c.fmt(urr, "", ""), urr, nil
+	}
+
+	frontend, name, err := accountName(other)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(nil, frontend, name), nil, nil
+}
+
+func (normalConfirm) fmt(urr core.Urr, frontend, name string) string {
+	switch urr {
+	case nil:
+		return fmt.Sprintf("Linked with %s on %s.", name, frontend)
+	case UrrNoPending:
+		return "There is nothing to confirm, the code has to be entered from your other account first."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (normalConfirm) core(m *core.EventMessage, keepThis bool) (int64, core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return -1, nil, err
+	}
+	return Confirm(author, keepThis)
+}
+
+//////////
+//      //
+// list //
+//      //
+//////////
+
+var NormalList = normalList{}
+
+type normalList struct{}
+
+func (c normalList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalList) Names() []string {
+	return core.AliasesList
+}
+
+func (normalList) Description() string {
+	return "List your linked accounts."
+}
+
+func (normalList) UsageArgs() string {
+	return ""
+}
+
+func (c normalList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalList) Examples() []string {
+	return nil
+}
+
+func (normalList) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalList) Init() error {
+	return nil
+}
+
+func (c normalList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	linked, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+
+	var accounts []string
+	for i, person := range linked {
+		frontend, name, err := accountName(person)
+		if err != nil {
+			return nil, nil, err
+		}
+		account := fmt.Sprintf("%s (%s)", name, frontend)
+		if i == 0 {
+			account += " [main]"
+		}
+		accounts = append(accounts, account)
+	}
+	return "Linked accounts: " + strings.Join(accounts, ", "), nil, nil
+}
+
+func (normalList) fmt(urr core.Urr) string {
+	switch urr {
+	case UrrNotLinked:
+		return "This account isn't linked with any other accounts."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (normalList) core(m *core.EventMessage) ([]int64, core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return nil, nil, err
+	}
+	return List(author)
+}
+
+////////////
+//        //
+// unlink //
+//        //
+////////////
+
+var NormalUnlink = normalUnlink{}
+
+type normalUnlink struct{}
+
+func (c normalUnlink) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalUnlink) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalUnlink) Names() []string {
+	return append([]string{"unlink"}, core.AliasesDelete...)
+}
+
+func (normalUnlink) Description() string {
+	return "Unlink this account, the shared info stays with your other accounts."
+}
+
+func (normalUnlink) UsageArgs() string {
+	return ""
+}
+
+func (c normalUnlink) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalUnlink) Examples() []string {
+	return nil
+}
+
+func (normalUnlink) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalUnlink) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalUnlink) Init() error {
+	return nil
+}
+
+func (c normalUnlink) Run(m *core.EventMessage) (any, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (normalUnlink) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "Unlinked this account."
+	case UrrNotLinked:
+		return "This account isn't linked with any other accounts."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (normalUnlink) core(m *core.EventMessage) (core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return nil, err
+	}
+	return Unlink(author)
+}
+
+//////////
+//      //
+// main //
+//      //
+//////////
+
+var NormalMain = normalMain{}
+
+type normalMain struct{}
+
+func (c normalMain) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c normalMain) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (normalMain) Names() []string {
+	return []string{
+		"main",
+		"primary",
+	}
+}
+
+func (normalMain) Description() string {
+	return "Make this the account that holds the shared info."
+}
+
+func (normalMain) UsageArgs() string {
+	return ""
+}
+
+func (c normalMain) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalMain) Examples() []string {
+	return nil
+}
+
+func (normalMain) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalMain) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalMain) Init() error {
+	return nil
+}
+
+func (c normalMain) Run(m *core.EventMessage) (any, core.Urr, error) {
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(urr), urr, nil
+}
+
+func (normalMain) fmt(urr core.Urr) string {
+	switch urr {
+	case nil:
+		return "This is now your main account."
+	case UrrNotLinked:
+		return "This account isn't linked with any other accounts."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (normalMain) core(m *core.EventMessage) (core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return nil, err
+	}
+	return Main(author)
+}
@@ -0,0 +1,822 @@
+package mod
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/commands/nick"
+	ctime "github.com/kvlach/janitorjeff/commands/time"
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	if m.Frontend.Type() != twitch.Type {
+		return false
+	}
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"mod",
+		"moderate",
+	}
+}
+
+func (advanced) Description() string {
+	return "Moderate the chat, every action is saved in a log."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedTimeout,
+		AdvancedBan,
+		AdvancedUnban,
+		AdvancedSlow,
+		AdvancedFollowers,
+		AdvancedEmoteOnly,
+		AdvancedClear,
+		AdvancedLog,
+	}
+}
+
+func (advanced) Init() error {
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrPersonNotFound:
+		return "Couldn't find that user."
+	case UrrInvalidDuration:
+		return "Invalid duration, expected something like 30s, 10m or 1h."
+	case UrrInvalidToggle:
+		return "Expected either on or off."
+	case UrrNoActions:
+		return "No moderation actions have been taken."
+	case UrrDurationTooShort:
+		return "The duration is too short."
+	case UrrDurationTooLong:
+		return "The duration is too long."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+// Returns the reason given after the first n arguments, if any.
+func reason(m *core.EventMessage, n int) string {
+	if len(m.Command.Args) <= n {
+		return ""
+	}
+	return strings.TrimSpace(m.RawArgs(n))
+}
+
+// Returns the place and the moderator taking the action.
+func placeModerator(m *core.EventMessage) (int64, int64, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return -1, -1, err
+	}
+	author, err := m.Author.Scope()
+	if err != nil {
+		return -1, -1, err
+	}
+	return here, author, nil
+}
+
+// Same as placeModerator but also resolves the target, which is given as the
+// first argument.
+func placeModeratorTarget(m *core.EventMessage) (int64, int64, int64, core.Urr, error) {
+	here, author, err := placeModerator(m)
+	if err != nil {
+		return -1, -1, -1, nil, err
+	}
+	target, err := nick.ParsePerson(m, here, m.Command.Args[0])
+	if err != nil {
+		return -1, -1, -1, UrrPersonNotFound, nil
+	}
+	return here, author, target, nil, nil
+}
+
+// Returns the reply for an action, done is used if it went through.
+func run(done string, urr core.Urr, err error) (any, core.Urr, error) {
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return done, nil, nil
+}
+
+/////////////
+//         //
+// timeout //
+//         //
+/////////////
+
+var AdvancedTimeout = advancedTimeout{}
+
+type advancedTimeout struct{}
+
+func (c advancedTimeout) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedTimeout) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedTimeout) Names() []string {
+	return []string{
+		"timeout",
+		"to",
+	}
+}
+
+func (advancedTimeout) Description() string {
+	return "Prevent a user from chatting for a while."
+}
+
+func (advancedTimeout) UsageArgs() string {
+	return "<user> <duration> [reason...]"
+}
+
+func (c advancedTimeout) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedTimeout) Examples() []string {
+	return []string{
+		"kvlach 10m",
+		"@kvlach 1h spamming",
+	}
+}
+
+func (advancedTimeout) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedTimeout) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedTimeout) Init() error {
+	return nil
+}
+
+func (c advancedTimeout) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	return run("Timed out the user.", urr, err)
+}
+
+func (advancedTimeout) core(m *core.EventMessage) (core.Urr, error) {
+	d, err := time.ParseDuration(m.Command.Args[1])
+	if err != nil {
+		return UrrInvalidDuration, nil
+	}
+	here, author, target, urr, err := placeModeratorTarget(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Timeout(here, author, target, d, reason(m, 2))
+}
+
+/////////
+//     //
+// ban //
+//     //
+/////////
+
+var AdvancedBan = advancedBan{}
+
+type advancedBan struct{}
+
+func (c advancedBan) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedBan) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedBan) Names() []string {
+	return []string{
+		"ban",
+	}
+}
+
+func (advancedBan) Description() string {
+	return "Permanently prevent a user from chatting."
+}
+
+func (advancedBan) UsageArgs() string {
+	return "<user> [reason...]"
+}
+
+func (c advancedBan) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedBan) Examples() []string {
+	return []string{
+		"kvlach",
+		"@kvlach hate speech",
+	}
+}
+
+func (advancedBan) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedBan) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedBan) Init() error {
+	return nil
+}
+
+func (c advancedBan) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	return run("Banned the user.", urr, err)
+}
+
+func (advancedBan) core(m *core.EventMessage) (core.Urr, error) {
+	here, author, target, urr, err := placeModeratorTarget(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Ban(here, author, target, reason(m, 1))
+}
+
+///////////
+//       //
+// unban //
+//       //
+///////////
+
+var AdvancedUnban = advancedUnban{}
+
+type advancedUnban struct{}
+
+func (c advancedUnban) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedUnban) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedUnban) Names() []string {
+	return []string{
+		"unban",
+		"untimeout",
+		"pardon",
+	}
+}
+
+func (advancedUnban) Description() string {
+	return "Remove a user's ban or timeout."
+}
+
+func (advancedUnban) UsageArgs() string {
+	return "<user> [reason...]"
+}
+
+func (c advancedUnban) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedUnban) Examples() []string {
+	return []string{
+		"kvlach",
+		"@kvlach appealed",
+	}
+}
+
+func (advancedUnban) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedUnban) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedUnban) Init() error {
+	return nil
+}
+
+func (c advancedUnban) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	return run("Unbanned the user.", urr, err)
+}
+
+func (advancedUnban) core(m *core.EventMessage) (core.Urr, error) {
+	here, author, target, urr, err := placeModeratorTarget(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Unban(here, author, target, reason(m, 1))
+}
+
+//////////
+//      //
+// slow //
+//      //
+//////////
+
+var AdvancedSlow = advancedSlow{}
+
+type advancedSlow struct{}
+
+func (c advancedSlow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedSlow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedSlow) Names() []string {
+	return []string{
+		"slow",
+		"slowmode",
+	}
+}
+
+func (advancedSlow) Description() string {
+	return "Make chatters wait between messages, from 3s up to 2m."
+}
+
+func (advancedSlow) UsageArgs() string {
+	return "(<duration> | off) [reason...]"
+}
+
+func (c advancedSlow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedSlow) Examples() []string {
+	return []string{
+		"30s",
+		"off",
+	}
+}
+
+func (advancedSlow) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedSlow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedSlow) Init() error {
+	return nil
+}
+
+func (c advancedSlow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	wait, urr, err := c.core(m)
+	if wait == 0 {
+		return run("Turned slow mode off.", urr, err)
+	}
+	return run("Turned slow mode on, chatters must wait "+wait.String()+" between messages.", urr, err)
+}
+
+func (advancedSlow) core(m *core.EventMessage) (time.Duration, core.Urr, error) {
+	var wait time.Duration
+	if arg := strings.ToLower(m.Command.Args[0]); !slices.Contains(core.AliasesOff, arg) {
+		var err error
+		if wait, err = time.ParseDuration(arg); err != nil {
+			return 0, UrrInvalidDuration, nil
+		}
+	}
+	here, author, err := placeModerator(m)
+	if err != nil {
+		return 0, nil, err
+	}
+	urr, err := Slow(here, author, wait, reason(m, 1))
+	return wait, urr, err
+}
+
+///////////////
+//           //
+// followers //
+//           //
+///////////////
+
+var AdvancedFollowers = advancedFollowers{}
+
+type advancedFollowers struct{}
+
+func (c advancedFollowers) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFollowers) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFollowers) Names() []string {
+	return []string{
+		"followers",
+		"followersonly",
+	}
+}
+
+func (advancedFollowers) Description() string {
+	return "Only allow followers to chat, optionally only those that have been following for a while."
+}
+
+func (advancedFollowers) UsageArgs() string {
+	return "(on | off | <duration>) [reason...]"
+}
+
+func (c advancedFollowers) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFollowers) Examples() []string {
+	return []string{
+		"on",
+		"10m",
+		"off",
+	}
+}
+
+func (advancedFollowers) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedFollowers) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFollowers) Init() error {
+	return nil
+}
+
+func (c advancedFollowers) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	on, d, urr, err := c.core(m)
+	switch {
+	case !on:
+		return run("Turned followers-only mode off.", urr, err)
+	case d == 0:
+		return run("Turned followers-only mode on.", urr, err)
+	default:
+		return run("Turned followers-only mode on, chatters must have been following for "+d.String()+".", urr, err)
+	}
+}
+
+func (advancedFollowers) core(m *core.EventMessage) (bool, time.Duration, core.Urr, error) {
+	on := true
+	var d time.Duration
+
+	switch arg := strings.ToLower(m.Command.Args[0]); {
+	case slices.Contains(core.AliasesOn, arg):
+	case slices.Contains(core.AliasesOff, arg):
+		on = false
+	default:
+		var err error
+		if d, err = time.ParseDuration(arg); err != nil {
+			return false, 0, UrrInvalidDuration, nil
+		}
+	}
+
+	here, author, err := placeModerator(m)
+	if err != nil {
+		return false, 0, nil, err
+	}
+	urr, err := Followers(here, author, on, d, reason(m, 1))
+	return on, d, urr, err
+}
+
+///////////////
+//           //
+// emoteonly //
+//           //
+///////////////
+
+var AdvancedEmoteOnly = advancedEmoteOnly{}
+
+type advancedEmoteOnly struct{}
+
+func (c advancedEmoteOnly) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedEmoteOnly) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedEmoteOnly) Names() []string {
+	return []string{
+		"emoteonly",
+		"emotes",
+	}
+}
+
+func (advancedEmoteOnly) Description() string {
+	return "Only allow messages that consist of emotes."
+}
+
+func (advancedEmoteOnly) UsageArgs() string {
+	return "(on | off) [reason...]"
+}
+
+func (c advancedEmoteOnly) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedEmoteOnly) Examples() []string {
+	return []string{
+		"on",
+		"off",
+	}
+}
+
+func (advancedEmoteOnly) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedEmoteOnly) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedEmoteOnly) Init() error {
+	return nil
+}
+
+func (c advancedEmoteOnly) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	on, urr, err := c.core(m)
+	return run("Turned emote-only mode "+toggle(on)+".", urr, err)
+}
+
+func (advancedEmoteOnly) core(m *core.EventMessage) (bool, core.Urr, error) {
+	var on bool
+	switch arg := strings.ToLower(m.Command.Args[0]); {
+	case slices.Contains(core.AliasesOn, arg):
+		on = true
+	case slices.Contains(core.AliasesOff, arg):
+		on = false
+	default:
+		return false, UrrInvalidToggle, nil
+	}
+
+	here, author, err := placeModerator(m)
+	if err != nil {
+		return false, nil, err
+	}
+	urr, err := EmoteOnly(here, author, on, reason(m, 1))
+	return on, urr, err
+}
+
+///////////
+//       //
+// clear //
+//       //
+///////////
+
+var AdvancedClear = advancedClear{}
+
+type advancedClear struct{}
+
+func (c advancedClear) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedClear) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedClear) Names() []string {
+	return []string{
+		"clear",
+	}
+}
+
+func (advancedClear) Description() string {
+	return "Delete every message in the chat."
+}
+
+func (advancedClear) UsageArgs() string {
+	return "[reason...]"
+}
+
+func (c advancedClear) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedClear) Examples() []string {
+	return []string{
+		"",
+		"spoilers",
+	}
+}
+
+func (advancedClear) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedClear) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedClear) Init() error {
+	return nil
+}
+
+func (c advancedClear) Run(m *core.EventMessage) (any, core.Urr, error) {
+	urr, err := c.core(m)
+	return run("Cleared the chat.", urr, err)
+}
+
+func (advancedClear) core(m *core.EventMessage) (core.Urr, error) {
+	here, author, err := placeModerator(m)
+	if err != nil {
+		return nil, err
+	}
+	return Clear(here, author, reason(m, 0))
+}
+
+/////////
+//     //
+// log //
+//     //
+/////////
+
+var AdvancedLog = advancedLog{}
+
+type advancedLog struct{}
+
+func (c advancedLog) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedLog) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedLog) Names() []string {
+	return []string{
+		"log",
+		"history",
+		"audit",
+	}
+}
+
+func (advancedLog) Description() string {
+	return "Show the latest moderation actions, optionally only those taken against a user."
+}
+
+func (advancedLog) UsageArgs() string {
+	return "[user]"
+}
+
+func (c advancedLog) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedLog) Examples() []string {
+	return []string{
+		"",
+		"kvlach",
+	}
+}
+
+func (advancedLog) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedLog) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedLog) Init() error {
+	return nil
+}
+
+func (c advancedLog) Run(m *core.EventMessage) (any, core.Urr, error) {
+	as, format, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+
+	var fmted []string
+	for _, a := range as {
+		s, err := c.fmt(a, format)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmted = append(fmted, s)
+	}
+	return strings.Join(fmted, " | "), nil, nil
+}
+
+func (advancedLog) fmt(a Action, format string) (string, error) {
+	moderator, err := Name(a.Moderator)
+	if err != nil {
+		return "", err
+	}
+
+	s := a.Action
+	if a.Target != -1 {
+		target, err := Name(a.Target)
+		if err != nil {
+			return "", err
+		}
+		s += " " + target
+	}
+	if a.Arg != "" {
+		s += " " + a.Arg
+	}
+	s += " by " + moderator
+	if a.Reason != "" {
+		s += " (" + a.Reason + ")"
+	}
+	return s + ", " + ctime.FormatTime(a.When, format), nil
+}
+
+func (advancedLog) core(m *core.EventMessage) ([]Action, string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	target := int64(-1)
+	if len(m.Command.Args) > 0 {
+		target, err = nick.ParsePerson(m, here, m.Command.Args[0])
+		if err != nil {
+			return nil, "", UrrPersonNotFound, nil
+		}
+	}
+
+	format, err := ctime.FormatGet(here)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// twitch messages are short, so only a few actions fit
+	as, urr, err := Log(here, target, 5)
+	return as, format, urr, err
+}
@@ -0,0 +1,353 @@
+package mod
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrPersonNotFound   = core.UrrNew("was unable to find user")
+	UrrInvalidDuration  = core.UrrNew("invalid duration")
+	UrrInvalidToggle    = core.UrrNew("expected on or off")
+	UrrNoActions        = core.UrrNew("no moderation actions have been taken")
+	UrrDurationTooShort = core.UrrNew("duration is too short")
+	UrrDurationTooLong  = core.UrrNew("duration is too long")
+)
+
+// The actions that can be taken, these are also what gets saved in the audit
+// log.
+const (
+	ActionTimeout   = "timeout"
+	ActionBan       = "ban"
+	ActionUnban     = "unban"
+	ActionSlow      = "slow"
+	ActionFollowers = "followers"
+	ActionEmoteOnly = "emoteonly"
+	ActionClear     = "clear"
+)
+
+// The limits twitch places on each setting.
+const (
+	TimeoutMax   = 14 * 24 * time.Hour
+	SlowMin      = 3 * time.Second
+	SlowMax      = 120 * time.Second
+	FollowersMax = 90 * 24 * time.Hour
+)
+
+// Action is an entry in the audit log.
+type Action struct {
+	ID        int64
+	Moderator int64
+	Action    string
+	// The person the action was taken against, -1 if it affected the whole
+	// chat.
+	Target int64
+	// Depends on the action, e.g. the timeout's duration or whether a chat
+	// mode was turned on or off.
+	Arg    string
+	Reason string
+	When   time.Time
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func dbAdd(a Action, place int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var target sql.NullInt64
+	if a.Target != -1 {
+		target = sql.NullInt64{Int64: a.Target, Valid: true}
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO cmd_mod_actions (place, moderator, action, target, arg, reason, time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, place, a.Moderator, a.Action, target, a.Arg, a.Reason, a.When.Unix())
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Interface("action", a).
+		Msg("added moderation action to audit log")
+
+	return err
+}
+
+// Returns the place's latest n actions, newest first. If target isn't -1 then
+// only the actions taken against them are returned.
+func dbList(place, target int64, n int) ([]Action, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, moderator, action, target, arg, reason, time
+		FROM cmd_mod_actions
+		WHERE place = $1 AND ($2 = -1 OR target = $2)
+		ORDER BY time DESC, id DESC
+		LIMIT $3
+	`, place, target, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var as []Action
+	for rows.Next() {
+		var a Action
+		var t sql.NullInt64
+		var when int64
+		err := rows.Scan(&a.ID, &a.Moderator, &a.Action, &t, &a.Arg, &a.Reason, &when)
+		if err != nil {
+			return nil, err
+		}
+		a.Target = -1
+		if t.Valid {
+			a.Target = t.Int64
+		}
+		a.When = time.Unix(when, 0).UTC()
+		as = append(as, a)
+	}
+
+	log.Debug().
+		Err(rows.Err()).
+		Int64("place", place).
+		Int64("target", target).
+		Int("#actions", len(as)).
+		Msg("got moderation actions")
+
+	return as, rows.Err()
+}
+
+/////////
+//     //
+// mod //
+//     //
+/////////
+
+// Returns the broadcaster's helix client along with their ID, the actions are
+// taken on the broadcaster's behalf.
+func broadcaster(place int64) (*twitch.Helix, string, error) {
+	broadcasterID, err := core.DB.ScopeID(place)
+	if err != nil {
+		return nil, "", err
+	}
+	hx, err := twitch.NewHelix(broadcasterID)
+	return hx, broadcasterID, err
+}
+
+// Takes the action using do and, if it went through, adds it to the audit
+// log.
+func take(place int64, a Action, do func(hx *twitch.Helix, broadcasterID string) (error, error)) (core.Urr, error) {
+	hx, broadcasterID, err := broadcaster(place)
+	if err != nil {
+		return nil, err
+	}
+	urr, err := do(hx, broadcasterID)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	a.When = time.Now().UTC()
+	return nil, dbAdd(a, place)
+}
+
+// Takes an action against the target, whose twitch ID is passed to do.
+func takeAgainst(place int64, a Action, do func(hx *twitch.Helix, broadcasterID, userID string) (error, error)) (core.Urr, error) {
+	userID, err := core.DB.ScopeID(a.Target)
+	if err != nil {
+		return nil, err
+	}
+	return take(place, a, func(hx *twitch.Helix, broadcasterID string) (error, error) {
+		return do(hx, broadcasterID, userID)
+	})
+}
+
+func toggle(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+// Timeout prevents the target from chatting for the given duration.
+func Timeout(place, moderator, target int64, d time.Duration, reason string) (core.Urr, error) {
+	if d < time.Second {
+		return UrrDurationTooShort, nil
+	}
+	if d > TimeoutMax {
+		return UrrDurationTooLong, nil
+	}
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionTimeout,
+		Target:    target,
+		Arg:       d.String(),
+		Reason:    reason,
+	}
+	return takeAgainst(place, a, func(hx *twitch.Helix, broadcasterID, userID string) (error, error) {
+		return hx.Ban(broadcasterID, userID, reason, d)
+	})
+}
+
+// Ban permanently prevents the target from chatting.
+func Ban(place, moderator, target int64, reason string) (core.Urr, error) {
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionBan,
+		Target:    target,
+		Reason:    reason,
+	}
+	return takeAgainst(place, a, func(hx *twitch.Helix, broadcasterID, userID string) (error, error) {
+		return hx.Ban(broadcasterID, userID, reason, 0)
+	})
+}
+
+// Unban removes the target's ban or timeout.
+func Unban(place, moderator, target int64, reason string) (core.Urr, error) {
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionUnban,
+		Target:    target,
+		Reason:    reason,
+	}
+	return takeAgainst(place, a, func(hx *twitch.Helix, broadcasterID, userID string) (error, error) {
+		return hx.Unban(broadcasterID, userID)
+	})
+}
+
+// Slow makes chatters wait between sending messages, a wait of zero turns slow
+// mode off.
+func Slow(place, moderator int64, wait time.Duration, reason string) (core.Urr, error) {
+	on := wait != 0
+	if on && wait < SlowMin {
+		return UrrDurationTooShort, nil
+	}
+	if wait > SlowMax {
+		return UrrDurationTooLong, nil
+	}
+
+	params := &helix.UpdateChatSettingsParams{
+		SlowMode: &on,
+	}
+	arg := toggle(on)
+	if on {
+		secs := int(wait.Seconds())
+		params.SlowModeWaitTime = &secs
+		arg = wait.String()
+	}
+
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionSlow,
+		Target:    -1,
+		Arg:       arg,
+		Reason:    reason,
+	}
+	return take(place, a, func(hx *twitch.Helix, broadcasterID string) (error, error) {
+		params.BroadcasterID = broadcasterID
+		return hx.ChatSettingsUpdate(params)
+	})
+}
+
+// Followers turns followers-only mode on or off. When on, chatters must have
+// been following for at least the given duration.
+func Followers(place, moderator int64, on bool, d time.Duration, reason string) (core.Urr, error) {
+	if d < 0 {
+		return UrrDurationTooShort, nil
+	}
+	if d > FollowersMax {
+		return UrrDurationTooLong, nil
+	}
+
+	params := &helix.UpdateChatSettingsParams{
+		FollowerMode: &on,
+	}
+	arg := toggle(on)
+	if on {
+		// twitch expects the duration in minutes
+		mins := int(d.Minutes())
+		params.FollowerModeDuration = &mins
+		arg = d.String()
+	}
+
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionFollowers,
+		Target:    -1,
+		Arg:       arg,
+		Reason:    reason,
+	}
+	return take(place, a, func(hx *twitch.Helix, broadcasterID string) (error, error) {
+		params.BroadcasterID = broadcasterID
+		return hx.ChatSettingsUpdate(params)
+	})
+}
+
+// EmoteOnly turns emote-only mode on or off.
+func EmoteOnly(place, moderator int64, on bool, reason string) (core.Urr, error) {
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionEmoteOnly,
+		Target:    -1,
+		Arg:       toggle(on),
+		Reason:    reason,
+	}
+	return take(place, a, func(hx *twitch.Helix, broadcasterID string) (error, error) {
+		return hx.ChatSettingsUpdate(&helix.UpdateChatSettingsParams{
+			BroadcasterID: broadcasterID,
+			EmoteMode:     &on,
+		})
+	})
+}
+
+// Clear deletes every message in the chat.
+func Clear(place, moderator int64, reason string) (core.Urr, error) {
+	a := Action{
+		Moderator: moderator,
+		Action:    ActionClear,
+		Target:    -1,
+		Reason:    reason,
+	}
+	return take(place, a, func(hx *twitch.Helix, broadcasterID string) (error, error) {
+		return hx.ChatClear(broadcasterID)
+	})
+}
+
+// Log returns the latest n actions taken in the place, newest first. If target
+// isn't -1 then only the actions taken against them are returned.
+func Log(place, target int64, n int) ([]Action, core.Urr, error) {
+	as, err := dbList(place, target, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(as) == 0 {
+		return nil, UrrNoActions, nil
+	}
+	return as, nil, nil
+}
+
+// Name returns the person's twitch username, used when showing the audit log.
+func Name(person int64) (string, error) {
+	id, err := core.DB.ScopeID(person)
+	if err != nil {
+		return "", err
+	}
+	hx, err := twitch.Frontend.Helix()
+	if err != nil {
+		return "", err
+	}
+	u, err := hx.GetUser(id)
+	return u.Login, err
+}
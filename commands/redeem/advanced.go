@@ -0,0 +1,590 @@
+package redeem
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"redeem",
+		"reward",
+	}
+}
+
+func (advanced) Description() string {
+	return "Make channel point rewards do things when they are redeemed."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedBind,
+		AdvancedUnbind,
+		AdvancedList,
+		AdvancedPolicy,
+	}
+}
+
+func (advanced) Init() error {
+	core.EventRedeemClaimHooks.Register(dispatch)
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrNotTwitch:
+		return "Channel point rewards only exist on Twitch."
+	case UrrInvalidReward:
+		return "Invalid reward ID."
+	case UrrUnknownAction:
+		return "Unknown action, expected one of: " + strings.Join(Actions, ", ")
+	case UrrInvalidDuration:
+		return "Expected a duration like 30s, 10m or 1h30m."
+	case UrrNoRelay:
+		return "This channel has to be relayed to a Discord channel first."
+	case UrrNoDiscordAccount:
+		return "Link your Discord account first, the sound is played in the voice channel you are in."
+	case UrrNoBindings:
+		return "No rewards have been bound to anything."
+	case UrrBindingNotFound:
+		return "Couldn't find a binding with that ID."
+	case UrrInvalidID:
+		return "Expected a binding ID, see the list of bindings for them."
+	case UrrCommandNotFound:
+		return "Couldn't find a custom command with that trigger."
+	case core.UrrUnknownRedeemPolicy:
+		return "Unknown policy, expected one of: " + strings.Join(core.RedeemPolicies, ", ")
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+//////////
+//      //
+// bind //
+//      //
+//////////
+
+var AdvancedBind = advancedBind{}
+
+type advancedBind struct{}
+
+func (c advancedBind) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedBind) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedBind) Names() []string {
+	return []string{
+		"bind",
+	}
+}
+
+func (advancedBind) Description() string {
+	return "Run an action every time the reward is redeemed. $(user) and $(input) are replaced with the redeemer and their text."
+}
+
+func (advancedBind) UsageArgs() string {
+	return "<reward-id> (command <trigger> | sound <url> | timer <duration> [message] | discord <message>)"
+}
+
+func (c advancedBind) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedBind) Examples() []string {
+	return []string{
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 command hydrate",
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 timer 10m $(user) it's time to stretch",
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 discord $(user) says: $(input)",
+	}
+}
+
+func (advancedBind) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedBind) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedBind) Init() error {
+	return nil
+}
+
+func (c advancedBind) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 3 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return fmt.Sprintf("Bound the reward, the binding's ID is #%d.", id), nil, nil
+}
+
+func (advancedBind) core(m *core.EventMessage) (int64, core.Urr, error) {
+	if m.Frontend.Type() != twitch.Frontend.Type() {
+		return -1, UrrNotTwitch, nil
+	}
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return -1, nil, err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return -1, nil, err
+	}
+
+	reward := m.Command.Args[0]
+	action := strings.ToLower(m.Command.Args[1])
+	arg := strings.TrimSpace(m.RawArgs(2))
+	return Bind(here, author, reward, action, arg)
+}
+
+////////////
+//        //
+// unbind //
+//        //
+////////////
+
+var AdvancedUnbind = advancedUnbind{}
+
+type advancedUnbind struct{}
+
+func (c advancedUnbind) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedUnbind) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedUnbind) Names() []string {
+	return append([]string{"unbind"}, core.AliasesDelete...)
+}
+
+func (advancedUnbind) Description() string {
+	return "Stop running an action when its reward is redeemed."
+}
+
+func (advancedUnbind) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedUnbind) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedUnbind) Examples() []string {
+	return []string{
+		"4",
+	}
+}
+
+func (advancedUnbind) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedUnbind) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedUnbind) Init() error {
+	return nil
+}
+
+func (c advancedUnbind) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Removed the binding.", nil, nil
+}
+
+func (advancedUnbind) core(m *core.EventMessage) (core.Urr, error) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(m.Command.Args[0], "#"), 10, 64)
+	if err != nil {
+		return UrrInvalidID, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, err
+	}
+	return Unbind(id, here)
+}
+
+//////////
+//      //
+// list //
+//      //
+//////////
+
+var AdvancedList = advancedList{}
+
+type advancedList struct{}
+
+func (c advancedList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedList) Description() string {
+	return "List the rewards that are bound to actions."
+}
+
+func (advancedList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedList) Examples() []string {
+	return nil
+}
+
+func (advancedList) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedList) Init() error {
+	return nil
+}
+
+func (c advancedList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	bs, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+
+	var fmted []string
+	for _, b := range bs {
+		fmted = append(fmted, fmt.Sprintf("#%d %s -> %s %s", b.ID, b.Reward, b.Action, b.Arg))
+	}
+	return strings.Join(fmted, " | "), nil, nil
+}
+
+func (advancedList) core(m *core.EventMessage) ([]binding, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	return List(here)
+}
+
+////////////
+//        //
+// policy //
+//        //
+////////////
+
+var AdvancedPolicy = advancedPolicy{}
+
+type advancedPolicy struct{}
+
+func (c advancedPolicy) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPolicy) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPolicy) Names() []string {
+	return []string{
+		"policy",
+	}
+}
+
+func (advancedPolicy) Description() string {
+	return "Control whether redemptions get fulfilled or refunded depending on how they were handled."
+}
+
+func (c advancedPolicy) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedPolicy) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPolicy) Examples() []string {
+	return nil
+}
+
+func (advancedPolicy) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedPolicy) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedPolicyShow,
+		AdvancedPolicySet,
+	}
+}
+
+func (advancedPolicy) Init() error {
+	return nil
+}
+
+func (advancedPolicy) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+/////////////////
+//             //
+// policy show //
+//             //
+/////////////////
+
+var AdvancedPolicyShow = advancedPolicyShow{}
+
+type advancedPolicyShow struct{}
+
+func (c advancedPolicyShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPolicyShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPolicyShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedPolicyShow) Description() string {
+	return "Show the current redemption policy."
+}
+
+func (advancedPolicyShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedPolicyShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPolicyShow) Examples() []string {
+	return nil
+}
+
+func (advancedPolicyShow) Parent() core.CommandStatic {
+	return AdvancedPolicy
+}
+
+func (advancedPolicyShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPolicyShow) Init() error {
+	return nil
+}
+
+func (c advancedPolicyShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	policy, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.fmt(policy), nil, nil
+}
+
+func (advancedPolicyShow) fmt(policy string) string {
+	switch policy {
+	case core.RedeemPolicyAuto:
+		return "Successful redemptions are fulfilled and failed ones are refunded."
+	case core.RedeemPolicyRefund:
+		return "Failed redemptions are refunded, successful ones are left in the queue."
+	default:
+		return "Redemptions are left in the queue."
+	}
+}
+
+func (advancedPolicyShow) core(m *core.EventMessage) (string, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+	return core.RedeemPolicyGet(here)
+}
+
+////////////////
+//            //
+// policy set //
+//            //
+////////////////
+
+var AdvancedPolicySet = advancedPolicySet{}
+
+type advancedPolicySet struct{}
+
+func (c advancedPolicySet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedPolicySet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedPolicySet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedPolicySet) Description() string {
+	return "Set what happens to redemptions: auto fulfils or refunds them, refund only refunds failed ones, off leaves them alone."
+}
+
+func (advancedPolicySet) UsageArgs() string {
+	return "(auto | refund | off)"
+}
+
+func (c advancedPolicySet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedPolicySet) Examples() []string {
+	return []string{
+		"auto",
+		"off",
+	}
+}
+
+func (advancedPolicySet) Parent() core.CommandStatic {
+	return AdvancedPolicy
+}
+
+func (advancedPolicySet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedPolicySet) Init() error {
+	return nil
+}
+
+func (c advancedPolicySet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	policy := strings.ToLower(m.Command.Args[0])
+	unmanageable, urr, err := c.core(m, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return c.fmt(policy, unmanageable), nil, nil
+}
+
+func (advancedPolicySet) fmt(policy string, unmanageable []helix.ChannelCustomReward) string {
+	resp := AdvancedPolicyShow.fmt(policy)
+	if len(unmanageable) == 0 {
+		return resp
+	}
+	var titles []string
+	for _, r := range unmanageable {
+		titles = append(titles, r.Title)
+	}
+	return resp + " The policy doesn't apply to " + strings.Join(titles, ", ") +
+		" since only rewards created by the bot can be managed, recreate them with the bot instead."
+}
+
+func (advancedPolicySet) core(m *core.EventMessage, policy string) ([]helix.ChannelCustomReward, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+	urr, err := core.RedeemPolicySet(here, policy)
+	if urr != nil || err != nil {
+		return nil, urr, err
+	}
+	if policy == core.RedeemPolicyOff || m.Frontend.Type() != twitch.Frontend.Type() {
+		return nil, nil, nil
+	}
+
+	broadcasterID, err := m.Here.IDExact()
+	if err != nil {
+		return nil, nil, err
+	}
+	// the policy has already been set, so failing to check shouldn't make it
+	// look like it wasn't
+	unmanageable, err := Unmanageable(here, broadcasterID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check which rewards can be managed")
+		return nil, nil, nil
+	}
+	return unmanageable, nil, nil
+}
@@ -0,0 +1,560 @@
+package redeem
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/commands/audio"
+	"github.com/kvlach/janitorjeff/commands/custom-command"
+	"github.com/kvlach/janitorjeff/commands/god"
+	"github.com/kvlach/janitorjeff/commands/relay"
+	"github.com/kvlach/janitorjeff/commands/streak"
+	ctime "github.com/kvlach/janitorjeff/commands/time"
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/google/uuid"
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrNotTwitch        = core.UrrNew("channel point rewards only exist on twitch")
+	UrrInvalidReward    = core.UrrNew("invalid reward ID")
+	UrrUnknownAction    = core.UrrNew("unknown action")
+	UrrInvalidDuration  = core.UrrNew("invalid timer duration")
+	UrrNoRelay          = core.UrrNew("this channel isn't relayed to any discord channel")
+	UrrNoDiscordAccount = core.UrrNew("no discord account is linked with this account")
+	UrrNoBindings       = core.UrrNew("there are no bindings")
+	UrrBindingNotFound  = core.UrrNew("couldn't find the binding")
+	UrrInvalidID        = core.UrrNew("invalid binding ID")
+	UrrCommandNotFound  = core.UrrNew("couldn't find the custom command")
+)
+
+// The actions that a reward can be bound to.
+const (
+	// Runs a custom command, the redeem's input is passed as its arguments.
+	ActionCommand = "command"
+	// Plays audio in the discord voice channel that the binding's creator is
+	// connected to.
+	ActionSound = "sound"
+	// Reminds the person who redeemed the reward after some time.
+	ActionTimer = "timer"
+	// Posts a message in the discord channels the place is relayed to.
+	ActionDiscord = "discord"
+)
+
+var Actions = []string{
+	ActionCommand,
+	ActionSound,
+	ActionTimer,
+	ActionDiscord,
+}
+
+type binding struct {
+	// Fields are public so that they show up in the debug logs
+	ID      int64
+	Place   int64
+	Reward  uuid.UUID
+	Action  string
+	Arg     string
+	Creator int64
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func scanBindings(rows *sql.Rows) ([]binding, error) {
+	var bs []binding
+	for rows.Next() {
+		var b binding
+		err := rows.Scan(&b.ID, &b.Place, &b.Reward, &b.Action, &b.Arg, &b.Creator)
+		if err != nil {
+			return nil, err
+		}
+		bs = append(bs, b)
+	}
+	return bs, rows.Err()
+}
+
+func dbAdd(place, creator int64, reward uuid.UUID, action, arg string) (int64, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var id int64
+	err := db.DB.QueryRow(`
+		INSERT INTO cmd_redeem_bindings (place, reward, action, arg, creator)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, place, reward, action, arg, creator).Scan(&id)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("reward", reward.String()).
+		Str("action", action).
+		Str("arg", arg).
+		Int64("creator", creator).
+		Int64("id", id).
+		Msg("added redeem binding")
+
+	return id, err
+}
+
+func dbList(place int64) ([]binding, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, place, reward, action, arg, creator
+		FROM cmd_redeem_bindings
+		WHERE place = $1
+		ORDER BY id
+	`, place)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bs, err := scanBindings(rows)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int("#bindings", len(bs)).
+		Msg("got redeem bindings")
+
+	return bs, err
+}
+
+func dbForReward(place int64, reward string) ([]binding, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, place, reward, action, arg, creator
+		FROM cmd_redeem_bindings
+		WHERE place = $1 AND reward = $2
+		ORDER BY id
+	`, place, reward)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bs, err := scanBindings(rows)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("reward", reward).
+		Int("#bindings", len(bs)).
+		Msg("got reward's bindings")
+
+	return bs, err
+}
+
+// Returns false if the binding doesn't exist in the place.
+func dbDelete(id, place int64) (bool, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	res, err := db.DB.Exec(`
+		DELETE FROM cmd_redeem_bindings
+		WHERE id = $1 AND place = $2
+	`, id, place)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int64("place", place).
+		Int64("deleted", n).
+		Msg("deleted redeem binding")
+
+	return n > 0, err
+}
+
+/////////
+//     //
+// run //
+//     //
+/////////
+
+// Splits a timer's argument into its duration and message.
+func parseTimer(arg string) (time.Duration, string, core.Urr) {
+	dur, msg, _ := strings.Cut(arg, " ")
+	d, err := time.ParseDuration(dur)
+	if err != nil || d <= 0 {
+		return 0, "", UrrInvalidDuration
+	}
+	return d, strings.TrimSpace(msg), nil
+}
+
+// Returns the discord account that the person is linked with.
+func discordAccount(person int64) (int64, core.Urr, error) {
+	linked, err := core.DB.PersonLinked(person)
+	if err != nil {
+		return -1, nil, err
+	}
+	for _, p := range linked {
+		f, err := core.DB.ScopeFrontend(p)
+		if err != nil {
+			return -1, nil, err
+		}
+		if core.FrontendType(f) == discord.Frontend.Type() {
+			return p, nil, nil
+		}
+	}
+	return -1, UrrNoDiscordAccount, nil
+}
+
+// Returns UrrNoRelay if the place isn't relayed to any discord channel.
+func relayed(place int64) (core.Urr, error) {
+	chs, err := relay.Discord(place)
+	if err != nil {
+		return nil, err
+	}
+	if len(chs) == 0 {
+		return UrrNoRelay, nil
+	}
+	return nil, nil
+}
+
+// Returns UrrCommandNotFound if the trigger isn't a custom command in the
+// place.
+func commandExists(place int64, trigger string) (core.Urr, error) {
+	place, err := core.DB.PlaceShared(place, core.ShareCustomCommands)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := custom_command.Exists(place, trigger)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return UrrCommandNotFound, nil
+	}
+	return nil, nil
+}
+
+// Bind makes the action run every time the reward is redeemed in the place.
+// What arg is depends on the action:
+//
+//   - command: the custom command's trigger
+//   - sound: a URL or a search
+//   - timer: a duration followed by an optional message, e.g. 10m stretch
+//   - discord: the message to post
+//
+// Messages may contain $(user) and $(input) which are replaced with the
+// redeemer's name and the text they entered respectively.
+func Bind(place, creator int64, reward, action, arg string) (int64, core.Urr, error) {
+	u, err := uuid.Parse(reward)
+	if err != nil {
+		return -1, UrrInvalidReward, nil
+	}
+
+	switch action {
+	case ActionCommand:
+		if urr, err := commandExists(place, arg); urr != nil || err != nil {
+			return -1, urr, err
+		}
+	case ActionTimer:
+		if _, _, urr := parseTimer(arg); urr != nil {
+			return -1, urr, nil
+		}
+	case ActionSound:
+		// the creator's discord account is needed to know which voice channel
+		// to join
+		if _, urr, err := discordAccount(creator); urr != nil || err != nil {
+			return -1, urr, err
+		}
+		if urr, err := relayed(place); urr != nil || err != nil {
+			return -1, urr, err
+		}
+	case ActionDiscord:
+		if urr, err := relayed(place); urr != nil || err != nil {
+			return -1, urr, err
+		}
+	default:
+		return -1, UrrUnknownAction, nil
+	}
+
+	id, err := dbAdd(place, creator, u, action, arg)
+	return id, nil, err
+}
+
+// Unbind deletes the binding from the place.
+func Unbind(id, place int64) (core.Urr, error) {
+	ok, err := dbDelete(id, place)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return UrrBindingNotFound, nil
+	}
+	return nil, nil
+}
+
+// List returns all the bindings in the place.
+func List(place int64) ([]binding, core.Urr, error) {
+	bs, err := dbList(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bs) == 0 {
+		return nil, UrrNoBindings, nil
+	}
+	return bs, nil, nil
+}
+
+// Returns the IDs of the rewards whose redemptions are handled in the place,
+// either through a binding or by being the god or the streak redeem.
+func handled(place int64) ([]string, error) {
+	bs, err := dbList(place)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, b := range bs {
+		ids = append(ids, b.Reward.String())
+	}
+
+	for _, get := range []func(int64) (uuid.UUID, core.Urr, error){god.RedeemGet, streak.RedeemGet} {
+		u, urr, err := get(place)
+		if err != nil {
+			return nil, err
+		}
+		if urr == nil {
+			ids = append(ids, u.String())
+		}
+	}
+	return ids, nil
+}
+
+// Unmanageable returns the rewards that are handled in the place, but whose
+// redemptions can't be fulfilled or refunded because the rewards weren't
+// created by the bot, e.g. they were created from the dashboard. The
+// broadcaster must have connected their account to the bot.
+func Unmanageable(place int64, broadcasterID string) ([]helix.ChannelCustomReward, error) {
+	ids, err := handled(place)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := hx.RedeemsList(broadcasterID)
+	if err == twitch.ErrNoResults {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manageable, err := hx.RedeemsManageable(broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+
+	var unmanageable []helix.ChannelCustomReward
+	for _, r := range rs {
+		if !slices.Contains(ids, r.ID) {
+			continue
+		}
+		if slices.ContainsFunc(manageable, func(m helix.ChannelCustomReward) bool { return m.ID == r.ID }) {
+			continue
+		}
+		unmanageable = append(unmanageable, r)
+	}
+	return unmanageable, nil
+}
+
+// Replaces the variables in the text with the redeem's info.
+func fill(text string, rc *core.EventRedeemClaim) (string, error) {
+	name, err := rc.Author.DisplayName()
+	if err != nil {
+		return "", err
+	}
+	return strings.NewReplacer(
+		"$(user)", name,
+		"$(input)", rc.Input,
+	).Replace(text), nil
+}
+
+// Runs all the actions bound to the claimed reward.
+func dispatch(rc *core.EventRedeemClaim) {
+	place, err := rc.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get place scope")
+		return
+	}
+
+	bs, err := dbForReward(place, rc.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get reward's bindings")
+		return
+	}
+
+	if len(bs) == 0 {
+		return
+	}
+
+	failed := false
+	for _, b := range bs {
+		if err := run(b, rc); err != nil {
+			log.Error().
+				Err(err).
+				Interface("binding", b).
+				Msg("failed to run redeem action")
+			failed = true
+		}
+	}
+
+	if failed {
+		rc.Refund()
+	} else {
+		rc.Fulfil()
+	}
+}
+
+func run(b binding, rc *core.EventRedeemClaim) error {
+	switch b.Action {
+	case ActionCommand:
+		return runCommand(b, rc)
+	case ActionSound:
+		return runSound(b)
+	case ActionTimer:
+		return runTimer(b, rc)
+	case ActionDiscord:
+		return runDiscord(b, rc)
+	default:
+		return fmt.Errorf("unknown action %s", b.Action)
+	}
+}
+
+func runCommand(b binding, rc *core.EventRedeemClaim) error {
+	place, err := core.DB.PlaceShared(b.Place, core.ShareCustomCommands)
+	if err != nil {
+		return err
+	}
+
+	resp, urr, err := custom_command.Run(place, rc.Author, b.Arg, strings.Fields(rc.Input))
+	if err != nil {
+		return err
+	}
+	// the command didn't run, e.g. it's on cooldown or the viewer's input
+	// doesn't have the right number of arguments, so the redemption must be
+	// refunded
+	if urr != nil {
+		return urr
+	}
+
+	person, err := rc.Author.Scope()
+	if err != nil {
+		return err
+	}
+	m, err := core.Frontends.CreateMessage(person, b.Place, "")
+	if err != nil {
+		return err
+	}
+	_, err = m.Write(resp, nil)
+	return err
+}
+
+func runSound(b binding) error {
+	chs, err := relay.Discord(b.Place)
+	if err != nil {
+		return err
+	}
+	if len(chs) == 0 {
+		return UrrNoRelay
+	}
+
+	person, urr, err := discordAccount(b.Creator)
+	if err != nil {
+		return err
+	}
+	if urr != nil {
+		return urr
+	}
+
+	// the bot joins whatever voice channel the binding's creator is in
+	m, err := core.Frontends.CreateMessage(person, chs[0], "")
+	if err != nil {
+		return err
+	}
+	guild, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+
+	_, urr, err = audio.Play(strings.Fields(b.Arg), m.Speaker, guild)
+	if err != nil {
+		return err
+	}
+	if urr != nil {
+		return urr
+	}
+	return nil
+}
+
+func runTimer(b binding, rc *core.EventRedeemClaim) error {
+	d, what, urr := parseTimer(b.Arg)
+	if urr != nil {
+		return urr
+	}
+	if what == "" {
+		what = "Time's up!"
+	}
+	what, err := fill(what, rc)
+	if err != nil {
+		return err
+	}
+
+	person, err := rc.Author.Scope()
+	if err != nil {
+		return err
+	}
+	_, _, urr, err = ctime.RemindAfter(d, what, "", person, b.Place, b.Place)
+	if err != nil {
+		return err
+	}
+	if urr != nil {
+		return urr
+	}
+	return nil
+}
+
+func runDiscord(b binding, rc *core.EventRedeemClaim) error {
+	text, err := fill(b.Arg, rc)
+	if err != nil {
+		return err
+	}
+
+	chs, err := relay.Discord(b.Place)
+	if err != nil {
+		return err
+	}
+	for _, ch := range chs {
+		if err := core.Frontends.Announce(ch, text, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
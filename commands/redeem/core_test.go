@@ -0,0 +1,99 @@
+package redeem
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kvlach/janitorjeff/commands/custom-command"
+	"github.com/kvlach/janitorjeff/core"
+	_ "github.com/kvlach/janitorjeff/internal/testing_init"
+	"github.com/kvlach/janitorjeff/internal/testkit"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+var msg *testkit.TestMessage
+
+// Records how the redemptions were resolved instead of contacting twitch.
+type resolver struct {
+	core.Frontender
+	resolved  bool
+	fulfilled bool
+}
+
+func (r *resolver) RedeemResolve(_ *core.EventRedeemClaim, fulfilled bool) error {
+	r.resolved = true
+	r.fulfilled = fulfilled
+	return nil
+}
+
+func TestDispatchCooldownRefund(t *testing.T) {
+	place, err := msg.Here.ScopeLogical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	person, err := msg.Author.Scope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const trigger = "!hydrate"
+	if urr, err := custom_command.Add(place, person, trigger, "drink water"); urr != nil || err != nil {
+		t.Fatalf("failed to add command: urr = %v, err = %v", urr, err)
+	}
+	if urr, err := custom_command.CooldownSet(place, trigger, time.Hour, 0); urr != nil || err != nil {
+		t.Fatalf("failed to set cooldown: urr = %v, err = %v", urr, err)
+	}
+	// puts the command on cooldown
+	if _, urr, err := custom_command.Run(place, msg.Author, trigger, nil); urr != nil || err != nil {
+		t.Fatalf("failed to run command: urr = %v, err = %v", urr, err)
+	}
+
+	reward := uuid.NewString()
+	if _, urr, err := Bind(place, person, reward, ActionCommand, trigger); urr != nil || err != nil {
+		t.Fatalf("failed to bind command: urr = %v, err = %v", urr, err)
+	}
+
+	r := &resolver{Frontender: msg.Frontend}
+	rc := core.NewEventRedeemClaim(reward, uuid.NewString(), "", time.Now(), msg.Author, msg.Here, r)
+	dispatch(rc)
+
+	if !r.resolved {
+		t.Fatal("expected the redemption to be resolved")
+	}
+	if r.fulfilled {
+		t.Fatal("expected the redemption to be refunded, got fulfilled")
+	}
+}
+
+func TestBindMissingCommand(t *testing.T) {
+	place, err := msg.Here.ScopeLogical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	person, err := msg.Author.Scope()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, urr, err := Bind(place, person, uuid.NewString(), ActionCommand, "!missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if urr != UrrCommandNotFound {
+		t.Fatalf("expected urr = %v, got %v", UrrCommandNotFound, urr)
+	}
+}
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	tdb := testkit.NewTestDB()
+	msg = testkit.NewTestMessage().DiscordRandom()
+
+	code := m.Run()
+	tdb.Delete()
+	os.Exit(code)
+}
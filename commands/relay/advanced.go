@@ -0,0 +1,551 @@
+package relay
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	admin, err := m.Author.Admin()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is admin")
+		return false
+	}
+	return admin
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"relay",
+	}
+}
+
+func (advanced) Description() string {
+	return "Mirror a twitch channel's chat into a discord channel, and optionally the other way around."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedStart,
+		AdvancedJoin,
+		AdvancedList,
+		AdvancedReverse,
+		AdvancedDelete,
+	}
+}
+
+func (advanced) Init() error {
+	core.EventMessageHooks.Register(relay)
+	discord.MessageDeleteHooks.Register(deleted)
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrInvalidCode:
+		return "The code is invalid or has expired."
+	case UrrWrongFrontend:
+		return "A relay has to be between a Twitch channel and a Discord channel."
+	case UrrAlreadyPaired:
+		return "These channels are already relayed."
+	case UrrNoPairs:
+		return "There are no relays here."
+	case UrrPairNotFound:
+		return "Couldn't find a relay with that ID here."
+	case UrrInvalidID:
+		return "Expected a relay ID, see the list of relays for them."
+	case UrrNotTwitch:
+		return "Only the Twitch channel can allow messages into its chat."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+// Returns the place's name along with the frontend it's on.
+func placeName(place int64) (string, error) {
+	frontendType, err := core.DB.ScopeFrontend(place)
+	if err != nil {
+		return "", err
+	}
+	id, err := core.DB.ScopeID(place)
+	if err != nil {
+		return "", err
+	}
+
+	switch core.FrontendType(frontendType) {
+	case discord.Frontend.Type():
+		ch, err := discord.Client.Session.Channel(id)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("#%s (%s)", ch.Name, discord.Frontend.Name()), nil
+	case twitch.Frontend.Type():
+		hx, err := twitch.Frontend.Helix()
+		if err != nil {
+			return "", err
+		}
+		u, err := hx.GetUser(id)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s (%s)", u.DisplayName, twitch.Frontend.Name()), nil
+	default:
+		return id, nil
+	}
+}
+
+// Returns the relay ID given as the first argument.
+func argID(m *core.EventMessage) (int64, core.Urr) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(m.Command.Args[0], "#"), 10, 64)
+	if err != nil {
+		return -1, UrrInvalidID
+	}
+	return id, nil
+}
+
+///////////
+//       //
+// start //
+//       //
+///////////
+
+var AdvancedStart = advancedStart{}
+
+type advancedStart struct{}
+
+func (c advancedStart) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedStart) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedStart) Names() []string {
+	return []string{
+		"start",
+	}
+}
+
+func (advancedStart) Description() string {
+	return "Get a code to enter in the other channel."
+}
+
+func (advancedStart) UsageArgs() string {
+	return ""
+}
+
+func (c advancedStart) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedStart) Examples() []string {
+	return nil
+}
+
+func (advancedStart) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedStart) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedStart) Init() error {
+	return nil
+}
+
+func (c advancedStart) Run(m *core.EventMessage) (any, core.Urr, error) {
+	code, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	join := core.FormatQuote(AdvancedJoin, m.Command.Prefix, m.Client)
+	return fmt.Sprintf("An admin of the other channel has to run %s with the code %s within %d minutes.",
+		join, code, int(codeExpiry.Minutes())), nil, nil
+}
+
+func (advancedStart) core(m *core.EventMessage) (string, error) {
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return "", err
+	}
+	return Start(here)
+}
+
+//////////
+//      //
+// join //
+//      //
+//////////
+
+var AdvancedJoin = advancedJoin{}
+
+type advancedJoin struct{}
+
+func (c advancedJoin) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedJoin) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedJoin) Names() []string {
+	return []string{
+		"join",
+	}
+}
+
+func (advancedJoin) Description() string {
+	return "Start relaying using the code from the other channel."
+}
+
+func (advancedJoin) UsageArgs() string {
+	return "<code>"
+}
+
+func (c advancedJoin) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedJoin) Examples() []string {
+	return []string{
+		"K7MPX2QD",
+	}
+}
+
+func (advancedJoin) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedJoin) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedJoin) Init() error {
+	return nil
+}
+
+func (c advancedJoin) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	reverse := core.FormatQuote(AdvancedReverse, m.Command.Prefix, m.Client)
+	return fmt.Sprintf("Relay #%d started, Twitch chat will now show up in Discord. "+
+		"To also send Discord messages to Twitch, run %s from the Twitch channel.", id, reverse), nil, nil
+}
+
+func (advancedJoin) core(m *core.EventMessage) (int64, core.Urr, error) {
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return -1, nil, err
+	}
+	return Join(here, strings.ToUpper(m.Command.Args[0]))
+}
+
+//////////
+//      //
+// list //
+//      //
+//////////
+
+var AdvancedList = advancedList{}
+
+type advancedList struct{}
+
+func (c advancedList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedList) Description() string {
+	return "List the relays this channel is a part of."
+}
+
+func (advancedList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedList) Examples() []string {
+	return nil
+}
+
+func (advancedList) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedList) Init() error {
+	return nil
+}
+
+func (c advancedList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	ps, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+
+	var relays []string
+	for _, p := range ps {
+		tw, err := placeName(p.Twitch)
+		if err != nil {
+			return nil, nil, err
+		}
+		dc, err := placeName(p.Discord)
+		if err != nil {
+			return nil, nil, err
+		}
+		arrow := "->"
+		if p.ToTwitch {
+			arrow = "<->"
+		}
+		relays = append(relays, fmt.Sprintf("#%d %s %s %s", p.ID, tw, arrow, dc))
+	}
+
+	if m.Frontend.Type() == discord.Frontend.Type() {
+		return strings.Join(relays, "\n"), nil, nil
+	}
+	return strings.Join(relays, " | "), nil, nil
+}
+
+func (advancedList) core(m *core.EventMessage) ([]pair, core.Urr, error) {
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return nil, nil, err
+	}
+	return List(here)
+}
+
+/////////////
+//         //
+// reverse //
+//         //
+/////////////
+
+var AdvancedReverse = advancedReverse{}
+
+type advancedReverse struct{}
+
+func (c advancedReverse) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedReverse) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedReverse) Names() []string {
+	return []string{
+		"reverse",
+	}
+}
+
+func (advancedReverse) Description() string {
+	return "Decide whether Discord messages are sent to Twitch chat, can only be run from Twitch."
+}
+
+func (advancedReverse) UsageArgs() string {
+	return "<id> (on | off)"
+}
+
+func (c advancedReverse) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedReverse) Examples() []string {
+	return []string{
+		"3 on",
+		"3 off",
+	}
+}
+
+func (advancedReverse) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedReverse) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedReverse) Init() error {
+	return nil
+}
+
+func (c advancedReverse) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	var on bool
+	switch arg := strings.ToLower(m.Command.Args[1]); {
+	case slices.Contains(core.AliasesOn, arg):
+		on = true
+	case slices.Contains(core.AliasesOff, arg):
+		on = false
+	default:
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	urr, err := c.core(m, on)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	if on {
+		return "Discord messages will now be sent to this chat.", nil, nil
+	}
+	return "Discord messages will no longer be sent to this chat.", nil, nil
+}
+
+func (advancedReverse) core(m *core.EventMessage, on bool) (core.Urr, error) {
+	id, urr := argID(m)
+	if urr != nil {
+		return urr, nil
+	}
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return nil, err
+	}
+	return Reverse(id, here, on)
+}
+
+////////////
+//        //
+// delete //
+//        //
+////////////
+
+var AdvancedDelete = advancedDelete{}
+
+type advancedDelete struct{}
+
+func (c advancedDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedDelete) Names() []string {
+	return append([]string{"stop"}, core.AliasesDelete...)
+}
+
+func (advancedDelete) Description() string {
+	return "Stop a relay, can be run from either channel."
+}
+
+func (advancedDelete) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedDelete) Examples() []string {
+	return []string{
+		"3",
+	}
+}
+
+func (advancedDelete) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedDelete) Init() error {
+	return nil
+}
+
+func (c advancedDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Stopped the relay.", nil, nil
+}
+
+func (advancedDelete) core(m *core.EventMessage) (core.Urr, error) {
+	id, urr := argID(m)
+	if urr != nil {
+		return urr, nil
+	}
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		return nil, err
+	}
+	return Delete(id, here)
+}
@@ -0,0 +1,655 @@
+package relay
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	dg "github.com/bwmarrin/discordgo"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrInvalidCode   = core.UrrNew("the code is invalid or has expired")
+	UrrWrongFrontend = core.UrrNew("a relay must be between a twitch and a discord channel")
+	UrrAlreadyPaired = core.UrrNew("these channels are already relayed")
+	UrrNoPairs       = core.UrrNew("there are no relays here")
+	UrrPairNotFound  = core.UrrNew("couldn't find the relay")
+	UrrInvalidID     = core.UrrNew("invalid relay ID")
+	UrrNotTwitch     = core.UrrNew("only the twitch channel can allow messages into its chat")
+)
+
+// How long a pairing code lasts.
+const codeExpiry = 10 * time.Minute
+
+const codeLength = 8
+
+// Twitch allows 20 messages every 30 seconds in channels where the bot isn't
+// a moderator. The bot's own responses count towards that as well, so relayed
+// messages are sent at a slower pace, leaving room for everything else.
+const twitchInterval = 2 * time.Second
+
+// If more than this many messages are waiting to be relayed into a twitch
+// channel, then the oldest ones are dropped. Chat has moved on by the time
+// they would be sent anyway.
+const queueMax = 30
+
+func codeKey(code string) string {
+	return "cmd_relay-code-" + code
+}
+
+type pair struct {
+	ID      int64
+	Twitch  int64
+	Discord int64
+	// Whether messages from discord are relayed into twitch chat, messages
+	// from twitch are always relayed into discord.
+	ToTwitch bool
+}
+
+//////////////
+//          //
+// database //
+//          //
+//////////////
+
+func scanPairs(rows *sql.Rows) ([]pair, error) {
+	var ps []pair
+	for rows.Next() {
+		var p pair
+		if err := rows.Scan(&p.ID, &p.Twitch, &p.Discord, &p.ToTwitch); err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+	}
+	return ps, rows.Err()
+}
+
+func dbAdd(twitch, discord int64) (int64, error) {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var id int64
+	err := db.DB.QueryRow(`
+		INSERT INTO cmd_relay_pairs (twitch, discord)
+		VALUES ($1, $2)
+		RETURNING id
+	`, twitch, discord).Scan(&id)
+
+	log.Debug().
+		Err(err).
+		Int64("twitch", twitch).
+		Int64("discord", discord).
+		Int64("id", id).
+		Msg("added relay")
+
+	return id, err
+}
+
+func dbExists(twitch, discord int64) (bool, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var exists bool
+	err := db.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM cmd_relay_pairs
+			WHERE twitch = $1 AND discord = $2
+			LIMIT 1
+		)
+	`, twitch, discord).Scan(&exists)
+
+	log.Debug().
+		Err(err).
+		Int64("twitch", twitch).
+		Int64("discord", discord).
+		Bool("exists", exists).
+		Msg("checked if relay exists")
+
+	return exists, err
+}
+
+func dbGet(id int64) (pair, bool, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	p := pair{ID: id}
+	err := db.DB.QueryRow(`
+		SELECT twitch, discord, to_twitch
+		FROM cmd_relay_pairs
+		WHERE id = $1
+	`, id).Scan(&p.Twitch, &p.Discord, &p.ToTwitch)
+
+	log.Debug().
+		Err(err).
+		Interface("pair", p).
+		Msg("got relay")
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, false, nil
+	}
+	return p, err == nil, err
+}
+
+// Returns all the relays that the place is a part of, on either side.
+func dbList(place int64) ([]pair, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, twitch, discord, to_twitch
+		FROM cmd_relay_pairs
+		WHERE twitch = $1 OR discord = $1
+		ORDER BY id
+	`, place)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ps, err := scanPairs(rows)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Interface("pairs", ps).
+		Msg("got relays")
+
+	return ps, err
+}
+
+// Returns the relays that messages sent in the place should be forwarded
+// through.
+func dbFrom(place int64, fromTwitch bool) ([]pair, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	query := `
+		SELECT id, twitch, discord, to_twitch
+		FROM cmd_relay_pairs
+		WHERE discord = $1 AND to_twitch = true
+	`
+	if fromTwitch {
+		query = `
+			SELECT id, twitch, discord, to_twitch
+			FROM cmd_relay_pairs
+			WHERE twitch = $1
+		`
+	}
+
+	rows, err := db.DB.Query(query, place)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ps, err := scanPairs(rows)
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Bool("from-twitch", fromTwitch).
+		Int("#pairs", len(ps)).
+		Msg("got relays to forward through")
+
+	return ps, err
+}
+
+func dbDelete(id int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		DELETE FROM cmd_relay_pairs
+		WHERE id = $1
+	`, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Msg("deleted relay")
+
+	return err
+}
+
+func dbSetToTwitch(id int64, on bool) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_relay_pairs
+		SET to_twitch = $1
+		WHERE id = $2
+	`, on, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Bool("to-twitch", on).
+		Msg("changed relay direction")
+
+	return err
+}
+
+/////////
+//     //
+// run //
+//     //
+/////////
+
+// Start returns a code that must be entered in the other channel in order to
+// start relaying. Requiring a code means that admins of both channels have
+// agreed to it.
+func Start(place int64) (string, error) {
+	code, err := core.GenerateCode(codeLength)
+	if err != nil {
+		return "", err
+	}
+	err = core.RDB.Set(context.Background(), codeKey(code), place, codeExpiry).Err()
+	return code, err
+}
+
+// Join pairs the place with the one that generated the code. One of them must
+// be a twitch channel and the other a discord channel. Only messages from
+// twitch are relayed at first, see Reverse. Returns the new relay's ID.
+func Join(place int64, code string) (int64, core.Urr, error) {
+	other, err := core.RDB.GetDel(context.Background(), codeKey(code)).Int64()
+	if err == redis.Nil {
+		return -1, UrrInvalidCode, nil
+	}
+	if err != nil {
+		return -1, nil, err
+	}
+
+	tw, dc, urr, err := sides(place, other)
+	if urr != nil || err != nil {
+		return -1, urr, err
+	}
+
+	exists, err := dbExists(tw, dc)
+	if err != nil {
+		return -1, nil, err
+	}
+	if exists {
+		return -1, UrrAlreadyPaired, nil
+	}
+
+	id, err := dbAdd(tw, dc)
+	return id, nil, err
+}
+
+// Returns the twitch and the discord place, in that order.
+func sides(a, b int64) (int64, int64, core.Urr, error) {
+	fa, err := core.DB.ScopeFrontend(a)
+	if err != nil {
+		return -1, -1, nil, err
+	}
+	fb, err := core.DB.ScopeFrontend(b)
+	if err != nil {
+		return -1, -1, nil, err
+	}
+
+	tw, dc := twitch.Frontend.Type(), discord.Frontend.Type()
+	switch {
+	case core.FrontendType(fa) == tw && core.FrontendType(fb) == dc:
+		return a, b, nil, nil
+	case core.FrontendType(fa) == dc && core.FrontendType(fb) == tw:
+		return b, a, nil, nil
+	default:
+		return -1, -1, UrrWrongFrontend, nil
+	}
+}
+
+// List returns the relays the place is a part of.
+func List(place int64) ([]pair, core.Urr, error) {
+	ps, err := dbList(place)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ps) == 0 {
+		return nil, UrrNoPairs, nil
+	}
+	return ps, nil, nil
+}
+
+// Discord returns the discord channels that the twitch channel is relayed to.
+func Discord(place int64) ([]int64, error) {
+	ps, err := dbFrom(place, true)
+	if err != nil {
+		return nil, err
+	}
+	var channels []int64
+	for _, p := range ps {
+		channels = append(channels, p.Discord)
+	}
+	return channels, nil
+}
+
+// Returns the relay with the given ID, if the place is a part of it.
+func get(id, place int64) (pair, core.Urr, error) {
+	p, ok, err := dbGet(id)
+	if err != nil {
+		return pair{}, nil, err
+	}
+	if !ok || (p.Twitch != place && p.Discord != place) {
+		return pair{}, UrrPairNotFound, nil
+	}
+	return p, nil, nil
+}
+
+// Delete stops the relay, can be done from either side.
+func Delete(id, place int64) (core.Urr, error) {
+	_, urr, err := get(id, place)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return nil, dbDelete(id)
+}
+
+// Reverse decides whether messages from discord are relayed into twitch chat
+// as well. Since the messages end up being sent under the bot's name, only
+// the twitch side is allowed to change this.
+func Reverse(id, place int64, on bool) (core.Urr, error) {
+	p, urr, err := get(id, place)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	if p.Twitch != place {
+		return UrrNotTwitch, nil
+	}
+	return nil, dbSetToTwitch(id, on)
+}
+
+///////////
+//       //
+// relay //
+//       //
+///////////
+
+var escaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"~", `\~`,
+	"`", "\\`",
+	"|", `\|`,
+	">", `\>`,
+)
+
+// Returns the badges that are shown next to the author's name.
+func badges(a core.Personifier) []string {
+	// broadcasters are mods as well, no point in showing both
+	if admin, err := a.Admin(); err == nil && admin {
+		return []string{"broadcaster"}
+	}
+
+	var bs []string
+	if mod, err := a.Moderator(); err == nil && mod {
+		bs = append(bs, "mod")
+	}
+	if sub, err := a.Subscriber(); err == nil && sub {
+		bs = append(bs, "sub")
+	}
+	return bs
+}
+
+// Formats a twitch message for discord, e.g. **[mod] Name:** text
+func fmtTwitch(m *core.EventMessage) (string, error) {
+	name, err := m.Author.DisplayName()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("**")
+	for _, badge := range badges(m.Author) {
+		b.WriteString("[" + badge + "] ")
+	}
+	b.WriteString(escaper.Replace(name))
+	b.WriteString(":** ")
+	b.WriteString(escaper.Replace(m.Raw))
+	return b.String(), nil
+}
+
+// Formats a discord message for twitch, e.g. [discord] Name: text
+func fmtDiscord(m *core.EventMessage, edited bool) (string, error) {
+	name, err := m.Author.DisplayName()
+	if err != nil {
+		return "", err
+	}
+
+	// mentions look like <@1234> in the raw text, which means nothing on
+	// twitch, so they are replaced with names
+	text := m.Raw
+	switch c := m.Client.(type) {
+	case *discord.MessageCreate:
+		text = c.Message.ContentWithMentionsReplaced()
+	case *discord.MessageEdit:
+		text = c.Message.ContentWithMentionsReplaced()
+	}
+
+	if edited {
+		name += " (edited)"
+	}
+	return "[" + discord.Frontend.Name() + "] " + name + ": " + text, nil
+}
+
+func relay(m *core.EventMessage) {
+	here, err := m.Here.ScopeExact()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get place")
+		return
+	}
+
+	switch m.Frontend.Type() {
+	case twitch.Frontend.Type():
+		fromTwitch(m, here)
+	case discord.Frontend.Type():
+		fromDiscord(m, here)
+	}
+}
+
+func fromTwitch(m *core.EventMessage, here int64) {
+	// the bot's own messages include anything that was relayed from discord,
+	// sending them back would create a loop
+	name, err := m.Author.Name()
+	if err != nil || strings.EqualFold(name, twitch.Frontend.Nick) {
+		return
+	}
+
+	ps, err := dbFrom(here, true)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get relays")
+		return
+	}
+	if len(ps) == 0 {
+		return
+	}
+
+	text, err := fmtTwitch(m)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to format twitch message")
+		return
+	}
+
+	for _, p := range ps {
+		// announcements don't mention anyone, so nobody can sneak in an
+		// @everyone through twitch chat
+		if err := core.Frontends.Announce(p.Discord, text, ""); err != nil {
+			log.Error().Err(err).Interface("pair", p).Msg("failed to relay to discord")
+		}
+	}
+}
+
+func fromDiscord(m *core.EventMessage, here int64) {
+	// the discord frontend already ignores messages sent by bots, which
+	// includes anything that was relayed from twitch
+
+	ps, err := dbFrom(here, false)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get relays")
+		return
+	}
+	if len(ps) == 0 {
+		return
+	}
+
+	text, err := fmtDiscord(m, false)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to format discord message")
+		return
+	}
+
+	_, edited := m.Client.(*discord.MessageEdit)
+	if !edited {
+		for _, p := range ps {
+			queueGet(p.Twitch).push(m.ID, text)
+		}
+		return
+	}
+
+	editedText, err := fmtDiscord(m, true)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to format discord message")
+		return
+	}
+	for _, p := range ps {
+		queueGet(p.Twitch).edit(m.ID, text, editedText)
+	}
+}
+
+// Messages that are deleted before they get relayed are never sent. Once a
+// message is in twitch chat there is no way to take it back, since sending
+// through IRC doesn't tell us the message's ID.
+func deleted(d *dg.MessageDelete) {
+	queuesLock.Lock()
+	defer queuesLock.Unlock()
+
+	for _, q := range queues {
+		q.remove(d.ID)
+	}
+}
+
+///////////
+//       //
+// queue //
+//       //
+///////////
+
+type queued struct {
+	id   string
+	text string
+}
+
+// Holds the messages waiting to be relayed into a twitch channel. One message
+// is sent every twitchInterval.
+type queue struct {
+	lock  sync.Mutex
+	place int64
+	msgs  []queued
+}
+
+var (
+	queues     = map[int64]*queue{}
+	queuesLock sync.Mutex
+)
+
+// Returns the place's queue, creating it if it doesn't exist.
+func queueGet(place int64) *queue {
+	queuesLock.Lock()
+	defer queuesLock.Unlock()
+
+	if q, ok := queues[place]; ok {
+		return q
+	}
+	q := &queue{place: place}
+	queues[place] = q
+	go q.run()
+	return q
+}
+
+func (q *queue) push(id, text string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.msgs) >= queueMax {
+		log.Debug().
+			Int64("place", q.place).
+			Str("id", q.msgs[0].id).
+			Msg("relay queue full, dropping oldest message")
+		q.msgs = q.msgs[1:]
+	}
+	q.msgs = append(q.msgs, queued{id, text})
+}
+
+// If the message is still waiting to be sent then its text is replaced,
+// otherwise the edited version is sent as a new message.
+func (q *queue) edit(id, text, editedText string) {
+	q.lock.Lock()
+	for i := range q.msgs {
+		if q.msgs[i].id == id {
+			q.msgs[i].text = text
+			q.lock.Unlock()
+			return
+		}
+	}
+	q.lock.Unlock()
+	q.push(id, editedText)
+}
+
+func (q *queue) remove(id string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for i := range q.msgs {
+		if q.msgs[i].id == id {
+			q.msgs = append(q.msgs[:i], q.msgs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *queue) pop() (queued, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.msgs) == 0 {
+		return queued{}, false
+	}
+	msg := q.msgs[0]
+	q.msgs = q.msgs[1:]
+	return msg, true
+}
+
+func (q *queue) run() {
+	for range time.Tick(twitchInterval) {
+		msg, ok := q.pop()
+		if !ok {
+			continue
+		}
+
+		m, err := core.Frontends.CreateMessage(q.place, q.place, "")
+		if err != nil {
+			log.Error().Err(err).Int64("place", q.place).Msg("failed to create twitch message")
+			continue
+		}
+		if _, err := m.Client.Send(msg.text, nil); err != nil {
+			log.Error().Err(err).Int64("place", q.place).Msg("failed to relay to twitch")
+		}
+	}
+}
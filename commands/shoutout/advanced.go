@@ -0,0 +1,755 @@
+package shoutout
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	if m.Frontend.Type() != twitch.Type {
+		return false
+	}
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"shoutout",
+		"so",
+	}
+}
+
+func (advanced) Description() string {
+	return "Control shoutouts."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedAuto,
+		AdvancedTemplate,
+		AdvancedCooldown,
+	}
+}
+
+func (advanced) Init() error {
+	core.EventRaidHooks.Register(onRaid)
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+func fmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrUserNotFound:
+		return "Couldn't find that user."
+	case UrrOnCooldown:
+		return "That user was shouted out too recently."
+	case UrrInvalidCooldown:
+		return "Invalid cooldown, expected something like 30m or 1h."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+//////////
+//      //
+// auto //
+//      //
+//////////
+
+var AdvancedAuto = advancedAuto{}
+
+type advancedAuto struct{}
+
+func (c advancedAuto) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAuto) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAuto) Names() []string {
+	return []string{
+		"auto",
+	}
+}
+
+func (advancedAuto) Description() string {
+	return "Control automatically shouting out raiders."
+}
+
+func (c advancedAuto) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedAuto) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAuto) Examples() []string {
+	return nil
+}
+
+func (advancedAuto) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedAuto) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedAutoShow,
+		AdvancedAutoOn,
+		AdvancedAutoOff,
+	}
+}
+
+func (advancedAuto) Init() error {
+	return nil
+}
+
+func (advancedAuto) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////
+//           //
+// auto show //
+//           //
+///////////////
+
+var AdvancedAutoShow = advancedAutoShow{}
+
+type advancedAutoShow struct{}
+
+func (c advancedAutoShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedAutoShow) Description() string {
+	return "Show if raiders are automatically shouted out."
+}
+
+func (advancedAutoShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoShow) Examples() []string {
+	return nil
+}
+
+func (advancedAutoShow) Parent() core.CommandStatic {
+	return AdvancedAuto
+}
+
+func (advancedAutoShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoShow) Init() error {
+	return nil
+}
+
+func (c advancedAutoShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	on, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if on {
+		return "Raiders are automatically shouted out.", nil, nil
+	}
+	return "Raiders are not automatically shouted out.", nil, nil
+}
+
+func (advancedAutoShow) core(m *core.EventMessage) (bool, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return false, err
+	}
+	return AutoGet(here)
+}
+
+/////////////
+//         //
+// auto on //
+//         //
+/////////////
+
+var AdvancedAutoOn = advancedAutoOn{}
+
+type advancedAutoOn struct{}
+
+func (c advancedAutoOn) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoOn) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoOn) Names() []string {
+	return core.AliasesOn
+}
+
+func (advancedAutoOn) Description() string {
+	return "Start automatically shouting out raiders."
+}
+
+func (advancedAutoOn) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoOn) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoOn) Examples() []string {
+	return nil
+}
+
+func (advancedAutoOn) Parent() core.CommandStatic {
+	return AdvancedAuto
+}
+
+func (advancedAutoOn) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoOn) Init() error {
+	return nil
+}
+
+func (c advancedAutoOn) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if err := c.core(m); err != nil {
+		return nil, nil, err
+	}
+	return "Raiders will now be automatically shouted out.", nil, nil
+}
+
+func (advancedAutoOn) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return AutoSet(here, true)
+}
+
+//////////////
+//          //
+// auto off //
+//          //
+//////////////
+
+var AdvancedAutoOff = advancedAutoOff{}
+
+type advancedAutoOff struct{}
+
+func (c advancedAutoOff) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedAutoOff) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedAutoOff) Names() []string {
+	return core.AliasesOff
+}
+
+func (advancedAutoOff) Description() string {
+	return "Stop automatically shouting out raiders."
+}
+
+func (advancedAutoOff) UsageArgs() string {
+	return ""
+}
+
+func (c advancedAutoOff) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedAutoOff) Examples() []string {
+	return nil
+}
+
+func (advancedAutoOff) Parent() core.CommandStatic {
+	return AdvancedAuto
+}
+
+func (advancedAutoOff) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedAutoOff) Init() error {
+	return nil
+}
+
+func (c advancedAutoOff) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if err := c.core(m); err != nil {
+		return nil, nil, err
+	}
+	return "Raiders will no longer be automatically shouted out.", nil, nil
+}
+
+func (advancedAutoOff) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return AutoSet(here, false)
+}
+
+//////////////
+//          //
+// template //
+//          //
+//////////////
+
+var AdvancedTemplate = advancedTemplate{}
+
+type advancedTemplate struct{}
+
+func (c advancedTemplate) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedTemplate) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedTemplate) Names() []string {
+	return []string{
+		"template",
+		"message",
+		"msg",
+	}
+}
+
+func (advancedTemplate) Description() string {
+	return "Control the shoutout message."
+}
+
+func (c advancedTemplate) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedTemplate) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedTemplate) Examples() []string {
+	return nil
+}
+
+func (advancedTemplate) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedTemplate) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedTemplateShow,
+		AdvancedTemplateSet,
+	}
+}
+
+func (advancedTemplate) Init() error {
+	return nil
+}
+
+func (advancedTemplate) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////////
+//               //
+// template show //
+//               //
+///////////////////
+
+var AdvancedTemplateShow = advancedTemplateShow{}
+
+type advancedTemplateShow struct{}
+
+func (c advancedTemplateShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedTemplateShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedTemplateShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedTemplateShow) Description() string {
+	return "Show the shoutout message."
+}
+
+func (advancedTemplateShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedTemplateShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedTemplateShow) Examples() []string {
+	return nil
+}
+
+func (advancedTemplateShow) Parent() core.CommandStatic {
+	return AdvancedTemplate
+}
+
+func (advancedTemplateShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedTemplateShow) Init() error {
+	return nil
+}
+
+func (c advancedTemplateShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	tmpl, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tmpl, nil, nil
+}
+
+func (advancedTemplateShow) core(m *core.EventMessage) (string, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+	return TemplateGet(here)
+}
+
+//////////////////
+//              //
+// template set //
+//              //
+//////////////////
+
+var AdvancedTemplateSet = advancedTemplateSet{}
+
+type advancedTemplateSet struct{}
+
+func (c advancedTemplateSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedTemplateSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedTemplateSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedTemplateSet) Description() string {
+	return "Set the shoutout message. $(user), $(login), $(url), $(game) and $(title) are replaced with the channel's details."
+}
+
+func (advancedTemplateSet) UsageArgs() string {
+	return "<message...>"
+}
+
+func (c advancedTemplateSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedTemplateSet) Examples() []string {
+	return []string{
+		"Go follow $(user) at $(url), they were last playing $(game)!",
+	}
+}
+
+func (advancedTemplateSet) Parent() core.CommandStatic {
+	return AdvancedTemplate
+}
+
+func (advancedTemplateSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedTemplateSet) Init() error {
+	return nil
+}
+
+func (c advancedTemplateSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	if err := c.core(m); err != nil {
+		return nil, nil, err
+	}
+	return "Set the shoutout message.", nil, nil
+}
+
+func (advancedTemplateSet) core(m *core.EventMessage) error {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return err
+	}
+	return TemplateSet(here, strings.TrimSpace(m.RawArgs(0)))
+}
+
+//////////////
+//          //
+// cooldown //
+//          //
+//////////////
+
+var AdvancedCooldown = advancedCooldown{}
+
+type advancedCooldown struct{}
+
+func (c advancedCooldown) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldown) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldown) Names() []string {
+	return []string{
+		"cooldown",
+		"cd",
+	}
+}
+
+func (advancedCooldown) Description() string {
+	return "Control how often the same channel can be shouted out."
+}
+
+func (c advancedCooldown) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedCooldown) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldown) Examples() []string {
+	return nil
+}
+
+func (advancedCooldown) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedCooldown) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedCooldownShow,
+		AdvancedCooldownSet,
+	}
+}
+
+func (advancedCooldown) Init() error {
+	return nil
+}
+
+func (advancedCooldown) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////////
+//               //
+// cooldown show //
+//               //
+///////////////////
+
+var AdvancedCooldownShow = advancedCooldownShow{}
+
+type advancedCooldownShow struct{}
+
+func (c advancedCooldownShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldownShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldownShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedCooldownShow) Description() string {
+	return "Show the shoutout cooldown."
+}
+
+func (advancedCooldownShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedCooldownShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldownShow) Examples() []string {
+	return nil
+}
+
+func (advancedCooldownShow) Parent() core.CommandStatic {
+	return AdvancedCooldown
+}
+
+func (advancedCooldownShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCooldownShow) Init() error {
+	return nil
+}
+
+func (c advancedCooldownShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	cooldown, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cooldown == 0 {
+		return "There is no shoutout cooldown.", nil, nil
+	}
+	return "The same channel can be shouted out once every " + cooldown.String() + ".", nil, nil
+}
+
+func (advancedCooldownShow) core(m *core.EventMessage) (time.Duration, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, err
+	}
+	return CooldownGet(here)
+}
+
+//////////////////
+//              //
+// cooldown set //
+//              //
+//////////////////
+
+var AdvancedCooldownSet = advancedCooldownSet{}
+
+type advancedCooldownSet struct{}
+
+func (c advancedCooldownSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedCooldownSet) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedCooldownSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedCooldownSet) Description() string {
+	return "Set the shoutout cooldown, 0 disables it."
+}
+
+func (advancedCooldownSet) UsageArgs() string {
+	return "<duration>"
+}
+
+func (c advancedCooldownSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedCooldownSet) Examples() []string {
+	return []string{
+		"1h",
+		"30m",
+		"0",
+	}
+}
+
+func (advancedCooldownSet) Parent() core.CommandStatic {
+	return AdvancedCooldown
+}
+
+func (advancedCooldownSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedCooldownSet) Init() error {
+	return nil
+}
+
+func (c advancedCooldownSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	cooldown, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return "Updated the shoutout cooldown to " + cooldown.String() + ".", nil, nil
+}
+
+func (advancedCooldownSet) core(m *core.EventMessage) (time.Duration, core.Urr, error) {
+	cooldown, err := time.ParseDuration(m.Command.Args[0])
+	if err != nil {
+		return 0, UrrInvalidCooldown, nil
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return 0, nil, err
+	}
+	urr, err := CooldownSet(here, cooldown)
+	return cooldown, urr, err
+}
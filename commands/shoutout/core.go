@@ -0,0 +1,236 @@
+package shoutout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	UrrUserNotFound    = core.UrrNew("couldn't find that user")
+	UrrOnCooldown      = core.UrrNew("the user was shouted out too recently")
+	UrrInvalidCooldown = core.UrrNew("invalid cooldown")
+)
+
+// DefaultTemplate is used in places that haven't set their own template.
+const DefaultTemplate = "Go check out $(user) at $(url), they were last seen playing $(game): $(title)"
+
+func cooldownKey(place int64, userID string) string {
+	return fmt.Sprintf("cmd_shoutout-cooldown-%d-%s", place, userID)
+}
+
+// AutoGet returns whether raiders are automatically shouted out in the place.
+func AutoGet(place int64) (bool, error) {
+	return core.DB.PlaceGet("cmd_shoutout_auto", place).Bool()
+}
+
+// AutoSet turns automatic shoutouts on or off for the place. Turning them on
+// subscribes to the place's raids, if that hasn't already been done.
+func AutoSet(place int64, on bool) error {
+	if on {
+		err := twitch.EventsubEnsureCreated(place, helix.EventSubTypeChannelRaid)
+		if err != nil {
+			return err
+		}
+	}
+	return core.DB.PlaceSet("cmd_shoutout_auto", place, on)
+}
+
+// TemplateGet returns the place's shoutout message template, or
+// DefaultTemplate if none has been set.
+func TemplateGet(place int64) (string, error) {
+	tmpl, urr, err := core.DB.PlaceGet("cmd_shoutout_template", place).StrNil()
+	if urr == core.UrrValNil {
+		return DefaultTemplate, nil
+	}
+	return tmpl, err
+}
+
+// TemplateSet sets the place's shoutout message template.
+func TemplateSet(place int64, tmpl string) error {
+	return core.DB.PlaceSet("cmd_shoutout_template", place, tmpl)
+}
+
+// CooldownGet returns how long has to pass before the same user can be
+// shouted out again in the place.
+func CooldownGet(place int64) (time.Duration, error) {
+	return core.DB.PlaceGet("cmd_shoutout_cooldown", place).Duration()
+}
+
+// CooldownSet sets the per-user shoutout cooldown for the place. A cooldown of
+// zero disables it, otherwise it must be at least a second since it's saved in
+// seconds.
+func CooldownSet(place int64, cooldown time.Duration) (core.Urr, error) {
+	if cooldown < 0 || (cooldown > 0 && cooldown < time.Second) {
+		return UrrInvalidCooldown, nil
+	}
+	return nil, core.DB.PlaceSet("cmd_shoutout_cooldown", place, int(cooldown.Seconds()))
+}
+
+// Checks if the user's cooldown is still active.
+func onCooldown(place int64, userID string) (bool, error) {
+	ctx := context.Background()
+	n, err := core.RDB.Exists(ctx, cooldownKey(place, userID)).Result()
+	return n > 0, err
+}
+
+// Starts the user's cooldown, returns false if it was already active.
+func cooldownStart(place int64, userID string) (bool, error) {
+	cooldown, err := CooldownGet(place)
+	if err != nil {
+		return false, err
+	}
+	if cooldown == 0 {
+		return true, nil
+	}
+	ctx := context.Background()
+	return core.RDB.SetNX(ctx, cooldownKey(place, userID), nil, cooldown).Result()
+}
+
+// Builds the shoutout message from the template, using the channel's last
+// category and title.
+func message(place int64, login string, ch helix.ChannelInformation) (string, error) {
+	tmpl, err := TemplateGet(place)
+	if err != nil {
+		return "", err
+	}
+
+	game := ch.GameName
+	if game == "" {
+		game = "nothing yet"
+	}
+
+	r := strings.NewReplacer(
+		"$(user)", ch.BroadcasterName,
+		"$(login)", login,
+		"$(url)", "https://twitch.tv/"+login,
+		"$(game)", game,
+		"$(title)", ch.Title,
+	)
+	return r.Replace(tmpl), nil
+}
+
+// Shoutout returns the shoutout message for the user with the given ID and,
+// if the broadcaster has connected their account, also sends a native
+// shoutout. Returns UrrOnCooldown if the user was shouted out in the place
+// within the cooldown.
+func Shoutout(place int64, broadcasterID, userID string) (string, core.Urr, error) {
+	cooldown, err := onCooldown(place, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if cooldown {
+		return "", UrrOnCooldown, nil
+	}
+
+	hx, err := twitch.Frontend.Helix()
+	if err != nil {
+		return "", nil, err
+	}
+	u, err := hx.GetUser(userID)
+	if err == twitch.ErrNoResults {
+		return "", UrrUserNotFound, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	ch, err := hx.GetChannelInfo(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	msg, err := message(place, u.Login, ch)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// only started once nothing can fail anymore, otherwise the user couldn't
+	// be shouted out again until it ends
+	ok, err := cooldownStart(place, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		return "", UrrOnCooldown, nil
+	}
+
+	// The native shoutout is a nice extra, the message is still sent if it
+	// fails, which it often does since it requires the stream to be live.
+	if hx, err := twitch.NewHelix(broadcasterID); err != nil {
+		log.Error().Err(err).Msg("failed to create broadcaster's helix client")
+	} else if urr, err := hx.Shoutout(broadcasterID, userID); err != nil {
+		log.Error().Err(err).Msg("failed to send native shoutout")
+	} else if urr != nil {
+		log.Debug().Err(urr).Msg("native shoutout was rejected")
+	}
+
+	return msg, nil, nil
+}
+
+// ShoutoutUser is the same as Shoutout, but the user is given by their
+// username.
+func ShoutoutUser(place int64, broadcasterID, username string) (string, core.Urr, error) {
+	username = strings.ToLower(strings.TrimPrefix(username, "@"))
+
+	hx, err := twitch.Frontend.Helix()
+	if err != nil {
+		return "", nil, err
+	}
+	userID, err := hx.GetUserID(username)
+	if err == twitch.ErrNoResults {
+		return "", UrrUserNotFound, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return Shoutout(place, broadcasterID, userID)
+}
+
+func onRaid(r *core.EventRaid) {
+	place, err := r.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get place scope")
+		return
+	}
+
+	on, err := AutoGet(place)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if auto shoutouts are on")
+		return
+	}
+	if !on {
+		return
+	}
+
+	broadcasterID, err := r.Here.IDExact()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get broadcaster id")
+		return
+	}
+	raiderID, err := r.Author.ID()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get raider id")
+		return
+	}
+
+	msg, urr, err := Shoutout(place, broadcasterID, raiderID)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to shout out raider")
+		return
+	}
+	if urr != nil {
+		log.Debug().Err(urr).Str("raider", raiderID).Msg("skipped raider shoutout")
+		return
+	}
+
+	if err := core.Frontends.Announce(place, msg, ""); err != nil {
+		log.Error().Err(err).Msg("failed to send raider shoutout")
+	}
+}
@@ -0,0 +1,77 @@
+package shoutout
+
+import (
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var Normal = normal{}
+
+type normal struct{}
+
+func (normal) Type() core.CommandType {
+	return core.Normal
+}
+
+func (normal) Permitted(m *core.EventMessage) bool {
+	return Advanced.Permitted(m)
+}
+
+func (normal) Names() []string {
+	return Advanced.Names()
+}
+
+func (normal) Description() string {
+	return "Shout out a channel, showing what they were last streaming."
+}
+
+func (normal) UsageArgs() string {
+	return "<user>"
+}
+
+func (normal) Category() core.CommandCategory {
+	return Advanced.Category()
+}
+
+func (normal) Examples() []string {
+	return []string{
+		"kvlach",
+	}
+}
+
+func (normal) Parent() core.CommandStatic {
+	return nil
+}
+
+func (normal) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normal) Init() error {
+	return nil
+}
+
+func (c normal) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	msg, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return fmtUrr(urr), urr, nil
+	}
+	return msg, nil, nil
+}
+
+func (normal) core(m *core.EventMessage) (string, core.Urr, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	broadcasterID, err := m.Here.IDExact()
+	if err != nil {
+		return "", nil, err
+	}
+	return ShoutoutUser(here, broadcasterID, m.Command.Args[0])
+}
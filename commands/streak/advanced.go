@@ -2,16 +2,33 @@ package streak
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kvlach/janitorjeff/commands/nick"
+	ctwitch "github.com/kvlach/janitorjeff/commands/twitch"
 	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/discord"
 	"github.com/kvlach/janitorjeff/frontends/twitch"
 
+	dg "github.com/bwmarrin/discordgo"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
-var UrrInvalidDuration = core.UrrNew("provided duration could not be parsed")
+var (
+	UrrInvalidDuration = core.UrrNew("provided duration could not be parsed")
+	UrrInvalidCount    = core.UrrNew("invalid number of people")
+	UrrNoStreaks       = core.UrrNew("nobody has an active streak")
+	UrrPersonNotFound  = core.UrrNew("was unable to find user")
+)
+
+// The number of people shown in the leaderboard by default and at most.
+const (
+	topDefault = 10
+	topMax     = 25
+)
 
 var Advanced = advanced{}
 
@@ -64,6 +81,8 @@ func (advanced) Children() core.CommandsStatic {
 		AdvancedOn,
 		AdvancedOff,
 		AdvancedShow,
+		AdvancedTop,
+		AdvancedStats,
 		AdvancedRedeem,
 		AdvancedGrace,
 	}
@@ -98,10 +117,18 @@ func (advanced) Init() error {
 		}
 
 		streak, err := Appearance(person, place, r.When)
+		if err == ErrIgnore {
+			// already redeemed during this stream
+			log.Debug().Int64("person", person).Msg("streak already counted")
+			r.Refund()
+			return
+		}
 		if err != nil {
 			log.Error().Err(err).Msg("failed to update user streak")
+			r.Refund()
 			return
 		}
+		r.Fulfil()
 
 		m, err := core.Frontends.CreateMessage(person, place, "")
 		if err != nil {
@@ -133,6 +160,28 @@ func (advanced) Run(m *core.EventMessage) (resp any, urr core.Urr, err error) {
 	return m.Usage(), core.UrrMissingArgs, nil
 }
 
+// Returns how the person is shown in the leaderboard and stats. On Discord
+// the person is mentioned, which doesn't ping them inside of embeds.
+func personName(m *core.EventMessage, person int64) (string, error) {
+	if m.Frontend.Type() == discord.Frontend.Type() {
+		id, err := core.DB.ScopeID(person)
+		if err != nil {
+			return "", err
+		}
+		return "<@" + id + ">", nil
+	}
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+	pm, err := core.Frontends.CreateMessage(person, here, "")
+	if err != nil {
+		return "", err
+	}
+	return pm.Author.DisplayName()
+}
+
 ////////
 //    //
 // on //
@@ -345,6 +394,335 @@ func (advancedShow) core(m *core.EventMessage) (int64, error) {
 	return Get(author, here)
 }
 
+/////////
+//     //
+// top //
+//     //
+/////////
+
+var AdvancedTop = advancedTop{}
+
+type advancedTop struct{}
+
+func (c advancedTop) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedTop) Permitted(*core.EventMessage) bool {
+	return true
+}
+
+func (advancedTop) Names() []string {
+	return []string{
+		"top",
+		"leaderboard",
+		"lb",
+	}
+}
+
+func (advancedTop) Description() string {
+	return "Show the people with the highest streaks."
+}
+
+func (advancedTop) UsageArgs() string {
+	return "[n]"
+}
+
+func (c advancedTop) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedTop) Examples() []string {
+	return []string{
+		"",
+		"5",
+	}
+}
+
+func (advancedTop) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedTop) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedTop) Init() error {
+	return nil
+}
+
+func (c advancedTop) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedTop) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	entries, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if urr != nil {
+		embed := &dg.MessageEmbed{
+			Description: c.fmt(urr),
+		}
+		return embed, urr, nil
+	}
+
+	var desc strings.Builder
+	for i, e := range entries {
+		name, err := personName(m, e.Person)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintf(&desc, "**%d.** %s - %d\n", i+1, name, e.Streak)
+	}
+
+	embed := &dg.MessageEmbed{
+		Title:       "Streak Leaderboard",
+		Description: desc.String(),
+	}
+	return embed, nil, nil
+}
+
+func (c advancedTop) text(m *core.EventMessage) (string, core.Urr, error) {
+	entries, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+
+	var parts []string
+	for i, e := range entries {
+		name, err := personName(m, e.Person)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, fmt.Sprintf("%d. %s (%d)", i+1, name, e.Streak))
+	}
+	return strings.Join(parts, ", "), nil, nil
+}
+
+func (advancedTop) fmt(urr core.Urr) string {
+	switch urr {
+	case UrrNoStreaks:
+		return "Nobody has an active streak."
+	case UrrInvalidCount:
+		return fmt.Sprintf("Expected a number between 1 and %d.", topMax)
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedTop) core(m *core.EventMessage) ([]Entry, core.Urr, error) {
+	n := topDefault
+	if len(m.Command.Args) > 0 {
+		var err error
+		n, err = strconv.Atoi(m.Command.Args[0])
+		if err != nil || n < 1 || n > topMax {
+			return nil, UrrInvalidCount, nil
+		}
+	}
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := Top(here, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) == 0 {
+		return nil, UrrNoStreaks, nil
+	}
+	return entries, nil, nil
+}
+
+///////////
+//       //
+// stats //
+//       //
+///////////
+
+var AdvancedStats = advancedStats{}
+
+type advancedStats struct{}
+
+func (c advancedStats) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedStats) Permitted(*core.EventMessage) bool {
+	return true
+}
+
+func (advancedStats) Names() []string {
+	return []string{
+		"stats",
+	}
+}
+
+func (advancedStats) Description() string {
+	return "Show yours or someone else's streak statistics."
+}
+
+func (advancedStats) UsageArgs() string {
+	return "[person]"
+}
+
+func (c advancedStats) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedStats) Examples() []string {
+	return nil
+}
+
+func (advancedStats) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedStats) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedStats) Init() error {
+	return nil
+}
+
+func (c advancedStats) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedStats) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	person, st, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if urr != nil {
+		embed := &dg.MessageEmbed{
+			Description: c.fmt(urr),
+		}
+		return embed, urr, nil
+	}
+
+	name, err := personName(m, person)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if st.Total == 0 {
+		embed := &dg.MessageEmbed{
+			Description: name + " hasn't appeared yet.",
+		}
+		return embed, nil, nil
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: name,
+		Fields: []*dg.MessageEmbedField{
+			{
+				Name:   "Current Streak",
+				Value:  strconv.FormatInt(st.Current, 10),
+				Inline: true,
+			},
+			{
+				Name:   "Longest Streak",
+				Value:  strconv.FormatInt(st.Longest, 10),
+				Inline: true,
+			},
+			{
+				Name:   "Appearances",
+				Value:  strconv.FormatInt(st.Total, 10),
+				Inline: true,
+			},
+			{
+				Name:   "First Seen",
+				Value:  fmt.Sprintf("<t:%d:D>", st.FirstSeen.Unix()),
+				Inline: true,
+			},
+			{
+				Name:   "Last Seen",
+				Value:  fmt.Sprintf("<t:%d:R>", st.LastSeen.Unix()),
+				Inline: true,
+			},
+		},
+	}
+	return embed, nil, nil
+}
+
+func (c advancedStats) text(m *core.EventMessage) (string, core.Urr, error) {
+	person, st, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if urr != nil {
+		return c.fmt(urr), urr, nil
+	}
+
+	name, err := personName(m, person)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if st.Total == 0 {
+		return name + " hasn't appeared yet.", nil, nil
+	}
+
+	resp := fmt.Sprintf("%s: current streak %d, longest streak %d, %d appearances, first seen on %s",
+		name, st.Current, st.Longest, st.Total, st.FirstSeen.Format("02 Jan 2006"))
+	return resp, nil, nil
+}
+
+func (advancedStats) fmt(urr core.Urr) string {
+	switch urr {
+	case UrrPersonNotFound:
+		return "Couldn't find the person you're looking for."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedStats) core(m *core.EventMessage) (int64, Stats, core.Urr, error) {
+	var person int64
+	var err error
+
+	if len(m.Command.Args) == 0 {
+		person, err = m.Author.Scope()
+		if err != nil {
+			return -1, Stats{}, nil, err
+		}
+	} else {
+		person, err = nick.ParsePersonHere(m, m.Command.Args[0])
+		if err != nil {
+			return -1, Stats{}, UrrPersonNotFound, nil
+		}
+	}
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return -1, Stats{}, nil, err
+	}
+
+	st, err := StatsGet(person, here)
+	return person, st, nil, err
+}
+
 ////////////
 //        //
 // redeem //
@@ -393,6 +771,7 @@ func (advancedRedeem) Children() core.CommandsStatic {
 	return core.CommandsStatic{
 		AdvancedRedeemShow,
 		AdvancedRedeemSet,
+		AdvancedRedeemCreate,
 	}
 }
 
@@ -551,6 +930,84 @@ func (advancedRedeemSet) core(m *core.EventMessage) error {
 	return RedeemSet(here, m.Command.Args[0])
 }
 
+///////////////////
+//               //
+// redeem create //
+//               //
+///////////////////
+
+var AdvancedRedeemCreate = advancedRedeemCreate{}
+
+type advancedRedeemCreate struct{}
+
+func (c advancedRedeemCreate) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemCreate) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemCreate) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedRedeemCreate) Description() string {
+	return "Create a new channel point reward and set it as the streak redeem."
+}
+
+func (advancedRedeemCreate) UsageArgs() string {
+	return "<cost> <title...>"
+}
+
+func (c advancedRedeemCreate) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemCreate) Examples() []string {
+	return []string{
+		"100 Streak",
+	}
+}
+
+func (advancedRedeemCreate) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemCreate) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemCreate) Init() error {
+	return nil
+}
+
+func (c advancedRedeemCreate) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return ctwitch.FmtUrr(urr), urr, nil
+	}
+	return "Created the reward and set it as the streak redeem, its ID is: " + id, nil, nil
+}
+
+func (advancedRedeemCreate) core(m *core.EventMessage) (string, core.Urr, error) {
+	id, urr, err := ctwitch.CreateFrom(m, ctwitch.RewardOptions{})
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+	return id, nil, RedeemSet(here, id)
+}
+
 ///////////
 //       //
 // grace //
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
 )
 
 var (
@@ -97,6 +98,36 @@ func Appearance(person, place int64, when time.Time) (int64, error) {
 	if err != nil {
 		return -1, err
 	}
+
+	longest, err := tx.PersonGet("cmd_streak_longest", person, place).Int64()
+	if err != nil {
+		return -1, err
+	}
+	if streak+1 > longest {
+		err = tx.PersonSet("cmd_streak_longest", person, place, streak+1)
+		if err != nil {
+			return -1, err
+		}
+	}
+	total, err := tx.PersonGet("cmd_streak_total", person, place).Int64()
+	if err != nil {
+		return -1, err
+	}
+	err = tx.PersonSet("cmd_streak_total", person, place, total+1)
+	if err != nil {
+		return -1, err
+	}
+	first, err := tx.PersonGet("cmd_streak_first", person, place).Int64()
+	if err != nil {
+		return -1, err
+	}
+	if first == 0 {
+		err = tx.PersonSet("cmd_streak_first", person, place, when.UTC().Unix())
+		if err != nil {
+			return -1, err
+		}
+	}
+
 	return streak + 1, tx.Commit()
 }
 
@@ -125,6 +156,113 @@ func Set(person, place int64, streak int) error {
 	return core.DB.PersonSet("cmd_streak_num", person, place, streak)
 }
 
+// Stats holds a person's streak statistics in a place.
+type Stats struct {
+	// The current streak, 0 if the person missed the previous stream.
+	Current int64
+	Longest int64
+	// The number of streams the person has appeared in.
+	Total int64
+	// Zero if the person has never appeared.
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// Entry is a single leaderboard entry.
+type Entry struct {
+	Person int64
+	Streak int64
+}
+
+// StatsGet returns the person's streak statistics.
+func StatsGet(person, place int64) (Stats, error) {
+	var st Stats
+
+	current, err := core.DB.PersonGet("cmd_streak_num", person, place).Int64()
+	if err != nil {
+		return st, err
+	}
+	last, err := core.DB.PersonGet("cmd_streak_last", person, place).Int64()
+	if err != nil {
+		return st, err
+	}
+	offlinePrev, err := core.DB.PlaceGet("stream_offline_norm_prev", place).Int64()
+	if err != nil {
+		return st, err
+	}
+	// the streak only gets reset on the person's next appearance, so it may
+	// be outdated, see Appearance
+	if offlinePrev > last {
+		current = 0
+	}
+	st.Current = current
+
+	st.Longest, err = core.DB.PersonGet("cmd_streak_longest", person, place).Int64()
+	if err != nil {
+		return st, err
+	}
+	st.Total, err = core.DB.PersonGet("cmd_streak_total", person, place).Int64()
+	if err != nil {
+		return st, err
+	}
+	first, err := core.DB.PersonGet("cmd_streak_first", person, place).Int64()
+	if err != nil {
+		return st, err
+	}
+	if first != 0 {
+		st.FirstSeen = time.Unix(first, 0).UTC()
+	}
+	if last != 0 {
+		st.LastSeen = time.Unix(last, 0).UTC()
+	}
+	return st, nil
+}
+
+// Top returns the n people with the highest current streaks in the place.
+// Streaks that are outdated because the person missed the previous stream
+// are ignored.
+func Top(place int64, n int) ([]Entry, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT person, cmd_streak_num
+		FROM info_person
+		WHERE place = $1 AND cmd_streak_num > 0 AND cmd_streak_last >= COALESCE((
+			SELECT stream_offline_norm_prev
+			FROM info_place
+			WHERE place = $1
+		), 0)
+		ORDER BY cmd_streak_num DESC, cmd_streak_longest DESC, cmd_streak_first ASC
+		LIMIT $2
+	`, place, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Person, &e.Streak); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Int("n", n).
+		Int("#entries", len(entries)).
+		Msg("got streak leaderboard")
+
+	return entries, err
+}
+
 // GraceGet returns the place's grace period. For more info: core/events.go.
 func GraceGet(place int64) (time.Duration, error) {
 	return core.DB.PlaceGet("stream_grace", place).Duration()
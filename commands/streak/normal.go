@@ -44,6 +44,8 @@ func (normal) Children() core.CommandsStatic {
 	return core.CommandsStatic{
 		NormalOn,
 		NormalOff,
+		NormalTop,
+		NormalStats,
 		NormalRedeem,
 		NormalGrace,
 	}
@@ -165,6 +167,114 @@ func (normalOff) Run(m *core.EventMessage) (any, core.Urr, error) {
 	return AdvancedOff.Run(m)
 }
 
+/////////
+//     //
+// top //
+//     //
+/////////
+
+var NormalTop = normalTop{}
+
+type normalTop struct{}
+
+func (c normalTop) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (normalTop) Permitted(m *core.EventMessage) bool {
+	return AdvancedTop.Permitted(m)
+}
+
+func (normalTop) Names() []string {
+	return AdvancedTop.Names()
+}
+
+func (normalTop) Description() string {
+	return AdvancedTop.Description()
+}
+
+func (normalTop) UsageArgs() string {
+	return AdvancedTop.UsageArgs()
+}
+
+func (c normalTop) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalTop) Examples() []string {
+	return AdvancedTop.Examples()
+}
+
+func (normalTop) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalTop) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalTop) Init() error {
+	return nil
+}
+
+func (normalTop) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return AdvancedTop.Run(m)
+}
+
+///////////
+//       //
+// stats //
+//       //
+///////////
+
+var NormalStats = normalStats{}
+
+type normalStats struct{}
+
+func (c normalStats) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (normalStats) Permitted(m *core.EventMessage) bool {
+	return AdvancedStats.Permitted(m)
+}
+
+func (normalStats) Names() []string {
+	return AdvancedStats.Names()
+}
+
+func (normalStats) Description() string {
+	return AdvancedStats.Description()
+}
+
+func (normalStats) UsageArgs() string {
+	return AdvancedStats.UsageArgs()
+}
+
+func (c normalStats) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (normalStats) Examples() []string {
+	return AdvancedStats.Examples()
+}
+
+func (normalStats) Parent() core.CommandStatic {
+	return Normal
+}
+
+func (normalStats) Children() core.CommandsStatic {
+	return nil
+}
+
+func (normalStats) Init() error {
+	return nil
+}
+
+func (normalStats) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return AdvancedStats.Run(m)
+}
+
 ////////////
 //        //
 // redeem //
@@ -63,19 +63,16 @@ func (advanced) Children() core.CommandsStatic {
 		AdvancedNow,
 		AdvancedConvert,
 		AdvancedTimestamp,
+		AdvancedUntil,
 		AdvancedTimezone,
+		AdvancedFormat,
 		AdvancedRemind,
 	}
 }
 
 func (advanced) Init() error {
-	go func() {
-		for {
-			runUpcoming()
-			time.Sleep(2 * time.Minute)
-		}
-	}()
-
+	remindReportMissed()
+	reminders.Start(2 * time.Minute)
 	return nil
 }
 
@@ -83,6 +80,25 @@ func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
 	return m.Usage(), core.UrrMissingArgs, nil
 }
 
+// Formats t using the place's time format, on Discord native timestamps are
+// used.
+func formatTime(m *core.EventMessage, t time.Time) (string, error) {
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", err
+	}
+
+	format, err := FormatGet(here)
+	if err != nil {
+		return "", err
+	}
+
+	if m.Frontend.Type() == discord.Frontend.Type() {
+		return FormatTimeDiscord(t, format), nil
+	}
+	return FormatTime(t, format), nil
+}
+
 /////////
 //     //
 // now //
@@ -145,7 +161,7 @@ func (c advancedNow) Run(m *core.EventMessage) (any, core.Urr, error) {
 }
 
 func (c advancedNow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
-	now, cmdTzSet, urr, err := c.core(m)
+	now, format, cmdTzSet, urr, err := c.core(m)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -153,25 +169,31 @@ func (c advancedNow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr,
 	cmdTzSet = discord.PlaceInBackticks(cmdTzSet)
 
 	embed := &dg.MessageEmbed{
-		Description: c.fmt(urr, m, now, cmdTzSet),
+		Description: c.fmt(urr, m, now, format, cmdTzSet),
 	}
 
 	return embed, urr, nil
 }
 
 func (c advancedNow) text(m *core.EventMessage) (string, core.Urr, error) {
-	now, cmdTzSet, urr, err := c.core(m)
+	now, format, cmdTzSet, urr, err := c.core(m)
 	if err != nil {
 		return "", nil, err
 	}
 	cmdTzSet = fmt.Sprintf("'%s'", cmdTzSet)
-	return c.fmt(urr, m, now, cmdTzSet), urr, nil
+	return c.fmt(urr, m, now, format, cmdTzSet), urr, nil
 }
 
-func (advancedNow) fmt(urr core.Urr, m *core.EventMessage, now time.Time, cmdTzSet string) string {
+func (advancedNow) fmt(urr core.Urr, m *core.EventMessage, now time.Time, format, cmdTzSet string) string {
 	switch urr {
 	case nil:
-		return now.Format(time.RFC1123)
+		// the current time relative to now would always be "now"
+		if format == FormatRelative {
+			format = Format24h
+		}
+		// Discord's native timestamps are not used here since they would show
+		// the time in the viewer's timezone instead of the person's
+		return FormatTime(now, format)
 	case UrrTimezoneNotSet:
 		mention, err := m.Author.Mention()
 		if err != nil {
@@ -186,7 +208,7 @@ func (advancedNow) fmt(urr core.Urr, m *core.EventMessage, now time.Time, cmdTzS
 	}
 }
 
-func (advancedNow) core(m *core.EventMessage) (time.Time, string, core.Urr, error) {
+func (advancedNow) core(m *core.EventMessage) (time.Time, string, string, core.Urr, error) {
 	cmdTzSet := core.Format(AdvancedTimezoneSet, m.Command.Prefix)
 
 	var person int64
@@ -198,16 +220,21 @@ func (advancedNow) core(m *core.EventMessage) (time.Time, string, core.Urr, erro
 	}
 
 	if err != nil {
-		return time.Time{}, cmdTzSet, UrrPersonNotFound, nil
+		return time.Time{}, "", cmdTzSet, UrrPersonNotFound, nil
 	}
 
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return time.Time{}, cmdTzSet, nil, err
+		return time.Time{}, "", cmdTzSet, nil, err
+	}
+
+	format, err := FormatGet(here)
+	if err != nil {
+		return time.Time{}, "", cmdTzSet, nil, err
 	}
 
-	now, urr, err := Now(person, here)
-	return now, cmdTzSet, urr, err
+	now, urr, err := Now(person)
+	return now, format, cmdTzSet, urr, err
 }
 
 /////////////
@@ -308,7 +335,24 @@ func (advancedConvert) fmt(urr error, t string) string {
 func (advancedConvert) core(m *core.EventMessage) (string, core.Urr, error) {
 	target := m.Command.Args[0]
 	tz := m.Command.Args[1]
-	return Convert(target, tz)
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+
+	format, err := FormatGet(here)
+	if err != nil {
+		return "", nil, err
+	}
+
+	t, urr, err := Convert(target, tz)
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+	// Discord's native timestamps are not used since they would ignore the
+	// given timezone
+	return FormatTime(t, format), nil, nil
 }
 
 ///////////////
@@ -422,14 +466,142 @@ func (advancedTimestamp) core(m *core.EventMessage) (time.Time, core.Urr, error)
 		return time.Time{}, nil, err
 	}
 
+	when := m.RawArgs(0)
+
+	return Timestamp(when, author)
+}
+
+///////////
+//       //
+// until //
+//       //
+///////////
+
+var AdvancedUntil = advancedUntil{}
+
+type advancedUntil struct{}
+
+func (c advancedUntil) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedUntil) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedUntil) Names() []string {
+	return []string{
+		"until",
+		"countdown",
+	}
+}
+
+func (advancedUntil) Description() string {
+	return "See how much time is left until the given datetime."
+}
+
+func (advancedUntil) UsageArgs() string {
+	return "<when...>"
+}
+
+func (c advancedUntil) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedUntil) Examples() []string {
+	return []string{
+		"friday at 8pm",
+		"december 25",
+	}
+}
+
+func (advancedUntil) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedUntil) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedUntil) Init() error {
+	return nil
+}
+
+func (c advancedUntil) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedUntil) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	t, _, _, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Discord keeps relative timestamps up to date by itself
+	countdown := fmt.Sprintf("<t:%d:R> (<t:%d:F>)", t.Unix(), t.Unix())
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, countdown),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedUntil) text(m *core.EventMessage) (string, core.Urr, error) {
+	t, d, format, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	countdown := formatDuration(d)
+	if d < 0 {
+		countdown += " ago"
+	}
+	if format != FormatRelative {
+		countdown = fmt.Sprintf("%s (%s)", countdown, FormatTime(t, format))
+	}
+
+	return c.fmt(urr, countdown), urr, nil
+}
+
+func (advancedUntil) fmt(urr core.Urr, countdown string) string {
+	switch urr {
+	case nil:
+		return countdown
+	case UrrInvalidTime:
+		return "I can't understand what date that is."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedUntil) core(m *core.EventMessage) (time.Time, time.Duration, string, core.Urr, error) {
+	author, err := m.Author.Scope()
+	if err != nil {
+		return time.Time{}, 0, "", nil, err
+	}
+
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return time.Time{}, nil, err
+		return time.Time{}, 0, "", nil, err
 	}
 
-	when := m.RawArgs(0)
+	format, err := FormatGet(here)
+	if err != nil {
+		return time.Time{}, 0, "", nil, err
+	}
 
-	return Timestamp(when, author, here)
+	t, d, urr, err := Until(m.RawArgs(0), author)
+	return t, d, format, urr, err
 }
 
 //////////////
@@ -587,12 +759,7 @@ func (advancedTimezoneShow) core(m *core.EventMessage) (string, error) {
 		return "", err
 	}
 
-	here, err := m.Here.ScopeLogical()
-	if err != nil {
-		return "", err
-	}
-
-	return TimezoneShow(author, here)
+	return TimezoneShow(author)
 }
 
 //////////////////
@@ -706,12 +873,7 @@ func (advancedTimezoneSet) core(m *core.EventMessage) (string, core.Urr, error)
 		return "", nil, err
 	}
 
-	here, err := m.Here.ScopeLogical()
-	if err != nil {
-		return "", nil, err
-	}
-
-	return TimezoneSet(tz, author, here)
+	return TimezoneSet(tz, author)
 }
 
 /////////////////////
@@ -806,11 +968,271 @@ func (advancedTimezoneDelete) core(m *core.EventMessage) error {
 	if err != nil {
 		return err
 	}
+	return TimezoneDelete(author)
+}
+
+////////////
+//        //
+// format //
+//        //
+////////////
+
+var AdvancedFormat = advancedFormat{}
+
+type advancedFormat struct{}
+
+func (c advancedFormat) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFormat) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFormat) Names() []string {
+	return []string{
+		"format",
+	}
+}
+
+func (advancedFormat) Description() string {
+	return "Show or set how times are displayed."
+}
+
+func (c advancedFormat) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedFormat) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFormat) Examples() []string {
+	return nil
+}
+
+func (advancedFormat) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedFormat) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedFormatShow,
+		AdvancedFormatSet,
+	}
+}
+
+func (advancedFormat) Init() error {
+	return nil
+}
+
+func (advancedFormat) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+/////////////////
+//             //
+// format show //
+//             //
+/////////////////
+
+var AdvancedFormatShow = advancedFormatShow{}
+
+type advancedFormatShow struct{}
+
+func (c advancedFormatShow) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedFormatShow) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedFormatShow) Names() []string {
+	return core.AliasesShow
+}
+
+func (advancedFormatShow) Description() string {
+	return "Show the time format used in this place."
+}
+
+func (advancedFormatShow) UsageArgs() string {
+	return ""
+}
+
+func (c advancedFormatShow) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFormatShow) Examples() []string {
+	return nil
+}
+
+func (advancedFormatShow) Parent() core.CommandStatic {
+	return AdvancedFormat
+}
+
+func (advancedFormatShow) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFormatShow) Init() error {
+	return nil
+}
+
+func (c advancedFormatShow) Run(m *core.EventMessage) (any, core.Urr, error) {
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFormatShow) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	format, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(discord.PlaceInBackticks(format)),
+	}
+
+	return embed, nil, nil
+}
+
+func (c advancedFormatShow) text(m *core.EventMessage) (string, core.Urr, error) {
+	format, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(fmt.Sprintf("'%s'", format)), nil, nil
+}
+
+func (advancedFormatShow) fmt(format string) string {
+	return "The time format is set to " + format
+}
+
+func (advancedFormatShow) core(m *core.EventMessage) (string, error) {
 	here, err := m.Here.ScopeLogical()
 	if err != nil {
-		return err
+		return "", err
 	}
-	return TimezoneDelete(author, here)
+	return FormatGet(here)
+}
+
+////////////////
+//            //
+// format set //
+//            //
+////////////////
+
+var AdvancedFormatSet = advancedFormatSet{}
+
+type advancedFormatSet struct{}
+
+func (c advancedFormatSet) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (advancedFormatSet) Permitted(m *core.EventMessage) bool {
+	// the format applies to the entire place
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advancedFormatSet) Names() []string {
+	return core.AliasesSet
+}
+
+func (advancedFormatSet) Description() string {
+	return "Set the time format used in this place."
+}
+
+func (advancedFormatSet) UsageArgs() string {
+	return "(" + strings.Join(Formats, " | ") + ")"
+}
+
+func (c advancedFormatSet) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedFormatSet) Examples() []string {
+	return nil
+}
+
+func (advancedFormatSet) Parent() core.CommandStatic {
+	return AdvancedFormat
+}
+
+func (advancedFormatSet) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedFormatSet) Init() error {
+	return nil
+}
+
+func (c advancedFormatSet) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedFormatSet) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	format, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, discord.PlaceInBackticks(format)),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedFormatSet) text(m *core.EventMessage) (string, core.Urr, error) {
+	format, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+	return c.fmt(urr, fmt.Sprintf("'%s'", format)), urr, nil
+}
+
+func (advancedFormatSet) fmt(urr core.Urr, format string) string {
+	switch urr {
+	case nil:
+		return "The time format has been set to " + format
+	case UrrInvalidFormat:
+		return fmt.Sprintf("Unknown format %s, expected one of: %s", format, strings.Join(Formats, ", "))
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedFormatSet) core(m *core.EventMessage) (string, core.Urr, error) {
+	format := strings.ToLower(m.Command.Args[0])
+
+	here, err := m.Here.ScopeLogical()
+	if err != nil {
+		return "", nil, err
+	}
+
+	urr, err := FormatSet(here, format)
+	return format, urr, err
 }
 
 ////////////
@@ -862,6 +1284,7 @@ func (advancedRemind) Children() core.CommandsStatic {
 		AdvancedRemindAdd,
 		AdvancedRemindDelete,
 		AdvancedRemindList,
+		AdvancedRemindSnooze,
 	}
 }
 
@@ -900,7 +1323,7 @@ func (advancedRemindAdd) Description() string {
 }
 
 func (advancedRemindAdd) UsageArgs() string {
-	return "<what> (in|on) <when>"
+	return "<what> ((in|on) <when> | every <interval>)"
 }
 
 func (c advancedRemindAdd) Category() core.CommandCategory {
@@ -908,7 +1331,12 @@ func (c advancedRemindAdd) Category() core.CommandCategory {
 }
 
 func (advancedRemindAdd) Examples() []string {
-	return nil
+	return []string{
+		"take out the trash in 2 hours",
+		"stretch every 2h",
+		"water the plants every monday at 9am",
+		"take my pills every day at 21:30",
+	}
 }
 
 func (advancedRemindAdd) Parent() core.CommandStatic {
@@ -935,18 +1363,24 @@ func (c advancedRemindAdd) Run(m *core.EventMessage) (any, core.Urr, error) {
 	if urr != nil {
 		return fmt.Sprint(urr), urr, nil
 	}
-	return fmt.Sprintf("%s (#%d)", t.Format(time.RFC1123), id), nil, nil
+	when, err := formatTime(m, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	return fmt.Sprintf("%s (#%d)", when, id), nil, nil
 }
 
 func (advancedRemindAdd) core(m *core.EventMessage) (time.Time, int64, core.Urr, error) {
 	rxWhat := `(?P<what>.+)`
 	rxWhen := `(in|on)\s+(?P<when>.+)`
+	rxEvery := `every\s+(?P<every>.+)`
 
-	re := regexp.MustCompile(`^` + rxWhat + `\s+` + rxWhen + `$`)
+	re := regexp.MustCompile(`^` + rxWhat + `\s+` + `(` + rxWhen + `|` + rxEvery + `)` + `$`)
 	groupNames := re.SubexpNames()
 
 	var when string
 	var what string
+	var every string
 
 	for _, match := range re.FindAllStringSubmatch(m.RawArgs(0), -1) {
 		for i, text := range match {
@@ -957,6 +1391,8 @@ func (advancedRemindAdd) core(m *core.EventMessage) (time.Time, int64, core.Urr,
 				when = text
 			case "what":
 				what = text
+			case "every":
+				every = text
 			}
 		}
 	}
@@ -976,6 +1412,9 @@ func (advancedRemindAdd) core(m *core.EventMessage) (time.Time, int64, core.Urr,
 		return time.Time{}, -1, nil, err
 	}
 
+	if every != "" {
+		return RemindAddRecurring(every, what, m.ID, author, hereExact, hereLogical)
+	}
 	return RemindAdd(when, what, m.ID, author, hereExact, hereLogical)
 }
 
@@ -1170,7 +1609,20 @@ func (c advancedRemindList) discord(m *core.EventMessage) (string, core.Urr, err
 
 	for _, r := range rs {
 		remaining := r.When.Sub(now).Round(time.Second)
-		fmt.Fprintf(&resp, "%d: %s (%s remaining)\n", r.ID, r.What, remaining)
+		switch {
+		case r.Dead:
+			fmt.Fprintf(&resp, "%d: %s (failed to deliver, snooze it to try again)\n", r.ID, r.What)
+		case r.Attempts > 0 && r.due().After(now):
+			retry := r.due().Sub(now).Round(time.Second)
+			fmt.Fprintf(&resp, "%d: %s (%s overdue, retrying in %s, failed attempts: %d)\n",
+				r.ID, r.What, -remaining, retry, r.Attempts)
+		case remaining < 0:
+			fmt.Fprintf(&resp, "%d: %s (%s overdue, delivering shortly)\n", r.ID, r.What, -remaining)
+		case r.Recur == "":
+			fmt.Fprintf(&resp, "%d: %s (%s remaining)\n", r.ID, r.What, remaining)
+		default:
+			fmt.Fprintf(&resp, "%d: %s (%s remaining, every %s)\n", r.ID, r.What, remaining, r.Recur)
+		}
 	}
 
 	return resp.String(), nil, nil
@@ -1187,5 +1639,144 @@ func (advancedRemindList) core(m *core.EventMessage) ([]reminder, core.Urr, erro
 		return nil, nil, err
 	}
 
-	return RemindList(author, here)
+	hereLogical, err := m.Here.ScopeLogical()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return RemindList(author, here, hereLogical)
+}
+
+///////////////////
+//               //
+// remind snooze //
+//               //
+///////////////////
+
+var AdvancedRemindSnooze = advancedRemindSnooze{}
+
+type advancedRemindSnooze struct{}
+
+func (c advancedRemindSnooze) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRemindSnooze) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRemindSnooze) Names() []string {
+	return []string{
+		"snooze",
+	}
+}
+
+func (advancedRemindSnooze) Description() string {
+	return "Push a reminder forward."
+}
+
+func (advancedRemindSnooze) UsageArgs() string {
+	return "<id> <duration>"
+}
+
+func (c advancedRemindSnooze) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRemindSnooze) Examples() []string {
+	return []string{
+		"42 10m",
+		"42 1h30m",
+	}
+}
+
+func (advancedRemindSnooze) Parent() core.CommandStatic {
+	return AdvancedRemind
+}
+
+func (advancedRemindSnooze) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRemindSnooze) Init() error {
+	return nil
+}
+
+func (c advancedRemindSnooze) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+
+	switch m.Frontend.Type() {
+	case discord.Frontend.Type():
+		return c.discord(m)
+	default:
+		return c.text(m)
+	}
+}
+
+func (c advancedRemindSnooze) discord(m *core.EventMessage) (*dg.MessageEmbed, core.Urr, error) {
+	when, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	formatted, err := formatTime(m, when)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	embed := &dg.MessageEmbed{
+		Description: c.fmt(urr, formatted),
+	}
+
+	return embed, urr, nil
+}
+
+func (c advancedRemindSnooze) text(m *core.EventMessage) (string, core.Urr, error) {
+	when, urr, err := c.core(m)
+	if err != nil {
+		return "", nil, err
+	}
+
+	formatted, err := formatTime(m, when)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return c.fmt(urr, formatted), urr, nil
+}
+
+func (advancedRemindSnooze) fmt(urr core.Urr, when string) string {
+	switch urr {
+	case nil:
+		return "Snoozed reminder until " + when
+	case UrrReminderNotFound:
+		return "Reminder not found. Maybe you are not the one who created the reminder?"
+	case UrrInvalidRemindID:
+		return "The ID you provided is invalid, expected a number."
+	case UrrInvalidDuration:
+		return "Can't understand duration, use the following format: 1h30m (snoozes for 1 hour and 30 minutes) or more simply 10m (snoozes for 10 minutes)."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+func (advancedRemindSnooze) core(m *core.EventMessage) (time.Time, core.Urr, error) {
+	id, err := strconv.ParseInt(m.Command.Args[0], 10, 64)
+	if err != nil {
+		return time.Time{}, UrrInvalidRemindID, nil
+	}
+
+	d, err := time.ParseDuration(m.Command.Args[1])
+	if err != nil {
+		return time.Time{}, UrrInvalidDuration, nil
+	}
+
+	author, err := m.Author.Scope()
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	return RemindSnooze(id, author, d)
 }
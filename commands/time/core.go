@@ -1,13 +1,15 @@
 package time
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/kvlach/janitorjeff/core"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 	"github.com/tj/go-naturaldate"
 )
@@ -20,6 +22,8 @@ var (
 	UrrNoReminders      = core.UrrNew("couldn't find any reminders")
 	UrrReminderNotFound = core.UrrNew("couldn't find person's reminder")
 	UrrOldTime          = core.UrrNew("given time has already passed")
+	UrrInvalidRecur     = core.UrrNew("could not parse given recurrence")
+	UrrInvalidDuration  = core.UrrNew("could not parse given duration")
 )
 
 type reminder struct {
@@ -30,6 +34,22 @@ type reminder struct {
 	When   time.Time
 	What   string
 	MsgID  string
+	// Empty if the reminder is not recurring
+	Recur        string
+	PlaceLogical int64
+	// Failed delivery attempts, RetryAt is zero if the reminder is not being
+	// retried and Dead is true if delivery has been given up on.
+	Attempts int
+	RetryAt  time.Time
+	Dead     bool
+}
+
+// Returns the time of the next delivery attempt.
+func (r reminder) due() time.Time {
+	if !r.RetryAt.IsZero() {
+		return r.RetryAt
+	}
+	return r.When
 }
 
 //////////////
@@ -38,24 +58,31 @@ type reminder struct {
 //          //
 //////////////
 
-func dbRemindAdd(person, place, when int64, what, msgID string) (int64, error) {
+func dbRemindAdd(person, place, placeLogical, when int64, what, msgID, recur string) (int64, error) {
 	db := core.DB
 	db.Lock.Lock()
 	defer db.Lock.Unlock()
 
+	var _recur any
+	if recur != "" {
+		_recur = recur
+	}
+
 	var id int64
 	err := db.DB.QueryRow(`
-	INSERT INTO cmd_time_reminders(person, place, time, what, msg_id)
-	VALUES ($1, $2, $3, $4, $5)
-	RETURNING id;`, person, place, when, what, msgID).Scan(&id)
+	INSERT INTO cmd_time_reminders(person, place, place_logical, time, what, msg_id, recur)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id;`, person, place, placeLogical, when, what, msgID, _recur).Scan(&id)
 
 	log.Debug().
 		Err(err).
 		Int64("person", person).
 		Int64("place", place).
+		Int64("place_logical", placeLogical).
 		Int64("when", when).
 		Str("what", what).
 		Str("msgID", msgID).
+		Str("recur", recur).
 		Msg("added reminder")
 
 	if err != nil {
@@ -67,18 +94,31 @@ func dbRemindAdd(person, place, when int64, what, msgID string) (int64, error) {
 func scanReminders(rows *sql.Rows) ([]reminder, error) {
 	var rs []reminder
 	for rows.Next() {
-		var id, person, place, timestamp int64
+		var id, person, place, placeLogical, timestamp int64
 		var what, msgID string
-		if err := rows.Scan(&id, &person, &place, &timestamp, &what, &msgID); err != nil {
+		var recur sql.NullString
+		var attempts int
+		var retryAt sql.NullInt64
+		var dead bool
+		err := rows.Scan(&id, &person, &place, &placeLogical, &timestamp, &what, &msgID, &recur,
+			&attempts, &retryAt, &dead)
+		if err != nil {
 			return nil, err
 		}
 		r := reminder{
-			ID:     id,
-			Person: person,
-			Place:  place,
-			When:   time.Unix(timestamp, 0).UTC(),
-			What:   what,
-			MsgID:  msgID,
+			ID:           id,
+			Person:       person,
+			Place:        place,
+			When:         time.Unix(timestamp, 0).UTC(),
+			What:         what,
+			MsgID:        msgID,
+			Recur:        recur.String,
+			PlaceLogical: placeLogical,
+			Attempts:     attempts,
+			Dead:         dead,
+		}
+		if retryAt.Valid {
+			r.RetryAt = time.Unix(retryAt.Int64, 0).UTC()
 		}
 		rs = append(rs, r)
 		log.Debug().Interface("reminder", r).Msg("found reminder")
@@ -92,7 +132,8 @@ func dbRemindList(person, place int64) ([]reminder, error) {
 	defer db.Lock.RUnlock()
 
 	rows, err := db.DB.Query(`
-		SELECT id, person, place, time, what, msg_id
+		SELECT id, person, place, place_logical, time, what, msg_id, recur,
+			attempts, retry_at, dead
 		FROM cmd_time_reminders
 		WHERE person = $1 and place = $2
 	`, person, place)
@@ -118,16 +159,55 @@ func dbRemindList(person, place int64) ([]reminder, error) {
 	return rs, err
 }
 
-func dbRemindUpcoming(nowSeconds int64) ([]reminder, error) {
+// Returns the reminders of any of the persons that were created in any of the
+// given logical places.
+func dbRemindListShared(persons, places []int64) ([]reminder, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, person, place, place_logical, time, what, msg_id, recur,
+			attempts, retry_at, dead
+		FROM cmd_time_reminders
+		WHERE person = ANY($1) and place_logical = ANY($2)
+		ORDER BY time
+	`, pq.Array(persons), pq.Array(places))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rs, err := scanReminders(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Ints64("persons", persons).
+		Ints64("places", places).
+		Int("#reminders", len(rs)).
+		Msg("got shared reminders")
+
+	return rs, err
+}
+
+// Returns all the reminders that are not dead and are due before the given
+// time.
+func dbRemindUpcoming(before int64) ([]reminder, error) {
 	db := core.DB
 	db.Lock.RLock()
 	defer db.Lock.RUnlock()
 
 	rows, err := db.DB.Query(`
-		SELECT id, person, place, time, what, msg_id
+		SELECT id, person, place, place_logical, time, what, msg_id, recur,
+			attempts, retry_at, dead
 		FROM cmd_time_reminders
-		WHERE time - $1 < 300
-	`, nowSeconds)
+		WHERE dead = $1 and COALESCE(retry_at, time) < $2
+	`, false, before)
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +222,7 @@ func dbRemindUpcoming(nowSeconds int64) ([]reminder, error) {
 
 	log.Debug().
 		Err(err).
-		Int64("when", nowSeconds).
+		Int64("before", before).
 		Int("#reminders", len(rs)).
 		Msg("got upcoming reminders")
 
@@ -166,6 +246,93 @@ func dbRemindDelete(id int64) error {
 	return err
 }
 
+// Returns the reminder with the given id, if it doesn't exist then it returns
+// false.
+func dbRemindGet(id int64) (reminder, bool, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT id, person, place, place_logical, time, what, msg_id, recur,
+			attempts, retry_at, dead
+		FROM cmd_time_reminders
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return reminder{}, false, err
+	}
+	defer rows.Close()
+
+	rs, err := scanReminders(rows)
+	if err != nil {
+		return reminder{}, false, err
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int("#reminders", len(rs)).
+		Msg("got reminder")
+
+	if len(rs) == 0 {
+		return reminder{}, false, err
+	}
+	return rs[0], true, err
+}
+
+// Sets the reminder's time and clears any failed delivery attempts, which
+// also means that dead reminders are revived.
+func dbRemindSetTime(id, when int64) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_time_reminders
+		SET time = $1, attempts = 0, retry_at = NULL, dead = $2, error = NULL
+		WHERE id = $3`, when, false, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int64("when", when).
+		Msg("changed reminder's time")
+
+	return err
+}
+
+// Records a failed delivery attempt. If dead is true then the reminder will
+// not be retried, otherwise it will be retried at retryAt.
+func dbRemindFailed(id int64, attempts int, retryAt int64, dead bool, reason string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	var _retryAt any
+	if !dead {
+		_retryAt = retryAt
+	}
+
+	_, err := db.DB.Exec(`
+		UPDATE cmd_time_reminders
+		SET attempts = $1, retry_at = $2, dead = $3, error = $4
+		WHERE id = $5`, attempts, _retryAt, dead, reason, id)
+
+	log.Debug().
+		Err(err).
+		Int64("id", id).
+		Int("attempts", attempts).
+		Int64("retry_at", retryAt).
+		Bool("dead", dead).
+		Str("reason", reason).
+		Msg("recorded failed reminder delivery")
+
+	return err
+}
+
 func dbRemindExists(id, person int64) (bool, error) {
 	db := core.DB
 	db.Lock.RLock()
@@ -198,10 +365,10 @@ func dbRemindExists(id, person int64) (bool, error) {
 //     //
 /////////
 
-func Now(person, place int64) (time.Time, core.Urr, error) {
+func Now(person int64) (time.Time, core.Urr, error) {
 	now := time.Now().UTC()
 
-	tz, err := core.DB.PersonGet("cmd_time_tz", person, place).Str()
+	tz, err := core.DB.PersonGlobalGet("cmd_time_tz", person).Str()
 	if err != nil {
 		return now, nil, err
 	}
@@ -214,28 +381,32 @@ func Now(person, place int64) (time.Time, core.Urr, error) {
 	return now.In(loc), nil, nil
 }
 
-func Convert(target, tz string) (string, core.Urr, error) {
+// Convert returns the target (a unix timestamp or "now") in the given timezone.
+func Convert(target, tz string) (time.Time, core.Urr, error) {
 	var t time.Time
 	if target == "now" {
 		t = time.Now().UTC()
 	} else {
 		timestamp, err := strconv.ParseInt(target, 10, 64)
 		if err != nil {
-			return "", UrrTimestamp, nil
+			return time.Time{}, UrrTimestamp, nil
 		}
 		t = time.Unix(timestamp, 0).UTC()
 	}
 
 	loc, err := time.LoadLocation(tz)
 	if err != nil {
-		return "", UrrTimezone, nil
+		return time.Time{}, UrrTimezone, nil
 	}
 
-	return t.In(loc).Format(time.UnixDate), nil, nil
+	return t.In(loc), nil, nil
 }
 
-func Time(when string, person, place int64) (time.Time, core.Urr, error) {
-	tz, err := core.DB.PersonGet("cmd_time_tz", person, place).Str()
+func Time(when string, person int64) (time.Time, core.Urr, error) {
+	tz, err := core.DB.PersonGlobalGet("cmd_time_tz", person).Str()
+	if err != nil {
+		return time.Time{}, nil, err
+	}
 	loc, err := time.LoadLocation(tz)
 	if err != nil {
 		return time.Time{}, nil, err
@@ -251,33 +422,33 @@ func Time(when string, person, place int64) (time.Time, core.Urr, error) {
 	return t, nil, nil
 }
 
-func Timestamp(when string, person, place int64) (time.Time, core.Urr, error) {
-	t, urr, err := Time(when, person, place)
+func Timestamp(when string, person int64) (time.Time, core.Urr, error) {
+	t, urr, err := Time(when, person)
 	if urr != nil || err != nil {
 		return time.Time{}, urr, err
 	}
 	return t, nil, nil
 }
 
-func TimezoneShow(person, place int64) (string, error) {
-	return core.DB.PersonGet("cmd_time_tz", person, place).Str()
+func TimezoneShow(person int64) (string, error) {
+	return core.DB.PersonGlobalGet("cmd_time_tz", person).Str()
 }
 
-func TimezoneSet(tz string, person, place int64) (string, core.Urr, error) {
+func TimezoneSet(tz string, person int64) (string, core.Urr, error) {
 	loc, err := time.LoadLocation(tz)
 	if err != nil {
 		return tz, UrrTimezone, nil
 	}
 	tz = loc.String()
-	return tz, nil, core.DB.PersonSet("cmd_time_tz", person, place, tz)
+	return tz, nil, core.DB.PersonGlobalSet("cmd_time_tz", person, tz)
 }
 
-func TimezoneDelete(person, place int64) error {
-	return core.DB.PersonSet("cmd_time_tz", person, place, "UTC")
+func TimezoneDelete(person int64) error {
+	return core.DB.PersonGlobalSet("cmd_time_tz", person, "UTC")
 }
 
 func RemindAdd(when, what, msgID string, person, placeExact, placeLogical int64) (time.Time, int64, core.Urr, error) {
-	t, urr, err := Time(when, person, placeLogical)
+	t, urr, err := Time(when, person)
 	if urr != nil || err != nil {
 		return t, -1, urr, err
 	}
@@ -286,14 +457,124 @@ func RemindAdd(when, what, msgID string, person, placeExact, placeLogical int64)
 		return t, -1, UrrOldTime, nil
 	}
 
-	id, err := dbRemindAdd(person, placeExact, t.UTC().Unix(), what, msgID)
+	id, err := dbRemindAdd(person, placeExact, placeLogical, t.UTC().Unix(), what, msgID, "")
+
+	// in case the reminder needs to happen close to immediately
+	reminders.Poll()
+
+	return t, id, nil, err
+}
+
+// RemindAfter is the same as RemindAdd, except that the reminder happens after
+// the given duration instead of at a time described in natural language.
+func RemindAfter(d time.Duration, what, msgID string, person, placeExact, placeLogical int64) (time.Time, int64, core.Urr, error) {
+	if d <= 0 {
+		return time.Time{}, -1, UrrInvalidDuration, nil
+	}
+
+	t := time.Now().Add(d)
+	id, err := dbRemindAdd(person, placeExact, placeLogical, t.UTC().Unix(), what, msgID, "")
 
 	// in case the reminder needs to happen close to immediately
-	runUpcoming()
+	reminders.Poll()
 
 	return t, id, nil, err
 }
 
+func personLocation(person int64) (*time.Location, error) {
+	tz, err := core.DB.PersonGlobalGet("cmd_time_tz", person).Str()
+	if err != nil {
+		return nil, err
+	}
+	return time.LoadLocation(tz)
+}
+
+// RemindAddRecurring creates a reminder that repeats based on every, for
+// example "2h" or "monday at 9am". Times of day are in the person's timezone.
+// Returns the time of the first occurrence.
+func RemindAddRecurring(every, what, msgID string, person, placeExact, placeLogical int64) (time.Time, int64, core.Urr, error) {
+	rec, ok := parseRecurrence(every)
+	if !ok {
+		return time.Time{}, -1, UrrInvalidRecur, nil
+	}
+
+	loc, err := personLocation(person)
+	if err != nil {
+		return time.Time{}, -1, nil, err
+	}
+
+	t := rec.next(time.Now(), loc)
+
+	id, err := dbRemindAdd(person, placeExact, placeLogical, t.UTC().Unix(), what, msgID, every)
+
+	reminders.Poll()
+
+	return t, id, nil, err
+}
+
+// RemindSnooze pushes the reminder forward by d. If the reminder is overdue,
+// then it is pushed forward starting from now.
+func RemindSnooze(id, person int64, d time.Duration) (time.Time, core.Urr, error) {
+	if d <= 0 {
+		return time.Time{}, UrrInvalidDuration, nil
+	}
+
+	exists, err := dbRemindExists(id, person)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	if !exists {
+		return time.Time{}, UrrReminderNotFound, nil
+	}
+
+	r, _, err := dbRemindGet(id)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	when := r.When
+	if now := time.Now(); when.Before(now) {
+		when = now
+	}
+	when = when.Add(d)
+
+	if err := dbRemindSetTime(id, when.UTC().Unix()); err != nil {
+		return time.Time{}, nil, err
+	}
+
+	reminders.Poll()
+
+	return when, nil, nil
+}
+
+// Deletes the reminder after it has been delivered or, if it is recurring,
+// schedules the next occurrence.
+func remindDone(r reminder) error {
+	if r.Recur == "" {
+		return dbRemindDelete(r.ID)
+	}
+
+	rec, ok := parseRecurrence(r.Recur)
+	if !ok {
+		log.Error().Interface("reminder", r).Msg("invalid recurrence, deleting reminder")
+		return dbRemindDelete(r.ID)
+	}
+
+	loc, err := personLocation(r.Person)
+	if err != nil {
+		return err
+	}
+
+	// skip any occurrences that were missed
+	now := time.Now()
+	next := rec.next(r.When, loc)
+	for !next.After(now) {
+		next = rec.next(next, loc)
+	}
+
+	return dbRemindSetTime(r.ID, next.UTC().Unix())
+}
+
 func RemindDelete(id, person int64) (core.Urr, error) {
 	// if the person their own reminder, but from a different place then we
 	// allow that
@@ -307,8 +588,27 @@ func RemindDelete(id, person int64) (core.Urr, error) {
 	return nil, dbRemindDelete(id)
 }
 
-func RemindList(person, place int64) ([]reminder, core.Urr, error) {
-	rs, err := dbRemindList(person, place)
+// RemindList returns the person's reminders that were created in the exact
+// place. If the logical place is in a group that shares reminders, then the
+// reminders created anywhere in the group by any of the person's linked
+// accounts are returned instead.
+func RemindList(person, placeExact, placeLogical int64) ([]reminder, core.Urr, error) {
+	places, err := core.DB.PlaceSharedMembers(placeLogical, core.ShareReminders)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rs []reminder
+	if len(places) == 1 {
+		rs, err = dbRemindList(person, placeExact)
+	} else {
+		var persons []int64
+		persons, err = core.DB.PersonLinked(person)
+		if err != nil {
+			return nil, nil, err
+		}
+		rs, err = dbRemindListShared(persons, places)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -318,68 +618,152 @@ func RemindList(person, place int64) ([]reminder, core.Urr, error) {
 	return rs, nil, nil
 }
 
-type upcoming struct {
-	lock sync.RWMutex
-
-	// serves as a set essentially
-	waiting map[int64]struct{}
+var reminders = &core.Scheduler{
+	Name:        "reminders",
+	MaxAttempts: 5,
+	Backoff:     30 * time.Second,
+	Lookahead:   5 * time.Minute,
+	Upcoming:    remindUpcoming,
+	Run:         remindRun,
+	Retry:       remindRetry,
+	Dead:        remindDead,
 }
 
-var upcomingReminders = upcoming{}
+// How long a delivery is remembered for, must outlast all the retries.
+const remindDeliveredExpiry = 24 * time.Hour
 
-func (u *upcoming) add(r reminder) {
-	u.lock.Lock()
-	defer u.lock.Unlock()
+// Each occurrence gets its own key, so that snoozing or recurring reminders
+// are delivered again.
+func remindDeliveredKey(r reminder) string {
+	return fmt.Sprintf("cmd_time-reminder-delivered-%d-%d", r.ID, r.When.Unix())
+}
 
-	if u.waiting == nil {
-		u.waiting = map[int64]struct{}{}
+func remindUpcoming(before time.Time) ([]core.ScheduledTask, error) {
+	rs, err := dbRemindUpcoming(before.Unix())
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]core.ScheduledTask, len(rs))
+	for i, r := range rs {
+		tasks[i] = core.ScheduledTask{
+			ID:       r.ID,
+			When:     r.due(),
+			Attempts: r.Attempts,
+		}
 	}
+	return tasks, nil
+}
 
-	if _, ok := u.waiting[r.ID]; ok {
-		log.Debug().Int64("id", r.ID).Msg("reminder already in queue")
-		return
+func remindRun(t core.ScheduledTask) error {
+	r, exists, err := dbRemindGet(t.ID)
+	if err != nil {
+		return err
+	}
+	// the reminder may have been deleted or snoozed in the meantime
+	if !exists || r.Dead || r.due().After(time.Now()) {
+		return nil
 	}
 
-	u.waiting[r.ID] = struct{}{}
-	log.Debug().Int64("id", r.ID).Msg("added reminder to queue")
+	what := r.What
+	if time.Since(r.When) > time.Minute {
+		what = fmt.Sprintf("%s (late, was due on %s)", what, r.When.Format(time.RFC1123))
+	}
 
-	go func() {
-		time.Sleep(r.When.Sub(time.Now()))
+	ctx := context.Background()
+	key := remindDeliveredKey(r)
 
-		m, err := core.Frontends.CreateMessage(r.Person, r.Place, r.MsgID)
-		if err != nil {
-			panic(err)
-		}
+	delivered, err := core.RDB.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	// a previous attempt delivered the reminder but failed to update it, so
+	// only the update is left
+	if delivered > 0 {
+		return remindDone(r)
+	}
 
-		_, err = m.Client.Ping(r.What, nil)
-		if err != nil {
-			// TODO: retry
-			panic(err)
-		}
+	m, err := core.Frontends.CreateMessage(r.Person, r.Place, r.MsgID)
+	if err != nil {
+		return err
+	}
+	if _, err = m.Client.Ping(what, nil); err != nil {
+		return err
+	}
 
-		err = dbRemindDelete(r.ID)
-		if err != nil {
-			// TODO
-			panic(err)
-		}
-		u.del(r.ID)
-	}()
+	// returning an error from here on would get the reminder delivered again,
+	// instead the delivery is remembered and the update is retried the next
+	// time the reminder is picked up
+	if err := core.RDB.Set(ctx, key, nil, remindDeliveredExpiry).Err(); err != nil {
+		log.Error().Err(err).Interface("reminder", r).Msg("failed to remember delivery")
+	}
+	if err := remindDone(r); err != nil {
+		log.Error().Err(err).Interface("reminder", r).Msg("failed to update delivered reminder")
+	}
+	return nil
+}
+
+func remindRetry(t core.ScheduledTask, attempts int, at time.Time, err error) error {
+	return dbRemindFailed(t.ID, attempts, at.Unix(), false, err.Error())
 }
 
-func (u *upcoming) del(id int64) {
-	u.lock.Lock()
-	defer u.lock.Unlock()
-	delete(u.waiting, id)
+func remindDead(t core.ScheduledTask, attempts int, err error) error {
+	if err := dbRemindFailed(t.ID, attempts, 0, true, err.Error()); err != nil {
+		return err
+	}
+	remindReportDead(t.ID)
+	return nil
+}
+
+// Lets the person know that their reminder couldn't be delivered. The reminder
+// was most likely a reply to a message that has since been deleted, so the
+// report is sent to the same place without replying to anything.
+func remindReportDead(id int64) {
+	r, exists, err := dbRemindGet(id)
+	if err != nil || !exists {
+		log.Error().Err(err).Int64("id", id).Msg("failed to get dead reminder")
+		return
+	}
+
+	// it was only the update after the delivery that kept failing
+	if n, err := core.RDB.Exists(context.Background(), remindDeliveredKey(r)).Result(); err == nil && n > 0 {
+		log.Error().Interface("reminder", r).Msg("failed to update delivered reminder, giving up")
+		return
+	}
+
+	msg := fmt.Sprintf("I wasn't able to deliver your reminder #%d: %s (snooze it to try again)", r.ID, r.What)
+
+	m, err := core.Frontends.CreateMessage(r.Person, r.Place, "")
+	if err == nil {
+		_, err = m.Client.Ping(msg, nil)
+	}
+	if err != nil {
+		log.Error().
+			Err(err).
+			Interface("reminder", r).
+			Msg("failed to report dead reminder")
+	}
 }
 
-func runUpcoming() {
-	rs, err := dbRemindUpcoming(time.Now().Unix())
+// Logs the reminders that should have been delivered while the bot was
+// offline, they will be delivered as soon as the scheduler starts. The owners
+// find out through the delivery itself, which mentions the original due time,
+// and until then the reminders are listed as overdue.
+func remindReportMissed() {
+	rs, err := dbRemindUpcoming(time.Now().Add(-time.Minute).Unix())
 	if err != nil {
-		// TODO
-		panic(err)
+		log.Error().Err(err).Msg("failed to get missed reminders")
+		return
+	}
+	if len(rs) == 0 {
+		return
 	}
 
-	for _, r := range rs {
-		upcomingReminders.add(r)
+	ids := make([]int64, len(rs))
+	for i, r := range rs {
+		ids[i] = r.ID
 	}
+	log.Warn().
+		Int("#reminders", len(rs)).
+		Ints64("ids", ids).
+		Msg("found missed reminders, delivering them late")
 }
@@ -0,0 +1,127 @@
+package time
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var UrrInvalidFormat = core.UrrNew("invalid time format")
+
+// The formats a place can choose from.
+const (
+	Format12h      = "12h"
+	Format24h      = "24h"
+	FormatISO      = "iso"
+	FormatRelative = "relative"
+)
+
+var Formats = []string{
+	Format12h,
+	Format24h,
+	FormatISO,
+	FormatRelative,
+}
+
+func FormatGet(place int64) (string, error) {
+	return core.DB.PlaceGet("cmd_time_format", place).Str()
+}
+
+// FormatSet sets the place's time format, must be one of Formats.
+func FormatSet(place int64, format string) (core.Urr, error) {
+	if !slices.Contains(Formats, format) {
+		return UrrInvalidFormat, nil
+	}
+	return nil, core.DB.PlaceSet("cmd_time_format", place, format)
+}
+
+// Returns a duration in a human-readable form, e.g. 2 days, 3 hours, 5 minutes.
+// Precision is limited to seconds.
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		d = -d
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+		{"second", time.Second},
+	}
+
+	var parts []string
+	for _, u := range units {
+		n := d / u.size
+		if n == 0 {
+			continue
+		}
+		d -= n * u.size
+		if n == 1 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, u.name))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d %ss", n, u.name))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Formats t relative to now, e.g. in 2 hours or 5 minutes ago.
+func formatRelative(t, now time.Time) string {
+	d := t.Sub(now)
+	if d.Round(time.Second) == 0 {
+		return "now"
+	}
+	if d > 0 {
+		return "in " + formatDuration(d)
+	}
+	return formatDuration(d) + " ago"
+}
+
+// FormatTime formats t as text using the given format, which is one of
+// Formats.
+func FormatTime(t time.Time, format string) string {
+	switch format {
+	case Format12h:
+		return t.Format("Mon, 02 Jan 2006 03:04:05 PM MST")
+	case FormatISO:
+		return t.Format(time.RFC3339)
+	case FormatRelative:
+		return formatRelative(t, time.Now())
+	default:
+		return t.Format(time.RFC1123)
+	}
+}
+
+// FormatTimeDiscord is like FormatTime, except that it uses Discord's native
+// timestamps which are shown in each viewer's own timezone and locale.
+func FormatTimeDiscord(t time.Time, format string) string {
+	switch format {
+	case FormatISO:
+		return t.Format(time.RFC3339)
+	case FormatRelative:
+		return fmt.Sprintf("<t:%d:R>", t.Unix())
+	default:
+		return fmt.Sprintf("<t:%d:F>", t.Unix())
+	}
+}
+
+// Until returns the time that when refers to and how long there is until then.
+// The when string is parsed in the person's timezone.
+func Until(when string, person int64) (time.Time, time.Duration, core.Urr, error) {
+	t, urr, err := Time(when, person)
+	if urr != nil || err != nil {
+		return t, 0, urr, err
+	}
+	return t, time.Until(t), nil, nil
+}
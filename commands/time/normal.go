@@ -59,7 +59,7 @@ func (c normalTime) Init() error {
 }
 
 func (normalTime) addReminder(m *core.EventMessage) {
-	re := regexp.MustCompile(`^remind\s+me\s+to\s+` + `(?P<cmd>.+(in|on)\s+.+)`)
+	re := regexp.MustCompile(`^remind\s+me\s+to\s+` + `(?P<cmd>.+(in|on|every)\s+.+)`)
 
 	if !re.MatchString(m.Raw) {
 		return
@@ -0,0 +1,155 @@
+package time
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The shortest interval a recurring reminder may have, anything less would
+// just be spam.
+const recurMinInterval = 5 * time.Minute
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+var units = map[string]time.Duration{
+	"minute": time.Minute,
+	"min":    time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+}
+
+var (
+	rxRecurInterval  = regexp.MustCompile(`^(\d+)?\s*([a-z]+?)s?$`)
+	rxRecurTimeOfDay = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// A recurrence is either a fixed interval (e.g. every 2h) or a time of day,
+// optionally on a specific day of the week (e.g. every monday at 9am).
+type recurrence struct {
+	interval time.Duration
+
+	// used only if interval is 0
+	weekday *time.Weekday
+	hour    int
+	minute  int
+}
+
+func parseTimeOfDay(s string) (int, int, bool) {
+	match := rxRecurTimeOfDay.FindStringSubmatch(s)
+	if match == nil {
+		return 0, 0, false
+	}
+
+	hour, _ := strconv.Atoi(match[1])
+	minute := 0
+	if match[2] != "" {
+		minute, _ = strconv.Atoi(match[2])
+	}
+
+	switch match[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// Parses the part that comes after "every", for example "2h", "3 days",
+// "day at 9:30pm" or "monday at 9am".
+func parseRecurrence(s string) (recurrence, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	day, tod, hasTime := strings.Cut(s, " at ")
+	day = strings.TrimSpace(day)
+
+	if !hasTime {
+		if d, err := time.ParseDuration(s); err == nil {
+			return recurrence{interval: d}, d >= recurMinInterval
+		}
+
+		if wd, ok := weekdays[s]; ok {
+			return recurrence{weekday: &wd}, true
+		}
+
+		match := rxRecurInterval.FindStringSubmatch(s)
+		if match == nil {
+			return recurrence{}, false
+		}
+		unit, ok := units[match[2]]
+		if !ok {
+			return recurrence{}, false
+		}
+		n := 1
+		if match[1] != "" {
+			n, _ = strconv.Atoi(match[1])
+		}
+		d := time.Duration(n) * unit
+		return recurrence{interval: d}, d >= recurMinInterval
+	}
+
+	hour, minute, ok := parseTimeOfDay(strings.TrimSpace(tod))
+	if !ok {
+		return recurrence{}, false
+	}
+	r := recurrence{hour: hour, minute: minute}
+
+	if day == "day" {
+		return r, true
+	}
+	wd, ok := weekdays[day]
+	if !ok {
+		return recurrence{}, false
+	}
+	r.weekday = &wd
+	return r, true
+}
+
+// Returns the first occurrence that is strictly after the given time. Days
+// are computed in the given location, so a reminder set for 9am stays at 9am
+// across DST changes.
+func (r recurrence) next(after time.Time, loc *time.Location) time.Time {
+	if r.interval != 0 {
+		return after.Add(r.interval)
+	}
+
+	after = after.In(loc)
+	t := time.Date(after.Year(), after.Month(), after.Day(), r.hour, r.minute, 0, 0, loc)
+
+	for !t.After(after) || (r.weekday != nil && t.Weekday() != *r.weekday) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, r.hour, r.minute, 0, 0, loc)
+	}
+	return t
+}
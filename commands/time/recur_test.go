@@ -0,0 +1,90 @@
+package time
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	monday := time.Monday
+
+	tests := []struct {
+		s   string
+		rec recurrence
+		ok  bool
+	}{
+		{"2h", recurrence{interval: 2 * time.Hour}, true},
+		{"1h30m", recurrence{interval: 90 * time.Minute}, true},
+		{"3 days", recurrence{interval: 72 * time.Hour}, true},
+		{"hour", recurrence{interval: time.Hour}, true},
+		{"monday", recurrence{weekday: &monday}, true},
+		{"day at 9:30pm", recurrence{hour: 21, minute: 30}, true},
+		{"Monday at 9am", recurrence{weekday: &monday, hour: 9}, true},
+		{"mon at 12am", recurrence{weekday: &monday, hour: 0}, true},
+		{"day at 12pm", recurrence{hour: 12}, true},
+		{"day at 21:05", recurrence{hour: 21, minute: 5}, true},
+		{"1m", recurrence{}, false},
+		{"day at 25:00", recurrence{}, false},
+		{"day at 13pm", recurrence{}, false},
+		{"someday at 9am", recurrence{}, false},
+		{"fortnight", recurrence{}, false},
+	}
+
+	for _, test := range tests {
+		rec, ok := parseRecurrence(test.s)
+		if ok != test.ok {
+			t.Errorf("%q: expected ok = %v, got %v", test.s, test.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if rec.interval != test.rec.interval || rec.hour != test.rec.hour || rec.minute != test.rec.minute {
+			t.Errorf("%q: expected %+v, got %+v", test.s, test.rec, rec)
+		}
+		if (rec.weekday == nil) != (test.rec.weekday == nil) ||
+			(rec.weekday != nil && *rec.weekday != *test.rec.weekday) {
+			t.Errorf("%q: expected weekday %v, got %v", test.s, test.rec.weekday, rec.weekday)
+		}
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Athens")
+	if err != nil {
+		t.Skip("timezone data not available")
+	}
+
+	// Saturday
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, loc)
+	monday := time.Monday
+	saturday := time.Saturday
+
+	tests := []struct {
+		rec      recurrence
+		expected time.Time
+	}{
+		{recurrence{interval: 2 * time.Hour}, time.Date(2026, 10, 17, 12, 0, 0, 0, loc)},
+		{recurrence{hour: 11}, time.Date(2026, 10, 17, 11, 0, 0, 0, loc)},
+		{recurrence{hour: 9}, time.Date(2026, 10, 18, 9, 0, 0, 0, loc)},
+		{recurrence{hour: 10}, time.Date(2026, 10, 18, 10, 0, 0, 0, loc)},
+		{recurrence{weekday: &monday, hour: 9}, time.Date(2026, 10, 19, 9, 0, 0, 0, loc)},
+		{recurrence{weekday: &saturday, hour: 11}, time.Date(2026, 10, 17, 11, 0, 0, 0, loc)},
+		{recurrence{weekday: &saturday, hour: 9}, time.Date(2026, 10, 24, 9, 0, 0, 0, loc)},
+		{recurrence{weekday: &monday, hour: 9, minute: 30}, time.Date(2026, 10, 19, 9, 30, 0, 0, loc)},
+	}
+
+	for _, test := range tests {
+		next := test.rec.next(now, loc)
+		if !next.Equal(test.expected) {
+			t.Errorf("%+v: expected %v, got %v", test.rec, test.expected, next)
+		}
+	}
+
+	// DST ends on the 25th, the time of day must stay the same
+	rec := recurrence{hour: 9}
+	next := rec.next(time.Date(2026, 10, 25, 9, 0, 0, 0, loc), loc)
+	if expected := time.Date(2026, 10, 26, 9, 0, 0, 0, loc); !next.Equal(expected) || next.Hour() != 9 {
+		t.Errorf("expected %v, got %v", expected, next)
+	}
+}
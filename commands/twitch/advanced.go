@@ -0,0 +1,617 @@
+package twitch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+var Advanced = advanced{}
+
+type advanced struct{}
+
+func (advanced) Type() core.CommandType {
+	return core.Advanced
+}
+
+func (advanced) Permitted(m *core.EventMessage) bool {
+	if m.Frontend.Type() != twitch.Type {
+		return false
+	}
+	mod, err := m.Author.Moderator()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check if author is mod")
+		return false
+	}
+	return mod
+}
+
+func (advanced) Names() []string {
+	return []string{
+		"twitch",
+		"ttv",
+	}
+}
+
+func (advanced) Description() string {
+	return "Manage the channel's Twitch settings."
+}
+
+func (c advanced) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (advanced) Category() core.CommandCategory {
+	return core.CommandCategoryModerators
+}
+
+func (advanced) Examples() []string {
+	return nil
+}
+
+func (advanced) Parent() core.CommandStatic {
+	return nil
+}
+
+func (advanced) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedRedeem,
+	}
+}
+
+func (advanced) Init() error {
+	return nil
+}
+
+func (advanced) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+// FmtUrr returns the user facing message of the errors returned when
+// managing rewards.
+func FmtUrr(urr core.Urr) string {
+	switch urr {
+	case UrrNotTwitch:
+		return "Channel point rewards only exist on Twitch."
+	case UrrInvalidID:
+		return "Invalid reward ID, see the list of rewards for them."
+	case UrrInvalidCost:
+		return "The cost must be a positive number of channel points."
+	case UrrInvalidCooldown:
+		return "Expected a cooldown like 30s, 10m or 1h30m, or off."
+	case UrrInvalidToggle:
+		return "Expected on or off."
+	case UrrUnknownSetting:
+		return "Unknown setting, expected one of: " + strings.Join(Settings, ", ")
+	case UrrNoRedeems:
+		return "The channel has no channel point rewards."
+	case twitch.ErrNoResults:
+		return "Couldn't find a reward with that ID."
+	default:
+		return fmt.Sprint(urr)
+	}
+}
+
+////////////
+//        //
+// redeem //
+//        //
+////////////
+
+var AdvancedRedeem = advancedRedeem{}
+
+type advancedRedeem struct{}
+
+func (c advancedRedeem) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeem) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeem) Names() []string {
+	return []string{
+		"redeem",
+		"reward",
+	}
+}
+
+func (advancedRedeem) Description() string {
+	return "Manage the channel's channel point rewards."
+}
+
+func (c advancedRedeem) UsageArgs() string {
+	return c.Children().Usage()
+}
+
+func (c advancedRedeem) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeem) Examples() []string {
+	return nil
+}
+
+func (advancedRedeem) Parent() core.CommandStatic {
+	return Advanced
+}
+
+func (advancedRedeem) Children() core.CommandsStatic {
+	return core.CommandsStatic{
+		AdvancedRedeemCreate,
+		AdvancedRedeemEdit,
+		AdvancedRedeemPause,
+		AdvancedRedeemResume,
+		AdvancedRedeemDelete,
+		AdvancedRedeemList,
+	}
+}
+
+func (advancedRedeem) Init() error {
+	return nil
+}
+
+func (advancedRedeem) Run(m *core.EventMessage) (any, core.Urr, error) {
+	return m.Usage(), core.UrrMissingArgs, nil
+}
+
+///////////////////
+//               //
+// redeem create //
+//               //
+///////////////////
+
+var AdvancedRedeemCreate = advancedRedeemCreate{}
+
+type advancedRedeemCreate struct{}
+
+func (c advancedRedeemCreate) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemCreate) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemCreate) Names() []string {
+	return core.AliasesAdd
+}
+
+func (advancedRedeemCreate) Description() string {
+	return "Create a new channel point reward, it can then be changed with the edit command."
+}
+
+func (advancedRedeemCreate) UsageArgs() string {
+	return "<cost> <title...>"
+}
+
+func (c advancedRedeemCreate) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemCreate) Examples() []string {
+	return []string{
+		"500 Hydrate",
+		"10000 Pick the next game",
+	}
+}
+
+func (advancedRedeemCreate) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemCreate) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemCreate) Init() error {
+	return nil
+}
+
+func (c advancedRedeemCreate) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 2 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	id, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+	return "Created the reward, its ID is: " + id, nil, nil
+}
+
+func (advancedRedeemCreate) core(m *core.EventMessage) (string, core.Urr, error) {
+	return CreateFrom(m, RewardOptions{})
+}
+
+/////////////////
+//             //
+// redeem edit //
+//             //
+/////////////////
+
+var AdvancedRedeemEdit = advancedRedeemEdit{}
+
+type advancedRedeemEdit struct{}
+
+func (c advancedRedeemEdit) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemEdit) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemEdit) Names() []string {
+	return core.AliasesEdit
+}
+
+func (advancedRedeemEdit) Description() string {
+	return "Change one of a reward's settings."
+}
+
+func (advancedRedeemEdit) UsageArgs() string {
+	return "<id> (title <text...> | cost <points> | prompt <text...> | cooldown <duration|off> | input <on|off>)"
+}
+
+func (c advancedRedeemEdit) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemEdit) Examples() []string {
+	return []string{
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 cost 1000",
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 prompt Ask god anything",
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 cooldown 5m",
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11 input on",
+	}
+}
+
+func (advancedRedeemEdit) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemEdit) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemEdit) Init() error {
+	return nil
+}
+
+func (c advancedRedeemEdit) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 3 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+	return "Updated the reward.", nil, nil
+}
+
+func (advancedRedeemEdit) core(m *core.EventMessage) (core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	setting := strings.ToLower(m.Command.Args[1])
+	_, urr, err = Edit(broadcaster, m.Command.Args[0], setting, m.RawArgs(2))
+	return urr, err
+}
+
+//////////////////
+//              //
+// redeem pause //
+//              //
+//////////////////
+
+var AdvancedRedeemPause = advancedRedeemPause{}
+
+type advancedRedeemPause struct{}
+
+func (c advancedRedeemPause) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemPause) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemPause) Names() []string {
+	return []string{
+		"pause",
+	}
+}
+
+func (advancedRedeemPause) Description() string {
+	return "Stop viewers from redeeming a reward without hiding it."
+}
+
+func (advancedRedeemPause) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedRedeemPause) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemPause) Examples() []string {
+	return []string{
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11",
+	}
+}
+
+func (advancedRedeemPause) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemPause) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemPause) Init() error {
+	return nil
+}
+
+func (c advancedRedeemPause) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+	return "Paused the reward.", nil, nil
+}
+
+func (advancedRedeemPause) core(m *core.EventMessage) (core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Pause(broadcaster, m.Command.Args[0], true)
+}
+
+///////////////////
+//               //
+// redeem resume //
+//               //
+///////////////////
+
+var AdvancedRedeemResume = advancedRedeemResume{}
+
+type advancedRedeemResume struct{}
+
+func (c advancedRedeemResume) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemResume) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemResume) Names() []string {
+	return []string{
+		"resume",
+		"unpause",
+	}
+}
+
+func (advancedRedeemResume) Description() string {
+	return "Allow viewers to redeem a paused reward again."
+}
+
+func (advancedRedeemResume) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedRedeemResume) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemResume) Examples() []string {
+	return []string{
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11",
+	}
+}
+
+func (advancedRedeemResume) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemResume) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemResume) Init() error {
+	return nil
+}
+
+func (c advancedRedeemResume) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+	return "Resumed the reward.", nil, nil
+}
+
+func (advancedRedeemResume) core(m *core.EventMessage) (core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Pause(broadcaster, m.Command.Args[0], false)
+}
+
+///////////////////
+//               //
+// redeem delete //
+//               //
+///////////////////
+
+var AdvancedRedeemDelete = advancedRedeemDelete{}
+
+type advancedRedeemDelete struct{}
+
+func (c advancedRedeemDelete) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemDelete) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemDelete) Names() []string {
+	return core.AliasesDelete
+}
+
+func (advancedRedeemDelete) Description() string {
+	return "Delete a reward, only rewards created by the bot can be deleted."
+}
+
+func (advancedRedeemDelete) UsageArgs() string {
+	return "<id>"
+}
+
+func (c advancedRedeemDelete) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemDelete) Examples() []string {
+	return []string{
+		"2d5e8f8e-5d2b-4c42-a0f6-1f7c3b0e0a11",
+	}
+}
+
+func (advancedRedeemDelete) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemDelete) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemDelete) Init() error {
+	return nil
+}
+
+func (c advancedRedeemDelete) Run(m *core.EventMessage) (any, core.Urr, error) {
+	if len(m.Command.Args) < 1 {
+		return m.Usage(), core.UrrMissingArgs, nil
+	}
+	urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+	return "Deleted the reward.", nil, nil
+}
+
+func (advancedRedeemDelete) core(m *core.EventMessage) (core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+	return Delete(broadcaster, m.Command.Args[0])
+}
+
+/////////////////
+//             //
+// redeem list //
+//             //
+/////////////////
+
+var AdvancedRedeemList = advancedRedeemList{}
+
+type advancedRedeemList struct{}
+
+func (c advancedRedeemList) Type() core.CommandType {
+	return c.Parent().Type()
+}
+
+func (c advancedRedeemList) Permitted(m *core.EventMessage) bool {
+	return c.Parent().Permitted(m)
+}
+
+func (advancedRedeemList) Names() []string {
+	return core.AliasesList
+}
+
+func (advancedRedeemList) Description() string {
+	return "List the channel's rewards and their IDs."
+}
+
+func (advancedRedeemList) UsageArgs() string {
+	return ""
+}
+
+func (c advancedRedeemList) Category() core.CommandCategory {
+	return c.Parent().Category()
+}
+
+func (advancedRedeemList) Examples() []string {
+	return nil
+}
+
+func (advancedRedeemList) Parent() core.CommandStatic {
+	return AdvancedRedeem
+}
+
+func (advancedRedeemList) Children() core.CommandsStatic {
+	return nil
+}
+
+func (advancedRedeemList) Init() error {
+	return nil
+}
+
+func (c advancedRedeemList) Run(m *core.EventMessage) (any, core.Urr, error) {
+	rs, urr, err := c.core(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	if urr != nil {
+		return FmtUrr(urr), urr, nil
+	}
+
+	var fmted []string
+	for _, r := range rs {
+		s := fmt.Sprintf("%s (%d) = %s", r.Title, r.Cost, r.ID)
+		if r.IsPaused {
+			s += " [paused]"
+		}
+		fmted = append(fmted, s)
+	}
+	return strings.Join(fmted, " | "), nil, nil
+}
+
+func (advancedRedeemList) core(m *core.EventMessage) ([]helix.ChannelCustomReward, core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return nil, urr, err
+	}
+	return List(broadcaster)
+}
@@ -0,0 +1,224 @@
+package twitch
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+	"github.com/kvlach/janitorjeff/frontends/twitch"
+
+	"github.com/google/uuid"
+	"github.com/nicklaw5/helix/v2"
+)
+
+var (
+	UrrNotTwitch       = core.UrrNew("channel point rewards only exist on twitch")
+	UrrInvalidID       = core.UrrNew("invalid reward ID")
+	UrrInvalidCost     = core.UrrNew("the cost must be a positive number")
+	UrrInvalidCooldown = core.UrrNew("invalid cooldown")
+	UrrInvalidToggle   = core.UrrNew("expected on or off")
+	UrrUnknownSetting  = core.UrrNew("unknown setting")
+	UrrNoRedeems       = core.UrrNew("the channel has no rewards")
+)
+
+// The reward settings that can be edited.
+const (
+	SettingTitle    = "title"
+	SettingCost     = "cost"
+	SettingPrompt   = "prompt"
+	SettingCooldown = "cooldown"
+	SettingInput    = "input"
+)
+
+var Settings = []string{
+	SettingTitle,
+	SettingCost,
+	SettingPrompt,
+	SettingCooldown,
+	SettingInput,
+}
+
+// Broadcaster returns the ID of the channel the message was sent in, which is
+// who owns the channel's rewards. Returns UrrNotTwitch if the message wasn't
+// sent on Twitch.
+func Broadcaster(m *core.EventMessage) (string, core.Urr, error) {
+	if m.Frontend.Type() != twitch.Type {
+		return "", UrrNotTwitch, nil
+	}
+	id, err := m.Here.IDExact()
+	return id, nil, err
+}
+
+// ParseCost parses the amount of channel points a reward costs.
+func ParseCost(s string) (int, core.Urr) {
+	cost, err := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+	if err != nil || cost < 1 {
+		return 0, UrrInvalidCost
+	}
+	return cost, nil
+}
+
+// Returns the number of seconds in a cooldown duration, 0 disables the
+// cooldown.
+func parseCooldown(s string) (int, core.Urr) {
+	if slices.Contains(core.AliasesOff, s) || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < time.Second {
+		return 0, UrrInvalidCooldown
+	}
+	return int(d.Seconds()), nil
+}
+
+func parseToggle(s string) (bool, core.Urr) {
+	switch {
+	case slices.Contains(core.AliasesOn, s):
+		return true, nil
+	case slices.Contains(core.AliasesOff, s):
+		return false, nil
+	default:
+		return false, UrrInvalidToggle
+	}
+}
+
+func parseID(id string) (string, core.Urr) {
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return "", UrrInvalidID
+	}
+	return u.String(), nil
+}
+
+// RewardOptions holds the optional settings of a new reward, the zero value
+// creates a reward without any of them.
+type RewardOptions struct {
+	Prompt string
+	// Zero disables the cooldown, otherwise it must be at least a second.
+	Cooldown time.Duration
+	// Whether the viewer has to enter some text when redeeming the reward.
+	InputRequired bool
+}
+
+// Create creates a new enabled reward in the broadcaster's channel. The
+// broadcaster must have connected their account to the bot.
+func Create(broadcasterID, title string, cost int, opts RewardOptions) (helix.ChannelCustomReward, core.Urr, error) {
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return helix.ChannelCustomReward{}, nil, err
+	}
+	secs := int(opts.Cooldown.Seconds())
+	return hx.RedeemCreate(&helix.ChannelCustomRewardsParams{
+		BroadcasterID:           broadcasterID,
+		Title:                   title,
+		Cost:                    cost,
+		Prompt:                  opts.Prompt,
+		IsEnabled:               true,
+		IsUserInputRequired:     opts.InputRequired,
+		IsGlobalCooldownEnabled: secs > 0,
+		GlobalCooldownSeconds:   secs,
+	})
+}
+
+// CreateFrom creates a reward in the channel the message was sent in, the
+// message's arguments are expected to be the cost followed by the title.
+// Returns the new reward's ID.
+func CreateFrom(m *core.EventMessage, opts RewardOptions) (string, core.Urr, error) {
+	broadcaster, urr, err := Broadcaster(m)
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+	cost, urr := ParseCost(m.Command.Args[0])
+	if urr != nil {
+		return "", urr, nil
+	}
+	r, urr, err := Create(broadcaster, strings.TrimSpace(m.RawArgs(1)), cost, opts)
+	if urr != nil || err != nil {
+		return "", urr, err
+	}
+	return r.ID, nil, nil
+}
+
+// Edit changes one of the reward's settings, see Settings for which ones are
+// available. The cooldown is a duration, e.g. 5m, or off, and the input
+// setting controls whether the viewer has to enter some text, on or off.
+func Edit(broadcasterID, id, setting, value string) (helix.ChannelCustomReward, core.Urr, error) {
+	id, urr := parseID(id)
+	if urr != nil {
+		return helix.ChannelCustomReward{}, urr, nil
+	}
+
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return helix.ChannelCustomReward{}, nil, err
+	}
+	r, urr, err := hx.RedeemGet(broadcasterID, id)
+	if urr != nil || err != nil {
+		return helix.ChannelCustomReward{}, urr, err
+	}
+
+	switch setting {
+	case SettingTitle:
+		r.Title = value
+	case SettingCost:
+		r.Cost, urr = ParseCost(value)
+	case SettingPrompt:
+		r.Prompt = value
+	case SettingCooldown:
+		var secs int
+		secs, urr = parseCooldown(value)
+		r.GlobalCooldownSetting.IsEnabled = secs > 0
+		r.GlobalCooldownSetting.GlobalCooldownSeconds = secs
+	case SettingInput:
+		r.IsUserInputRequired, urr = parseToggle(value)
+	default:
+		urr = UrrUnknownSetting
+	}
+	if urr != nil {
+		return helix.ChannelCustomReward{}, urr, nil
+	}
+
+	return hx.RedeemUpdate(r)
+}
+
+// Pause pauses or resumes the reward. Paused rewards are still visible to
+// viewers, but they can't redeem them.
+func Pause(broadcasterID, id string, paused bool) (core.Urr, error) {
+	id, urr := parseID(id)
+	if urr != nil {
+		return urr, nil
+	}
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	return hx.RedeemPause(broadcasterID, id, paused)
+}
+
+// Delete deletes the reward from the broadcaster's channel.
+func Delete(broadcasterID, id string) (core.Urr, error) {
+	id, urr := parseID(id)
+	if urr != nil {
+		return urr, nil
+	}
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	return hx.RedeemDelete(broadcasterID, id)
+}
+
+// List returns all of the broadcaster's rewards.
+func List(broadcasterID string) ([]helix.ChannelCustomReward, core.Urr, error) {
+	hx, err := twitch.NewHelix(broadcasterID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs, err := hx.RedeemsList(broadcasterID)
+	if err == twitch.ErrNoResults {
+		return nil, UrrNoRedeems, nil
+	}
+	return rs, nil, err
+}
@@ -509,8 +509,14 @@ func (tx *Tx) PersonEnsure(person, place int64) error {
 }
 
 // PersonGet returns the value of col in the table for the specified person
-// in the specified place.
+// in the specified place. If the person is linked to other accounts, then the
+// value of the canonical person is returned.
 func (tx *Tx) PersonGet(col string, person, place int64) Val {
+	person, err := tx.PersonCanonical(person)
+	if err != nil {
+		return Val{}
+	}
+
 	// Make sure that the person info is present
 	if err := tx.PersonEnsure(person, place); err != nil {
 		return Val{}
@@ -520,7 +526,7 @@ func (tx *Tx) PersonGet(col string, person, place int64) Val {
 	row := tx.Tx.QueryRow(query, person, place)
 
 	var val any
-	err := row.Scan(&val)
+	err = row.Scan(&val)
 	log.Debug().
 		Err(err).
 		Int64("person", person).
@@ -531,8 +537,14 @@ func (tx *Tx) PersonGet(col string, person, place int64) Val {
 }
 
 // PersonSet sets the value of col in the table for the specified person in
-// the specified place.
+// the specified place. If the person is linked to other accounts, then the
+// value of the canonical person is set.
 func (tx *Tx) PersonSet(col string, person, place int64, val any) error {
+	person, err := tx.PersonCanonical(person)
+	if err != nil {
+		return err
+	}
+
 	// Make sure that the person info is present
 	if err := tx.PersonEnsure(person, place); err != nil {
 		return err
@@ -543,7 +555,7 @@ func (tx *Tx) PersonSet(col string, person, place int64, val any) error {
 		SET %s = $1
 		WHERE person = $2 and place = $3
 	`, col)
-	_, err := tx.Tx.Exec(query, val, person, place)
+	_, err = tx.Tx.Exec(query, val, person, place)
 
 	log.Debug().
 		Err(err).
@@ -582,3 +594,504 @@ func (db *SQLDB) PersonSet(col string, person, place int64, val any) error {
 	}
 	return tx.Commit()
 }
+
+////////////////////////
+//                    //
+// global person info //
+//                    //
+////////////////////////
+
+// Generates the person's global info if it doesn't already exist.
+func (tx *Tx) personGlobalEnsure(person int64) error {
+	_, err := tx.Tx.Exec(`
+		INSERT INTO info_person_global (person)
+		VALUES ($1)
+		ON CONFLICT (person) DO NOTHING
+	`, person)
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Msg("POSTGRES: ensured global person info")
+
+	return err
+}
+
+// PersonGlobalGet returns the value of col in the global info of the
+// specified person, which is the same in every place. If the person is linked
+// to other accounts, then the value of the canonical person is returned.
+func (tx *Tx) PersonGlobalGet(col string, person int64) Val {
+	person, err := tx.PersonCanonical(person)
+	if err != nil {
+		return Val{}
+	}
+
+	if err := tx.personGlobalEnsure(person); err != nil {
+		return Val{}
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM info_person_global WHERE person = $1`, col)
+	row := tx.Tx.QueryRow(query, person)
+
+	var val any
+	err = row.Scan(&val)
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Interface(col, val).
+		Msg("POSTGRES: got global value")
+	return Val{val, err}
+}
+
+// PersonGlobalSet sets the value of col in the global info of the specified
+// person. If the person is linked to other accounts, then the value of the
+// canonical person is set.
+func (tx *Tx) PersonGlobalSet(col string, person int64, val any) error {
+	person, err := tx.PersonCanonical(person)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.personGlobalEnsure(person); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE info_person_global
+		SET %s = $1
+		WHERE person = $2
+	`, col)
+	_, err = tx.Tx.Exec(query, val, person)
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Interface(col, val).
+		Msg("POSTGRES: updated global info")
+
+	return err
+}
+
+func (db *SQLDB) PersonGlobalGet(col string, person int64) Val {
+	tx, err := db.Begin()
+	if err != nil {
+		return Val{}
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+	val := tx.PersonGlobalGet(col, person)
+	if val.err != nil {
+		return Val{}
+	}
+	return Val{val.val, tx.Commit()}
+}
+
+func (db *SQLDB) PersonGlobalSet(col string, person int64, val any) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+	err = tx.PersonGlobalSet(col, person, val)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+//////////////////
+//              //
+// person links //
+//              //
+//////////////////
+
+// How long the canonical person is cached for. Scopes getting deleted removes
+// their links through the foreign keys without clearing the cache, so it must
+// not be kept forever.
+const personLinkExpiry = 10 * time.Minute
+
+func personLinkKey(person int64) string {
+	return fmt.Sprintf("person_link_%d", person)
+}
+
+// Clears everything that is cached about the persons, must be called whenever
+// their links change or their info is moved to a different person.
+func personCacheClear(persons ...int64) error {
+	for _, person := range persons {
+		if err := RDB.Del(ctx, personLinkKey(person)).Err(); err != nil {
+			return err
+		}
+
+		pattern := fmt.Sprintf("info_person_%d_*", person)
+		iter := RDB.Scan(ctx, 0, pattern, 0).Iterator()
+		for iter.Next(ctx) {
+			if err := RDB.Del(ctx, iter.Val()).Err(); err != nil {
+				return err
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+
+		log.Debug().Int64("person", person).Msg("REDIS: cleared person cache")
+	}
+	return nil
+}
+
+// PersonCanonical returns the person whose info is used for the specified
+// person. If the person isn't linked to any other accounts, then that is the
+// person itself.
+func (tx *Tx) PersonCanonical(person int64) (int64, error) {
+	rdbKey := personLinkKey(person)
+
+	if canonical, err := RDB.Get(ctx, rdbKey).Int64(); err == nil {
+		return canonical, nil
+	}
+
+	var canonical int64
+	err := tx.Tx.QueryRow(`
+		SELECT canonical
+		FROM person_links
+		WHERE person = $1
+	`, person).Scan(&canonical)
+	if errors.Is(err, sql.ErrNoRows) {
+		canonical, err = person, nil
+	}
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Int64("canonical", canonical).
+		Msg("POSTGRES: got canonical person")
+
+	if err != nil {
+		return -1, err
+	}
+
+	err = RDB.Set(ctx, rdbKey, canonical, personLinkExpiry).Err()
+	log.Debug().
+		Err(err).
+		Msg("REDIS: caching canonical person")
+	return canonical, err
+}
+
+// Returns all the accounts that are linked with the canonical person,
+// including the canonical person itself which is always first.
+func (tx *Tx) personLinked(canonical int64) ([]int64, error) {
+	rows, err := tx.Tx.Query(`
+		SELECT person
+		FROM person_links
+		WHERE canonical = $1
+		ORDER BY person
+	`, canonical)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	persons := []int64{canonical}
+	for rows.Next() {
+		var person int64
+		if err := rows.Scan(&person); err != nil {
+			return nil, err
+		}
+		persons = append(persons, person)
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("canonical", canonical).
+		Ints64("persons", persons).
+		Msg("POSTGRES: got linked persons")
+
+	return persons, err
+}
+
+// Makes person the canonical person in place of the current one, all the info
+// gets moved over.
+func (tx *Tx) personPromote(person, current int64) error {
+	_, err := tx.Tx.Exec(`
+		DELETE FROM info_person
+		WHERE person = $1
+	`, person)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE info_person
+		SET person = $1
+		WHERE person = $2
+	`, person, current)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM info_person_global
+		WHERE person = $1
+	`, person)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE info_person_global
+		SET person = $1
+		WHERE person = $2
+	`, person, current)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE person_links
+		SET canonical = $1
+		WHERE canonical = $2
+	`, person, current)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM person_links
+		WHERE person = $1
+	`, person)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		INSERT INTO person_links (person, canonical)
+		VALUES ($1, $2)
+	`, current, person)
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Int64("previous", current).
+		Msg("POSTGRES: promoted person to canonical")
+
+	return err
+}
+
+// PersonLinked returns all the accounts that share their info with the
+// person, including the person. The canonical person is always first.
+func (db *SQLDB) PersonLinked(person int64) ([]int64, error) {
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	canonical, err := tx.PersonCanonical(person)
+	if err != nil {
+		return nil, err
+	}
+	persons, err := tx.personLinked(canonical)
+	if err != nil {
+		return nil, err
+	}
+	return persons, tx.Commit()
+}
+
+// PersonLink links the accounts of a and b (along with anything they are
+// already linked with) so that they share the same info. If keepA is true,
+// then a's canonical person becomes the canonical person of all the accounts
+// and a's info wins wherever both have info (including the global info),
+// otherwise b's does.
+func (db *SQLDB) PersonLink(a, b int64, keepA bool) error {
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	winner, err := tx.PersonCanonical(a)
+	if err != nil {
+		return err
+	}
+	loser, err := tx.PersonCanonical(b)
+	if err != nil {
+		return err
+	}
+	if winner == loser {
+		return nil
+	}
+	if !keepA {
+		winner, loser = loser, winner
+	}
+
+	affected, err := tx.personLinked(loser)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM info_person
+		WHERE person = $1 AND place IN (
+			SELECT place
+			FROM info_person
+			WHERE person = $2
+		)
+	`, loser, winner)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE info_person
+		SET person = $1
+		WHERE person = $2
+	`, winner, loser)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM info_person_global
+		WHERE person = $1 AND EXISTS (
+			SELECT 1
+			FROM info_person_global
+			WHERE person = $2
+		)
+	`, loser, winner)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE info_person_global
+		SET person = $1
+		WHERE person = $2
+	`, winner, loser)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		UPDATE person_links
+		SET canonical = $1
+		WHERE canonical = $2
+	`, winner, loser)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Tx.Exec(`
+		INSERT INTO person_links (person, canonical)
+		VALUES ($1, $2)
+	`, loser, winner)
+
+	log.Debug().
+		Err(err).
+		Int64("winner", winner).
+		Int64("loser", loser).
+		Msg("POSTGRES: linked persons")
+
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return personCacheClear(affected...)
+}
+
+// PersonUnlink removes the person from the accounts it's linked with. The
+// info stays with the rest of the accounts and the person starts over.
+// Returns false if the person wasn't linked with anything.
+func (db *SQLDB) PersonUnlink(person int64) (bool, error) {
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	canonical, err := tx.PersonCanonical(person)
+	if err != nil {
+		return false, err
+	}
+	affected, err := tx.personLinked(canonical)
+	if err != nil {
+		return false, err
+	}
+	if len(affected) == 1 {
+		return false, nil
+	}
+
+	// the info must stay with the rest, so someone else has to take over
+	if person == canonical {
+		if err := tx.personPromote(affected[1], canonical); err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.Tx.Exec(`
+		DELETE FROM person_links
+		WHERE person = $1
+	`, person)
+
+	log.Debug().
+		Err(err).
+		Int64("person", person).
+		Msg("POSTGRES: unlinked person")
+
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, personCacheClear(affected...)
+}
+
+// PersonPromote makes the person the canonical person of the accounts it's
+// linked with, meaning that the person's scope is the one that actually holds
+// the info.
+func (db *SQLDB) PersonPromote(person int64) error {
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	//goland:noinspection GoUnhandledErrorResult
+	defer tx.Rollback()
+
+	canonical, err := tx.PersonCanonical(person)
+	if err != nil {
+		return err
+	}
+	if canonical == person {
+		return nil
+	}
+	affected, err := tx.personLinked(canonical)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.personPromote(person, canonical); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return personCacheClear(affected...)
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	EventCheerHooks   = NewHooks[*EventCheer](5)
+	eventCheerChan    = make(chan *EventCheer)
+	eventCheerCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jeff_event_cheer_total",
+		Help: "Total number of received cheer events.",
+	}, []string{"frontend", "place"})
+)
+
+// EventCheer is sent when someone cheers bits in a place.
+type EventCheer struct {
+	Bits    int
+	Message string
+	// Nil if the cheer was anonymous.
+	Author   Personifier
+	When     time.Time
+	Here     Placer
+	Frontend Frontender
+}
+
+func NewEventCheer(bits int, message string,
+	author Personifier, when time.Time, here Placer, f Frontender) *EventCheer {
+
+	return &EventCheer{
+		Bits:     bits,
+		Message:  message,
+		Author:   author,
+		When:     when,
+		Here:     here,
+		Frontend: f,
+	}
+}
+
+func (c *EventCheer) Hooks() *Hooks[*EventCheer] {
+	return EventCheerHooks
+}
+
+func (c *EventCheer) Handler() {
+	place, err := c.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err)
+		return
+	}
+
+	eventCheerCounter.With(prometheus.Labels{
+		"frontend": c.Frontend.Name(),
+		"place":    strconv.FormatInt(place, 10),
+	}).Inc()
+
+	log.Debug().
+		Int("bits", c.Bits).
+		Str("when", c.When.String()).
+		Msg("received cheer event")
+
+	EventCheerHooks.Run(c)
+}
+
+func (c *EventCheer) Send() {
+	eventCheerChan <- c
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	EventFollowHooks   = NewHooks[*EventFollow](5)
+	eventFollowChan    = make(chan *EventFollow)
+	eventFollowCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jeff_event_follow_total",
+		Help: "Total number of received follow events.",
+	}, []string{"frontend", "place"})
+)
+
+// EventFollow is sent when someone follows a place.
+type EventFollow struct {
+	Author   Personifier
+	When     time.Time
+	Here     Placer
+	Frontend Frontender
+}
+
+func NewEventFollow(author Personifier, when time.Time, here Placer, f Frontender) *EventFollow {
+	return &EventFollow{
+		Author:   author,
+		When:     when,
+		Here:     here,
+		Frontend: f,
+	}
+}
+
+func (f *EventFollow) Hooks() *Hooks[*EventFollow] {
+	return EventFollowHooks
+}
+
+func (f *EventFollow) Handler() {
+	place, err := f.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err)
+		return
+	}
+
+	eventFollowCounter.With(prometheus.Labels{
+		"frontend": f.Frontend.Name(),
+		"place":    strconv.FormatInt(place, 10),
+	}).Inc()
+
+	log.Debug().
+		Str("when", f.When.String()).
+		Msg("received follow event")
+
+	EventFollowHooks.Run(f)
+}
+
+func (f *EventFollow) Send() {
+	eventFollowChan <- f
+}
@@ -171,6 +171,21 @@ type Messenger interface {
 	QuoteCommand(cmd string) string
 }
 
+// Limiter is implemented by messengers whose frontend limits the length of a
+// message's text.
+type Limiter interface {
+	// Limit returns the function used to count a message's length along with
+	// the maximum length, which must be able to fit any markers added when
+	// sending (e.g. mentions).
+	Limit() (lenCnt func(string) int, lenLim int)
+}
+
+// Editor is implemented by messengers that can edit the messages they've sent.
+type Editor interface {
+	// Edit replaces the contents of the message with the given ID.
+	Edit(id string, msg any) (resp *EventMessage, err error)
+}
+
 type EventMessage struct {
 	ID       string
 	Raw      string
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	EventRaidHooks   = NewHooks[*EventRaid](5)
+	eventRaidChan    = make(chan *EventRaid)
+	eventRaidCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jeff_event_raid_total",
+		Help: "Total number of received raid events.",
+	}, []string{"frontend", "place"})
+)
+
+// EventRaid is sent when another channel raids a place.
+type EventRaid struct {
+	// The number of viewers the raid brought along.
+	Viewers int
+	// The broadcaster who started the raid.
+	Author   Personifier
+	When     time.Time
+	Here     Placer
+	Frontend Frontender
+}
+
+func NewEventRaid(viewers int, author Personifier, when time.Time, here Placer, f Frontender) *EventRaid {
+	return &EventRaid{
+		Viewers:  viewers,
+		Author:   author,
+		When:     when,
+		Here:     here,
+		Frontend: f,
+	}
+}
+
+func (r *EventRaid) Hooks() *Hooks[*EventRaid] {
+	return EventRaidHooks
+}
+
+func (r *EventRaid) Handler() {
+	place, err := r.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err)
+		return
+	}
+
+	eventRaidCounter.With(prometheus.Labels{
+		"frontend": r.Frontend.Name(),
+		"place":    strconv.FormatInt(place, 10),
+	}).Inc()
+
+	log.Debug().
+		Int("viewers", r.Viewers).
+		Str("when", r.When.String()).
+		Msg("received raid event")
+
+	EventRaidHooks.Run(r)
+}
+
+func (r *EventRaid) Send() {
+	eventRaidChan <- r
+}
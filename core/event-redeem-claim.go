@@ -1,7 +1,9 @@
 package core
 
 import (
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,25 +20,59 @@ var (
 	}, []string{"frontend", "place"})
 )
 
+// The policies that control what happens to a redemption once a handler has
+// reported how it went.
+const (
+	// Fulfil successful redemptions and refund failed ones.
+	RedeemPolicyAuto = "auto"
+	// Only refund failed redemptions, successful ones are left in the reward
+	// queue for the broadcaster to review.
+	RedeemPolicyRefund = "refund"
+	// Leave every redemption in the reward queue.
+	RedeemPolicyOff = "off"
+)
+
+var RedeemPolicies = []string{
+	RedeemPolicyAuto,
+	RedeemPolicyRefund,
+	RedeemPolicyOff,
+}
+
+var UrrUnknownRedeemPolicy = UrrNew("unknown redeem policy")
+
+// RedeemResolver is implemented by frontends whose redemptions can be marked
+// as fulfilled or canceled after they have been handled.
+type RedeemResolver interface {
+	// RedeemResolve marks the redemption as fulfilled, or as canceled in
+	// which case the person gets refunded.
+	RedeemResolve(rc *EventRedeemClaim, fulfilled bool) error
+}
+
 type EventRedeemClaim struct {
-	ID       string
-	Input    string
-	When     time.Time
-	Author   Personifier
-	Here     Placer
-	Frontend Frontender
+	// The ID of the reward that was redeemed.
+	ID string
+	// The ID of this specific redemption of the reward.
+	RedemptionID string
+	Input        string
+	When         time.Time
+	Author       Personifier
+	Here         Placer
+	Frontend     Frontender
+
+	resolved sync.Once
 }
 
-func NewEventRedeemClaim(id, input string, when time.Time,
+func NewEventRedeemClaim(id, redemptionID, input string, when time.Time,
 	author Personifier, here Placer, f Frontender) *EventRedeemClaim {
 
 	return &EventRedeemClaim{
-		ID:       id,
-		Input:    input,
-		When:     when,
-		Author:   author,
-		Here:     here,
-		Frontend: f,
+		ID:           id,
+		RedemptionID: redemptionID,
+		Input:        input,
+		When:         when,
+		Author:       author,
+		Here:         here,
+		Frontend:     f,
 	}
 }
 
@@ -68,3 +104,82 @@ func (rc *EventRedeemClaim) Handler() {
 func (rc *EventRedeemClaim) Send() {
 	eventRedeemClaimChan <- rc
 }
+
+// Fulfil reports that the redemption was handled successfully. Depending on
+// the place's redeem policy the redemption is then marked as fulfilled.
+// Only the first report for a redemption has any effect.
+func (rc *EventRedeemClaim) Fulfil() {
+	rc.resolve(true)
+}
+
+// Refund reports that the redemption couldn't be handled. Depending on the
+// place's redeem policy the redemption is then canceled, which gives the
+// person back the points they spent. Only the first report for a redemption
+// has any effect.
+func (rc *EventRedeemClaim) Refund() {
+	rc.resolve(false)
+}
+
+func (rc *EventRedeemClaim) resolve(fulfilled bool) {
+	rc.resolved.Do(func() {
+		r, ok := rc.Frontend.(RedeemResolver)
+		if !ok {
+			return
+		}
+
+		place, err := rc.Here.ScopeLogical()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get place scope")
+			return
+		}
+		policy, err := RedeemPolicyGet(place)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get redeem policy")
+			return
+		}
+
+		switch {
+		case policy == RedeemPolicyOff:
+			return
+		case policy == RedeemPolicyRefund && fulfilled:
+			return
+		}
+
+		// Redemptions of rewards that skip the request queue are fulfilled
+		// immediately and can't be changed, neither can the ones of rewards
+		// that weren't created by the bot, in which case this fails.
+		err = r.RedeemResolve(rc, fulfilled)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("reward", rc.ID).
+				Str("redemption", rc.RedemptionID).
+				Str("policy", policy).
+				Bool("fulfilled", fulfilled).
+				Msg("failed to resolve redemption")
+			return
+		}
+
+		log.Debug().
+			Str("reward", rc.ID).
+			Str("redemption", rc.RedemptionID).
+			Str("policy", policy).
+			Bool("fulfilled", fulfilled).
+			Msg("resolved redemption")
+	})
+}
+
+// RedeemPolicyGet returns the place's redeem policy.
+func RedeemPolicyGet(place int64) (string, error) {
+	return DB.PlaceGet("redeem_policy", place).Str()
+}
+
+// RedeemPolicySet updates the place's redeem policy, see RedeemPolicies for
+// the available ones. Returns UrrUnknownRedeemPolicy if the policy doesn't
+// exist.
+func RedeemPolicySet(place int64, policy string) (Urr, error) {
+	if !slices.Contains(RedeemPolicies, policy) {
+		return UrrUnknownRedeemPolicy, nil
+	}
+	return nil, DB.PlaceSet("redeem_policy", place, policy)
+}
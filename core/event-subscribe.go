@@ -0,0 +1,82 @@
+package core
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	EventSubscribeHooks   = NewHooks[*EventSubscribe](5)
+	eventSubscribeChan    = make(chan *EventSubscribe)
+	eventSubscribeCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "jeff_event_subscribe_total",
+		Help: "Total number of received subscribe events.",
+	}, []string{"frontend", "place"})
+)
+
+// EventSubscribe is sent when someone subscribes to a place, or when they
+// share that they resubscribed.
+type EventSubscribe struct {
+	// The subscription's tier, e.g. 1000 for tier 1.
+	Tier string
+	// The total number of months the person has been subscribed for, 1 if
+	// it's a new subscription.
+	Months int
+	// True if the subscription was gifted to the person.
+	Gift bool
+	// The message the person shared when resubscribing.
+	Message  string
+	Author   Personifier
+	When     time.Time
+	Here     Placer
+	Frontend Frontender
+}
+
+func NewEventSubscribe(tier string, months int, gift bool, message string,
+	author Personifier, when time.Time, here Placer, f Frontender) *EventSubscribe {
+
+	return &EventSubscribe{
+		Tier:     tier,
+		Months:   months,
+		Gift:     gift,
+		Message:  message,
+		Author:   author,
+		When:     when,
+		Here:     here,
+		Frontend: f,
+	}
+}
+
+func (s *EventSubscribe) Hooks() *Hooks[*EventSubscribe] {
+	return EventSubscribeHooks
+}
+
+func (s *EventSubscribe) Handler() {
+	place, err := s.Here.ScopeLogical()
+	if err != nil {
+		log.Error().Err(err)
+		return
+	}
+
+	eventSubscribeCounter.With(prometheus.Labels{
+		"frontend": s.Frontend.Name(),
+		"place":    strconv.FormatInt(place, 10),
+	}).Inc()
+
+	log.Debug().
+		Str("tier", s.Tier).
+		Int("months", s.Months).
+		Bool("gift", s.Gift).
+		Str("when", s.When.String()).
+		Msg("received subscribe event")
+
+	EventSubscribeHooks.Run(s)
+}
+
+func (s *EventSubscribe) Send() {
+	eventSubscribeChan <- s
+}
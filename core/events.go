@@ -94,7 +94,8 @@ func (hs *Hooks[T]) Run(arg T) {
 // Event is the interface used for handling all incoming events,
 // such as messages, redeems, or stream status changes.
 type Event[T any] interface {
-	*EventMessage | *EventRedeemClaim | *EventStreamOnline | *EventStreamOffline
+	*EventMessage | *EventRedeemClaim | *EventStreamOnline | *EventStreamOffline |
+		*EventFollow | *EventSubscribe | *EventRaid | *EventCheer
 
 	// Handler is the method that [EventLoop] calls when it receives the event.
 	Handler()
@@ -122,6 +123,14 @@ func EventLoop() {
 			son.Handler()
 		case soff := <-eventStreamOfflineChan:
 			soff.Handler()
+		case f := <-eventFollowChan:
+			f.Handler()
+		case s := <-eventSubscribeChan:
+			s.Handler()
+		case r := <-eventRaidChan:
+			r.Handler()
+		case c := <-eventCheerChan:
+			c.Handler()
 		}
 	}
 }
@@ -28,6 +28,11 @@ type Frontender interface {
 	// Used to send messages that are not direct replies, e.g. reminders.
 	CreateMessage(person, place int64, msgID string) (*EventMessage, error)
 
+	// Announce sends a message to the place that isn't addressed to anyone.
+	// If role is not empty, then that role is mentioned, frontends that don't
+	// have roles ignore it.
+	Announce(place int64, text, role string) error
+
 	// Usage returns the passed usage formatted appropriately for the frontend.
 	Usage(usage string) any
 
@@ -58,6 +63,24 @@ func (fs Frontenders) CreateMessage(person, place int64, msgID string) (*EventMe
 	return nil, fmt.Errorf("frontend type %d couldn't be matched", frontendType)
 }
 
+// Announce sends a message that isn't addressed to anyone to the place. It
+// detects what the frontend is based on the place. Used for scheduled
+// announcements.
+func (fs Frontenders) Announce(place int64, text, role string) error {
+	frontendType, err := DB.ScopeFrontend(place)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fs {
+		if f.Type() == FrontendType(frontendType) {
+			return f.Announce(place, text, role)
+		}
+	}
+
+	return fmt.Errorf("frontend type %d couldn't be matched", frontendType)
+}
+
 // Match returns the Frontender corresponding to lowercase fname.
 // Returns UrrUnknownFrontend if nothing is matched.
 func (fs Frontenders) Match(fname string) (Frontender, Urr) {
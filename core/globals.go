@@ -13,6 +13,10 @@ var (
 	YouTubeKey      string
 	OpenAIKey       string
 	MinGodInterval  time.Duration
+	// The defaults used by places that haven't configured god's backend.
+	GodBaseURL string
+	GodModel   string
+	GodTimeout time.Duration
 
 	Gin = gin.Default()
 )
@@ -3,11 +3,13 @@ package core
 import (
 	crand "crypto/rand"
 	"encoding/base64"
+	"math/big"
 	mrand "math/rand"
 	"net/url"
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/kvlach/gosafe"
 	"github.com/rivo/uniseg"
@@ -71,6 +73,24 @@ func (s *States) New() (string, error) {
 	return state, nil
 }
 
+// Ambiguous characters like 0 and O are left out, since codes are meant to be
+// typed by hand.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GenerateCode returns a random code of the given length that is easy for
+// people to type, for example to verify something from a different account.
+func GenerateCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := crand.Int(crand.Reader, big.NewInt(int64(len(codeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
 func OnlyOneBitSet(n int) bool {
 	// https://stackoverflow.com/a/28303898
 	return n&(n-1) == 0
@@ -121,6 +141,66 @@ func Split(text string, lenCnt func(string) int, lenLim int) []string {
 	return parts
 }
 
+// A sentence ends with one or more terminating punctuation marks, optionally
+// followed by closing quotes or brackets, and then whitespace.
+var sentenceEnd = regexp.MustCompile(`[.!?…]+["'”’)\]]*\s+`)
+
+// Sentences splits text into the sentences that have been completed, each one
+// including its trailing whitespace, and the rest of the text that doesn't end
+// a sentence yet. Useful when the text is still being generated.
+func Sentences(text string) ([]string, string) {
+	var sentences []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		sentences = append(sentences, text[start:loc[1]])
+		start = loc[1]
+	}
+	return sentences, text[start:]
+}
+
+// SplitSentences works like Split except that it tries to fit whole sentences
+// in each sub-message. Sentences that don't fit in a single sub-message are
+// split using Split.
+func SplitSentences(text string, lenCnt func(string) int, lenLim int) []string {
+	parts := []string{""}
+
+	sentences, rest := Sentences(text)
+	if rest != "" {
+		sentences = append(sentences, rest)
+	}
+
+	for _, s := range sentences {
+		last := parts[len(parts)-1]
+		sLen := lenCnt(strings.TrimSpace(s))
+		partLen := lenCnt(last)
+
+		if lenLim > partLen+sLen {
+			parts[len(parts)-1] += s
+			continue
+		}
+
+		// no point in keeping a sub-message that's just whitespace
+		if strings.TrimSpace(last) == "" {
+			parts = parts[:len(parts)-1]
+		}
+		if lenLim > sLen {
+			parts = append(parts, s)
+		} else {
+			// Split trims the whitespace that separates the sentence from
+			// the next one, so it has to be added back
+			split := Split(s, lenCnt, lenLim)
+			split[len(split)-1] += s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+			parts = append(parts, split...)
+		}
+	}
+
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+
+	return parts
+}
+
 // IsValidURL returns true if the provided string is a valid URL with a http
 // or https scheme and a host.
 func IsValidURL(rawURL string) bool {
@@ -66,6 +66,59 @@ func TestSplit(t *testing.T) {
 	}
 }
 
+func TestSentences(t *testing.T) {
+	tests := []struct {
+		text      string
+		sentences []string
+		rest      string
+	}{
+		{"Hello", nil, "Hello"},
+		{"Hello there. How are", []string{"Hello there. "}, "How are"},
+		{"Wait... What?! Okay.", []string{"Wait... ", "What?! "}, "Okay."},
+		{`He said "hi." Then left. `, []string{`He said "hi." `, "Then left. "}, ""},
+		{"Version 1.5 is out", nil, "Version 1.5 is out"},
+	}
+
+	for _, test := range tests {
+		sentences, rest := core.Sentences(test.text)
+		if !eqSlices(sentences, test.sentences) || rest != test.rest {
+			t.Fatalf("failed to split sentences, got %#v and %#v, expected %#v and %#v", sentences, rest, test.sentences, test.rest)
+		}
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	lenCnt := func(s string) int { return len(s) }
+
+	tests := []struct {
+		text   string
+		lenLim int
+		res    []string
+	}{
+		{"Short one.", 50, []string{
+			"Short one.",
+		}},
+		{"First sentence here. Second sentence here. Third.", 30, []string{
+			"First sentence here.",
+			"Second sentence here. Third.",
+		}},
+		// The second sentence is too long to fit, so it falls back to Split, the
+		// sentence after it is then packed along with its last part.
+		{"Tiny. This sentence is far too long to fit. End.", 20, []string{
+			"Tiny.",
+			"This sentence is",
+			"far too long to",
+			"fit. End.",
+		}},
+	}
+
+	for _, test := range tests {
+		if split := core.SplitSentences(test.text, lenCnt, test.lenLim); !eqSlices(split, test.res) {
+			t.Fatalf("failed to split sentences, got %#v, expected %#v", split, test.res)
+		}
+	}
+}
+
 func TestIsValidURL(t *testing.T) {
 	tests := []struct {
 		url   string
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Places on different frontends that belong to the same community can be put
+// in a group so that they share some of their data. What is shared is decided
+// per feature. While a feature is shared, every place in the group uses the
+// data of the group's authority, which is the place that created the group.
+// The members' own data is left untouched and is used again as soon as they
+// leave the group or the feature stops being shared.
+const (
+	ShareCustomCommands = "customcommands"
+	SharePersonalities  = "personalities"
+	ShareReminders      = "reminders"
+)
+
+// ShareFeatures holds all the features that a place group can share.
+var ShareFeatures = []string{
+	ShareCustomCommands,
+	SharePersonalities,
+	ShareReminders,
+}
+
+// PlaceShared returns the place whose data should be used for the given
+// feature. If the place is in a group that shares the feature, then that is
+// the group's authority, otherwise it's the place itself.
+func (db *SQLDB) PlaceShared(place int64, feature string) (int64, error) {
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	var authority int64
+	err := db.DB.QueryRow(`
+		SELECT g.authority
+		FROM place_group_members m
+		INNER JOIN place_groups g ON g.id = m.grp
+		INNER JOIN place_group_features f ON f.grp = m.grp
+		WHERE m.place = $1 AND f.feature = $2
+	`, place, feature).Scan(&authority)
+	if errors.Is(err, sql.ErrNoRows) {
+		authority, err = place, nil
+	}
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("feature", feature).
+		Int64("authority", authority).
+		Msg("POSTGRES: got shared place")
+
+	return authority, err
+}
+
+// PlaceSharedMembers returns all the places that share the given feature with
+// the place, including the place itself. If the feature isn't shared, then
+// only the place itself is returned.
+func (db *SQLDB) PlaceSharedMembers(place int64, feature string) ([]int64, error) {
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT other.place
+		FROM place_group_members m
+		INNER JOIN place_group_features f ON f.grp = m.grp
+		INNER JOIN place_group_members other ON other.grp = m.grp
+		WHERE m.place = $1 AND f.feature = $2
+	`, place, feature)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var places []int64
+	for rows.Next() {
+		var p int64
+		if err := rows.Scan(&p); err != nil {
+			return nil, err
+		}
+		places = append(places, p)
+	}
+
+	err = rows.Err()
+
+	log.Debug().
+		Err(err).
+		Int64("place", place).
+		Str("feature", feature).
+		Ints64("places", places).
+		Msg("POSTGRES: got places sharing feature")
+
+	if len(places) == 0 {
+		places = []int64{place}
+	}
+	return places, err
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ScheduledTask is a task that is stored in the database and must be executed
+// at a specific time.
+type ScheduledTask struct {
+	ID       int64
+	When     time.Time
+	Attempts int
+}
+
+// Scheduler executes tasks at their due time. The tasks themselves are kept in
+// the database by whoever uses the scheduler, so nothing is lost if the bot
+// goes down. Tasks that fail are retried with an exponential backoff and after
+// MaxAttempts failures they are marked as dead.
+type Scheduler struct {
+	Name string
+
+	// The maximum number of times a task is attempted before giving up.
+	MaxAttempts int
+	// The delay before the first retry, doubles after every failure.
+	Backoff time.Duration
+	// How far into the future to look for tasks when polling.
+	Lookahead time.Duration
+
+	// Upcoming returns all the tasks, that are not dead, that are due before
+	// the given time.
+	Upcoming func(before time.Time) ([]ScheduledTask, error)
+
+	// Run executes the task. Since tasks may have been modified or deleted
+	// since they were loaded, Run should check that the task still exists and
+	// is actually due. If it returns an error, the task will be retried.
+	Run func(task ScheduledTask) error
+
+	// Retry is called when a task fails and must be attempted again at the
+	// given time.
+	Retry func(task ScheduledTask, attempts int, at time.Time, err error) error
+
+	// Dead is called when a task has failed MaxAttempts times.
+	Dead func(task ScheduledTask, attempts int, err error) error
+
+	lock sync.Mutex
+	// serves as a set essentially
+	waiting map[int64]struct{}
+}
+
+// Start polls for upcoming tasks every interval, in the background.
+func (s *Scheduler) Start(interval time.Duration) {
+	go func() {
+		for {
+			s.Poll()
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Poll queues all the tasks that are due within the lookahead. Tasks that are
+// already queued are ignored. Should be called whenever a task is created or
+// rescheduled to happen sooner than the next poll.
+func (s *Scheduler) Poll() {
+	tasks, err := s.Upcoming(time.Now().Add(s.Lookahead))
+	if err != nil {
+		log.Error().Err(err).Str("scheduler", s.Name).Msg("failed to get upcoming tasks")
+		return
+	}
+	for _, t := range tasks {
+		s.add(t)
+	}
+}
+
+func (s *Scheduler) add(t ScheduledTask) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	slog := log.With().
+		Str("scheduler", s.Name).
+		Int64("id", t.ID).
+		Logger()
+
+	if s.waiting == nil {
+		s.waiting = map[int64]struct{}{}
+	}
+
+	if _, ok := s.waiting[t.ID]; ok {
+		slog.Debug().Msg("task already in queue")
+		return
+	}
+
+	s.waiting[t.ID] = struct{}{}
+	slog.Debug().Time("when", t.When).Msg("added task to queue")
+
+	go func() {
+		time.Sleep(time.Until(t.When))
+		s.run(t)
+		s.del(t.ID)
+		// the task may have been rescheduled, e.g. if it's recurring
+		s.Poll()
+	}()
+}
+
+func (s *Scheduler) del(id int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.waiting, id)
+}
+
+func (s *Scheduler) run(t ScheduledTask) {
+	slog := log.With().
+		Str("scheduler", s.Name).
+		Int64("id", t.ID).
+		Logger()
+
+	err := s.Run(t)
+	if err == nil {
+		return
+	}
+
+	attempts := t.Attempts + 1
+	slog.Error().Err(err).Int("attempts", attempts).Msg("failed to run task")
+
+	if attempts >= s.MaxAttempts {
+		if err := s.Dead(t, attempts, err); err != nil {
+			slog.Error().Err(err).Msg("failed to mark task as dead")
+		}
+		return
+	}
+
+	at := time.Now().Add(s.Backoff << (attempts - 1))
+	if err := s.Retry(t, attempts, at, err); err != nil {
+		slog.Error().Err(err).Msg("failed to schedule retry")
+	}
+}
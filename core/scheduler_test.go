@@ -0,0 +1,219 @@
+package core_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kvlach/janitorjeff/core"
+)
+
+var errTask = errors.New("task failed")
+
+// Keeps the tasks in memory instead of the database. Each task fails the
+// given number of times before succeeding, a negative number means that it
+// always fails.
+type fakeTasks struct {
+	lock  sync.Mutex
+	tasks map[int64]core.ScheduledTask
+	fails int
+
+	runs    map[int64]int
+	retries []int
+	dead    map[int64]int
+	// receives the id of every task that either succeeds or dies
+	done chan int64
+}
+
+func newFakeTasks(fails int, tasks ...core.ScheduledTask) *fakeTasks {
+	f := &fakeTasks{
+		tasks: map[int64]core.ScheduledTask{},
+		fails: fails,
+		runs:  map[int64]int{},
+		dead:  map[int64]int{},
+		done:  make(chan int64, len(tasks)),
+	}
+	for _, t := range tasks {
+		f.tasks[t.ID] = t
+	}
+	return f
+}
+
+func (f *fakeTasks) scheduler(maxAttempts int) *core.Scheduler {
+	return &core.Scheduler{
+		Name:        "test",
+		MaxAttempts: maxAttempts,
+		Backoff:     10 * time.Millisecond,
+		Lookahead:   time.Minute,
+		Upcoming:    f.upcoming,
+		Run:         f.run,
+		Retry:       f.retry,
+		Dead:        f.markDead,
+	}
+}
+
+func (f *fakeTasks) upcoming(before time.Time) ([]core.ScheduledTask, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	var tasks []core.ScheduledTask
+	for _, t := range f.tasks {
+		if _, ok := f.dead[t.ID]; ok {
+			continue
+		}
+		if t.When.Before(before) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeTasks) run(t core.ScheduledTask) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.runs[t.ID]++
+	if f.fails < 0 || f.runs[t.ID] <= f.fails {
+		return errTask
+	}
+	delete(f.tasks, t.ID)
+	f.done <- t.ID
+	return nil
+}
+
+func (f *fakeTasks) retry(t core.ScheduledTask, attempts int, at time.Time, err error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.retries = append(f.retries, attempts)
+	f.tasks[t.ID] = core.ScheduledTask{ID: t.ID, When: at, Attempts: attempts}
+	return nil
+}
+
+func (f *fakeTasks) markDead(t core.ScheduledTask, attempts int, err error) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.dead[t.ID] = attempts
+	f.done <- t.ID
+	return nil
+}
+
+func (f *fakeTasks) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-f.done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for tasks, %d out of %d finished", i, n)
+		}
+	}
+	// give any unexpected extra runs a chance to happen
+	time.Sleep(50 * time.Millisecond)
+}
+
+func TestSchedulerRun(t *testing.T) {
+	now := time.Now()
+	f := newFakeTasks(0,
+		core.ScheduledTask{ID: 1, When: now},
+		core.ScheduledTask{ID: 2, When: now.Add(20 * time.Millisecond)},
+	)
+	s := f.scheduler(3)
+
+	// tasks that are already queued must not be run twice
+	s.Poll()
+	s.Poll()
+	f.wait(t, 2)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for id := int64(1); id <= 2; id++ {
+		if f.runs[id] != 1 {
+			t.Errorf("task %d: expected 1 run, got %d", id, f.runs[id])
+		}
+	}
+	if len(f.retries) != 0 || len(f.dead) != 0 {
+		t.Errorf("expected no retries or dead tasks, got %v and %v", f.retries, f.dead)
+	}
+}
+
+func TestSchedulerNotDue(t *testing.T) {
+	f := newFakeTasks(0, core.ScheduledTask{ID: 1, When: time.Now().Add(time.Hour)})
+	s := f.scheduler(3)
+
+	s.Poll()
+	time.Sleep(50 * time.Millisecond)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.runs[1] != 0 {
+		t.Errorf("expected task outside of the lookahead to not run, got %d runs", f.runs[1])
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	f := newFakeTasks(2, core.ScheduledTask{ID: 1, When: time.Now()})
+	s := f.scheduler(3)
+
+	start := time.Now()
+	s.Poll()
+	f.wait(t, 1)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.runs[1] != 3 {
+		t.Errorf("expected 3 runs, got %d", f.runs[1])
+	}
+	if len(f.retries) != 2 || f.retries[0] != 1 || f.retries[1] != 2 {
+		t.Errorf("expected retries after attempts [1 2], got %v", f.retries)
+	}
+	if len(f.dead) != 0 {
+		t.Errorf("expected no dead tasks, got %v", f.dead)
+	}
+	// 10ms and then 20ms
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("expected the backoff to double, but finished after %s", elapsed)
+	}
+}
+
+func TestSchedulerDead(t *testing.T) {
+	f := newFakeTasks(-1, core.ScheduledTask{ID: 1, When: time.Now()})
+	s := f.scheduler(3)
+
+	s.Poll()
+	f.wait(t, 1)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.runs[1] != 3 {
+		t.Errorf("expected 3 runs, got %d", f.runs[1])
+	}
+	if len(f.retries) != 2 {
+		t.Errorf("expected 2 retries, got %v", f.retries)
+	}
+	if f.dead[1] != 3 {
+		t.Errorf("expected task to die after 3 attempts, got %d", f.dead[1])
+	}
+}
+
+func TestSchedulerDeadResumed(t *testing.T) {
+	// the task had already failed before e.g. the bot restarted
+	f := newFakeTasks(-1, core.ScheduledTask{ID: 1, When: time.Now(), Attempts: 2})
+	s := f.scheduler(3)
+
+	s.Poll()
+	f.wait(t, 1)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.runs[1] != 1 || len(f.retries) != 0 || f.dead[1] != 3 {
+		t.Errorf("expected a single run before dying, got %d runs, retries %v and dead %v",
+			f.runs[1], f.retries, f.dead)
+	}
+}
@@ -115,6 +115,29 @@ func (f *frontend) CreateMessage(author, channel int64, msgID string) (*core.Eve
 	return NewMessage(d.Message, d)
 }
 
+func (f *frontend) Announce(channel int64, text, role string) error {
+	channelID, err := core.DB.ScopeID(channel)
+	if err != nil {
+		return err
+	}
+
+	// only the given role is allowed to be pinged, so that an announcement
+	// can't be used to sneak in an @everyone
+	mentions := &dg.MessageAllowedMentions{
+		Parse: []dg.AllowedMentionType{},
+	}
+	if role != "" {
+		text = fmt.Sprintf("<@&%s> %s", role, text)
+		mentions.Roles = []string{role}
+	}
+
+	_, err = Client.Session.ChannelMessageSendComplex(channelID, &dg.MessageSend{
+		Content:         text,
+		AllowedMentions: mentions,
+	})
+	return err
+}
+
 func (f *frontend) Usage(usage string) any {
 	embed := &dg.MessageEmbed{
 		Title: fmt.Sprintf("Usage: `%s`", usage),
@@ -16,6 +16,10 @@ type InteractionCreate struct {
 	Interaction *dg.InteractionCreate
 	Data        *dg.ApplicationCommandInteractionData
 	VC          *dg.VoiceConnection
+
+	// An interaction can only be responded to once, anything sent after that
+	// has to be a followup message.
+	responded bool
 }
 
 func NewInteractionCreate(i *dg.InteractionCreate, d *dg.ApplicationCommandInteractionData) (*core.EventMessage, error) {
@@ -138,32 +142,43 @@ func (i *InteractionCreate) Person(id string) (int64, error) {
 }
 
 func (i *InteractionCreate) send(msg any, urr error) (*core.EventMessage, error) {
+	var data *dg.InteractionResponseData
+
 	switch t := msg.(type) {
 	case string:
-		resp := &dg.InteractionResponse{
-			Type: dg.InteractionResponseChannelMessageWithSource,
-			Data: &dg.InteractionResponseData{
-				Content: msg.(string),
-			},
+		data = &dg.InteractionResponseData{
+			Content: msg.(string),
 		}
-		return nil, Client.Session.InteractionRespond(i.Interaction.Interaction, resp)
-
 	case *dg.MessageEmbed:
 		embed := msg.(*dg.MessageEmbed)
 		embed = embedColor(embed, urr)
 
-		resp := &dg.InteractionResponse{
-			Type: dg.InteractionResponseChannelMessageWithSource,
-			Data: &dg.InteractionResponseData{
-				Embeds: []*dg.MessageEmbed{
-					embed,
-				},
+		data = &dg.InteractionResponseData{
+			Embeds: []*dg.MessageEmbed{
+				embed,
 			},
 		}
-		return nil, Client.Session.InteractionRespond(i.Interaction.Interaction, resp)
 	default:
 		return nil, fmt.Errorf("Can't send discord message of type %v", t)
 	}
+
+	if i.responded {
+		_, err := Client.Session.FollowupMessageCreate(i.Interaction.Interaction, true, &dg.WebhookParams{
+			Content: data.Content,
+			Embeds:  data.Embeds,
+		})
+		return nil, err
+	}
+
+	resp := &dg.InteractionResponse{
+		Type: dg.InteractionResponseChannelMessageWithSource,
+		Data: data,
+	}
+	if err := Client.Session.InteractionRespond(i.Interaction.Interaction, resp); err != nil {
+		return nil, err
+	}
+	i.responded = true
+	return nil, nil
 }
 
 func (i *InteractionCreate) Send(msg any, urr core.Urr) (*core.EventMessage, error) {
@@ -186,6 +201,10 @@ func (i *InteractionCreate) QuoteCommand(cmd string) string {
 	return PlaceInBackticks(cmd)
 }
 
+func (i *InteractionCreate) Limit() (func(string) int, int) {
+	return textLimit()
+}
+
 /////////////
 //         //
 // Speaker //
@@ -7,7 +7,13 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// MessageDeleteHooks are run every time a message is deleted. Discord only
+// provides the IDs of the message and the channel it was in.
+var MessageDeleteHooks = core.NewHooks[*dg.MessageDelete](5)
+
 func messageDelete(s *dg.Session, m *dg.MessageDelete) {
+	MessageDeleteHooks.Run(m)
+
 	rdbKey := rdbMessageReplyToKeyPrefix + m.ID
 	if r, err := core.RDB.Get(ctx, rdbKey).Result(); err == nil {
 		s.ChannelMessageDelete(m.ChannelID, r)
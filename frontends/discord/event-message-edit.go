@@ -105,3 +105,7 @@ func (d *MessageEdit) Natural(msg any, urr core.Urr) (*core.EventMessage, error)
 func (d *MessageEdit) QuoteCommand(cmd string) string {
 	return PlaceInBackticks(cmd)
 }
+
+func (d *MessageEdit) Limit() (func(string) int, int) {
+	return textLimit()
+}
@@ -62,3 +62,19 @@ func (d *Message) Natural(msg any, urr core.Urr) (*core.EventMessage, error) {
 func (d *Message) QuoteCommand(cmd string) string {
 	return PlaceInBackticks(cmd)
 }
+
+func (d *Message) Limit() (func(string) int, int) {
+	return textLimit()
+}
+
+func (d *Message) Edit(id string, msg any) (*core.EventMessage, error) {
+	switch t := msg.(type) {
+	case string:
+		return editText(d.Message, id, msg.(string))
+	case *dg.MessageEmbed:
+		embed := msg.(*dg.MessageEmbed)
+		return editEmbed(d.Message, embed, nil, id)
+	default:
+		return nil, fmt.Errorf("Can't edit discord message to type %v", t)
+	}
+}
@@ -338,13 +338,18 @@ func msgSend(m *dg.Message, text string, embed *dg.MessageEmbed, ping bool) (*dg
 	return resp, nil
 }
 
+// Returns how the length of a message's text is counted and the maximum length
+// it can have.
+func textLimit() (func(string) int, int) {
+	// TODO: grapheme clusters instead of plain len?
+	return func(s string) int { return len(s) }, 2000
+}
+
 func sendText(m *dg.Message, text string, ping bool) (*core.EventMessage, error) {
 	var resp *dg.Message
 	var err error
 
-	lenLim := 2000
-	// TODO: grapheme clusters instead of plain len?
-	lenCnt := func(s string) int { return len(s) }
+	lenCnt, lenLim := textLimit()
 
 	if lenLim > lenCnt(text) {
 		resp, err = msgSend(m, text, nil, ping)
@@ -104,3 +104,89 @@ func dbGetUserTokens(channelID string) (string, string, error) {
 	err := row.Scan(&accessToken, &refreshToken)
 	return accessToken, refreshToken, err
 }
+
+func dbGetEventsubSecret() (string, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	row := db.DB.QueryRow(`
+		SELECT secret
+		FROM frontend_twitch_eventsub_secret`)
+
+	var secret string
+	err := row.Scan(&secret)
+	return secret, err
+}
+
+func dbSetEventsubSecret(secret string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO frontend_twitch_eventsub_secret(secret)
+		VALUES ($1)
+		ON CONFLICT (id) DO UPDATE SET secret = $1`, secret)
+
+	return err
+}
+
+// A saved EventSub subscription.
+type subscription struct {
+	ID      int64
+	SubID   string
+	SubType string
+	// The broadcaster's twitch ID.
+	Channel string
+}
+
+func dbGetSubscriptions() ([]subscription, error) {
+	db := core.DB
+	db.Lock.RLock()
+	defer db.Lock.RUnlock()
+
+	rows, err := db.DB.Query(`
+		SELECT e.id, e.sub_id, e.sub_type, c.channel_id
+		FROM frontend_twitch_eventsub e
+		JOIN frontend_twitch_channels c ON c.scope = e.channel`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []subscription
+	for rows.Next() {
+		var sub subscription
+		if err := rows.Scan(&sub.ID, &sub.SubID, &sub.SubType, &sub.Channel); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func dbUpdateSubscriptionID(id int64, subID string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		UPDATE frontend_twitch_eventsub
+		SET sub_id = $1
+		WHERE id = $2`, subID, id)
+
+	return err
+}
+
+func dbDeleteSubscription(subID string) error {
+	db := core.DB
+	db.Lock.Lock()
+	defer db.Lock.Unlock()
+
+	_, err := db.DB.Exec(`
+		DELETE FROM frontend_twitch_eventsub
+		WHERE sub_id = $1`, subID)
+
+	return err
+}
@@ -0,0 +1,198 @@
+package twitch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kvlach/gosafe"
+	"github.com/nicklaw5/helix/v2"
+	"github.com/rs/zerolog/log"
+)
+
+const eventsubURL = "wss://eventsub.wss.twitch.tv/ws"
+
+// The ID of the current websocket session, subscriptions must be created with
+// it in order to be delivered over the connection.
+var eventsubSession = gosafe.Value[string]{}
+
+type eventsubMessage struct {
+	Metadata struct {
+		MessageID        string    `json:"message_id"`
+		MessageType      string    `json:"message_type"`
+		MessageTimestamp time.Time `json:"message_timestamp"`
+		SubscriptionType string    `json:"subscription_type"`
+	} `json:"metadata"`
+	Payload struct {
+		Session struct {
+			ID                      string `json:"id"`
+			KeepaliveTimeoutSeconds int    `json:"keepalive_timeout_seconds"`
+			ReconnectURL            string `json:"reconnect_url"`
+		} `json:"session"`
+		Subscription helix.EventSubSubscription `json:"subscription"`
+		Event        json.RawMessage            `json:"event"`
+	} `json:"payload"`
+}
+
+// Returns how long to wait for a message before considering the connection
+// dead. Twitch sends a keepalive message if nothing else has been sent within
+// the timeout, a few seconds are added to account for latency.
+func (msg eventsubMessage) keepalive() time.Duration {
+	secs := msg.Payload.Session.KeepaliveTimeoutSeconds
+	if secs <= 0 {
+		secs = 10
+	}
+	return time.Duration(secs)*time.Second + 5*time.Second
+}
+
+// Connects to the url and waits for the welcome message.
+func eventsubDial(url string) (*websocket.Conn, eventsubMessage, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return nil, eventsubMessage{}, err
+	}
+
+	var welcome eventsubMessage
+	if err := conn.SetReadDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		conn.Close()
+		return nil, eventsubMessage{}, err
+	}
+	if err := conn.ReadJSON(&welcome); err != nil {
+		conn.Close()
+		return nil, eventsubMessage{}, err
+	}
+	if t := welcome.Metadata.MessageType; t != "session_welcome" {
+		conn.Close()
+		return nil, eventsubMessage{}, fmt.Errorf("expected welcome message, got '%s'", t)
+	}
+
+	log.Debug().
+		Str("session", welcome.Payload.Session.ID).
+		Int("keepalive", welcome.Payload.Session.KeepaliveTimeoutSeconds).
+		Msg("connected to eventsub websocket")
+
+	return conn, welcome, nil
+}
+
+// Keeps a websocket connection to EventSub open until stop is closed,
+// reconnecting whenever the connection is lost. Every new session starts
+// without any subscriptions, so all of them are re-created.
+func eventsubListen(stop chan struct{}) {
+	backoff := time.Second
+
+	for {
+		conn, welcome, err := eventsubDial(eventsubURL)
+		if err != nil {
+			log.Error().Err(err).Dur("backoff", backoff).Msg("failed to connect to eventsub websocket")
+			select {
+			case <-stop:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, 2*time.Minute)
+			continue
+		}
+		backoff = time.Second
+
+		eventsubSession.Set(welcome.Payload.Session.ID)
+		// subscriptions must be created within 10 seconds of the welcome
+		// message, otherwise twitch closes the connection
+		if err := eventsubRecreate(); err != nil {
+			log.Error().Err(err).Msg("failed to re-create subscriptions")
+		}
+
+		err = eventsubRead(conn, welcome.keepalive(), stop)
+		if err == nil {
+			return
+		}
+		log.Warn().Err(err).Msg("lost eventsub websocket connection, reconnecting")
+	}
+}
+
+// Reads messages until the connection is lost, in which case the error is
+// returned, or until stop is closed, in which case nil is returned.
+func eventsubRead(conn *websocket.Conn, keepalive time.Duration, stop chan struct{}) error {
+	var current gosafe.Value[*websocket.Conn]
+	current.Set(conn)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			current.Get().Close()
+		case <-done:
+		}
+	}()
+
+	stopped := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+
+	for {
+		conn := current.Get()
+
+		var msg eventsubMessage
+		err := conn.SetReadDeadline(time.Now().Add(keepalive))
+		if err == nil {
+			err = conn.ReadJSON(&msg)
+		}
+		if err != nil {
+			conn.Close()
+			if stopped() {
+				return nil
+			}
+			return err
+		}
+
+		switch t := msg.Metadata.MessageType; t {
+		case "session_keepalive":
+			// the read deadline gets pushed back, nothing else to do
+
+		case "notification":
+			eventsubDispatch(
+				msg.Metadata.MessageID,
+				msg.Metadata.MessageTimestamp,
+				msg.Metadata.SubscriptionType,
+				msg.Payload.Event,
+			)
+
+		case "session_reconnect":
+			// twitch is about to close the connection, the subscriptions are
+			// carried over to the new one so they don't have to be re-created
+			next, welcome, err := eventsubDial(msg.Payload.Session.ReconnectURL)
+			if err != nil {
+				conn.Close()
+				return err
+			}
+			current.Set(next)
+			conn.Close()
+			eventsubSession.Set(welcome.Payload.Session.ID)
+			keepalive = welcome.keepalive()
+			if stopped() {
+				next.Close()
+				return nil
+			}
+
+		case "revocation":
+			sub := msg.Payload.Subscription
+			log.Warn().
+				Str("id", sub.ID).
+				Str("type", sub.Type).
+				Str("status", sub.Status).
+				Msg("eventsub subscription was revoked")
+			if err := dbDeleteSubscription(sub.ID); err != nil {
+				log.Error().Err(err).Msg("failed to delete revoked subscription")
+			}
+
+		default:
+			log.Debug().Msgf("unhandled eventsub message type '%s'", t)
+		}
+	}
+}
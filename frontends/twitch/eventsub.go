@@ -3,10 +3,11 @@ package twitch
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/kvlach/janitorjeff/core"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kvlach/gosafe"
 	"github.com/nicklaw5/helix/v2"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
@@ -25,13 +27,22 @@ type eventSubNotification struct {
 	Event        json.RawMessage            `json:"event"`
 }
 
+const CallbackEventSub = "/twitch/eventsub"
+
+// The ways EventSub notifications can be received.
 const (
-	CallbackEventSub = "/twitch/eventsub"
-	secret           = "secretword"
+	// Twitch sends the notifications to CallbackEventSub, which means that
+	// core.VirtualHost must be reachable over HTTPS.
+	EventSubWebhook = "webhook"
+	// The bot connects to Twitch and receives the notifications over a
+	// websocket, useful when the bot isn't publicly reachable.
+	EventSubWebSocket = "websocket"
 )
 
 var ctx = context.Background()
 
+var eventsubSecret = gosafe.Value[string]{}
+
 func init() {
 	core.Gin.POST(CallbackEventSub, func(c *gin.Context) {
 		body, err := io.ReadAll(c.Request.Body)
@@ -41,13 +52,12 @@ func init() {
 		}
 		defer c.Request.Body.Close()
 
-		if !helix.VerifyEventSubNotification(secret, c.Request.Header, string(body)) {
+		if !helix.VerifyEventSubNotification(eventsubSecret.Get(), c.Request.Header, string(body)) {
 			log.Warn().Msg("no valid signature on subscription")
 			return
 		} else {
 			log.Debug().Msg("valid signature for subscription")
 		}
-		fmt.Println(string(body))
 
 		var vals eventSubNotification
 		err = json.NewDecoder(bytes.NewReader(body)).Decode(&vals)
@@ -70,142 +80,434 @@ func init() {
 				Msg("failed to parse timestamp")
 			return
 		}
-		if time.Now().Sub(when) > 10*time.Minute {
-			log.Error().Msg("message older than 10 minutes, skipping")
+
+		id := c.GetHeader("Twitch-Eventsub-Message-Id")
+		eventsubDispatch(id, when, vals.Subscription.Type, vals.Event)
+
+		c.String(http.StatusOK, "ok")
+	})
+}
+
+// Decodes the notification's event and sends it to the event loop. Both
+// transports end up here. Notifications older than 10 minutes or that have
+// already been processed are skipped.
+func eventsubDispatch(id string, when time.Time, t string, event json.RawMessage) {
+	if time.Now().Sub(when) > 10*time.Minute {
+		log.Error().Msg("message older than 10 minutes, skipping")
+		return
+	}
+
+	rdbKey := "frontend_twitch_eventsub_" + id
+
+	if err := core.RDB.Get(ctx, rdbKey).Err(); err != redis.Nil {
+		log.Debug().
+			Str("id", id).
+			Msg("message id has already been processed, skipping")
+		return
+	}
+
+	log.Debug().Str("id", id).Msg("caching eventsub message id")
+	if err := core.RDB.Set(ctx, rdbKey, nil, 10*time.Minute).Err(); err != nil {
+		log.Error().Err(err).Str("id", id).Msg("failed to cache event id")
+		return
+	}
+
+	switch t {
+	case "stream.online":
+		var onlineEvent helix.EventSubStreamOnlineEvent
+		if err := json.Unmarshal(event, &onlineEvent); err != nil {
+			log.Error().Err(err).Msg("failed to decode online event")
+			return
+		}
+		log.Debug().Msgf("got online webhook for channel: %s\n", onlineEvent.BroadcasterUserName)
+
+		a, err := NewAuthor(
+			onlineEvent.BroadcasterUserID,
+			onlineEvent.BroadcasterUserLogin,
+			onlineEvent.BroadcasterUserName,
+			onlineEvent.BroadcasterUserID,
+			nil,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("online", onlineEvent).
+				Msg("failed to parse online event's author")
 			return
 		}
 
-		id := c.GetHeader("Twitch-Eventsub-Message-Id")
-		rdbKey := "frontend_twitch_eventsub_" + id
+		h, err := NewHere(
+			onlineEvent.BroadcasterUserID,
+			onlineEvent.BroadcasterUserLogin,
+			a,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("online", onlineEvent).
+				Msg("failed to parse online event's here")
+			return
+		}
+
+		core.NewEventStreamOnline(onlineEvent.StartedAt.Time, h, Frontend).Send()
 
-		if err := core.RDB.Get(ctx, rdbKey).Err(); err != redis.Nil {
-			log.Debug().
-				Str("id", id).
-				Msg("message id has already been processed, skipping")
+	case "stream.offline":
+		var offlineEvent helix.EventSubStreamOfflineEvent
+		if err := json.Unmarshal(event, &offlineEvent); err != nil {
+			log.Error().Err(err).Msg("failed to decode offline event")
+			return
+		}
+		log.Debug().Msgf("got offline webhook for channel: %s\n", offlineEvent.BroadcasterUserName)
+
+		a, err := NewAuthor(
+			offlineEvent.BroadcasterUserID,
+			offlineEvent.BroadcasterUserLogin,
+			offlineEvent.BroadcasterUserName,
+			offlineEvent.BroadcasterUserID,
+			nil,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("offline", offlineEvent).
+				Msg("failed to parse offline event's author")
 			return
 		}
 
-		log.Debug().Str("id", id).Msg("caching eventsub message id")
-		if err := core.RDB.Set(ctx, rdbKey, nil, 10*time.Minute).Err(); err != nil {
-			log.Error().Err(err).Str("id", id).Msg("failed to cache event id")
+		h, err := NewHere(
+			offlineEvent.BroadcasterUserID,
+			offlineEvent.BroadcasterUserLogin,
+			a,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("offline", offlineEvent).
+				Msg("failed to parse offline event's here")
 			return
 		}
 
-		switch t := vals.Subscription.Type; t {
-		case "stream.online":
-			var onlineEvent helix.EventSubStreamOnlineEvent
-			err = json.NewDecoder(bytes.NewReader(vals.Event)).Decode(&onlineEvent)
-			log.Debug().Msgf("got online webhook for channel: %s\n", onlineEvent.BroadcasterUserName)
-
-			a, err := NewAuthor(
-				onlineEvent.BroadcasterUserID,
-				onlineEvent.BroadcasterUserLogin,
-				onlineEvent.BroadcasterUserName,
-				onlineEvent.BroadcasterUserID,
-				nil,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("online", onlineEvent).
-					Msg("failed to parse online event's author")
-				return
-			}
-
-			h, err := NewHere(
-				onlineEvent.BroadcasterUserID,
-				onlineEvent.BroadcasterUserLogin,
-				a,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("online", onlineEvent).
-					Msg("failed to parse online event's here")
-				return
-			}
-
-			core.NewEventStreamOnline(onlineEvent.StartedAt.Time, h, Frontend).Send()
-
-		case "stream.offline":
-			var offlineEvent helix.EventSubStreamOfflineEvent
-			err = json.NewDecoder(bytes.NewReader(vals.Event)).Decode(&offlineEvent)
-			log.Debug().Msgf("got offline webhook for channel: %s\n", offlineEvent.BroadcasterUserName)
-
-			a, err := NewAuthor(
-				offlineEvent.BroadcasterUserID,
-				offlineEvent.BroadcasterUserLogin,
-				offlineEvent.BroadcasterUserName,
-				offlineEvent.BroadcasterUserID,
-				nil,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("offline", offlineEvent).
-					Msg("failed to parse offline event's author")
-				return
-			}
-
-			h, err := NewHere(
-				offlineEvent.BroadcasterUserID,
-				offlineEvent.BroadcasterUserLogin,
-				a,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("offline", offlineEvent).
-					Msg("failed to parse offline event's here")
-				return
-			}
-
-			core.NewEventStreamOffline(time.Now().UTC(), h, Frontend).Send()
-
-		case "channel.channel_points_custom_reward_redemption.add":
-			var redeem helix.EventSubChannelPointsCustomRewardRedemptionEvent
-			err = json.NewDecoder(bytes.NewReader(vals.Event)).Decode(&redeem)
-			log.Debug().
-				Str("broadcaster", redeem.BroadcasterUserName).
-				Str("redeemer", redeem.UserName).
-				Msg("got channel redeem event")
-
-			a, err := NewAuthor(
-				redeem.UserID,
-				redeem.UserLogin,
-				redeem.UserName,
-				redeem.BroadcasterUserID,
-				nil,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("redeem", redeem).
-					Msg("failed to parse redeem's author")
-				return
-			}
-
-			h, err := NewHere(
-				redeem.BroadcasterUserID,
-				redeem.BroadcasterUserName,
-				a,
-			)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Interface("redeem", redeem).
-					Msg("failed to parse redeem's here")
-				return
-			}
-
-			core.NewEventRedeemClaim(redeem.Reward.ID, redeem.UserInput, redeem.RedeemedAt.Time, a, h, Frontend).Send()
-
-		default:
-			log.Debug().Msgf("unhandled event type '%s'", t)
+		core.NewEventStreamOffline(time.Now().UTC(), h, Frontend).Send()
+
+	case "channel.channel_points_custom_reward_redemption.add":
+		var redeem helix.EventSubChannelPointsCustomRewardRedemptionEvent
+		if err := json.Unmarshal(event, &redeem); err != nil {
+			log.Error().Err(err).Msg("failed to decode redeem event")
+			return
+		}
+		log.Debug().
+			Str("broadcaster", redeem.BroadcasterUserName).
+			Str("redeemer", redeem.UserName).
+			Msg("got channel redeem event")
+
+		a, err := NewAuthor(
+			redeem.UserID,
+			redeem.UserLogin,
+			redeem.UserName,
+			redeem.BroadcasterUserID,
+			nil,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("redeem", redeem).
+				Msg("failed to parse redeem's author")
+			return
 		}
 
-		c.String(http.StatusOK, "ok")
-	})
+		h, err := NewHere(
+			redeem.BroadcasterUserID,
+			redeem.BroadcasterUserName,
+			a,
+		)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Interface("redeem", redeem).
+				Msg("failed to parse redeem's here")
+			return
+		}
+
+		core.NewEventRedeemClaim(redeem.Reward.ID, redeem.ID, redeem.UserInput, redeem.RedeemedAt.Time, a, h, Frontend).Send()
+
+	case helix.EventSubTypeChannelFollow:
+		var follow helix.EventSubChannelFollowEvent
+		if err := json.Unmarshal(event, &follow); err != nil {
+			log.Error().Err(err).Msg("failed to decode follow event")
+			return
+		}
+
+		a, h, err := eventAuthorHere(follow.UserID, follow.UserLogin, follow.UserName,
+			follow.BroadcasterUserID, follow.BroadcasterUserLogin)
+		if err != nil {
+			log.Error().Err(err).Interface("follow", follow).Msg("failed to parse follow event")
+			return
+		}
+
+		core.NewEventFollow(a, follow.FollowedAt.Time, h, Frontend).Send()
+
+	case helix.EventSubTypeChannelSubscription:
+		var sub helix.EventSubChannelSubscribeEvent
+		if err := json.Unmarshal(event, &sub); err != nil {
+			log.Error().Err(err).Msg("failed to decode subscribe event")
+			return
+		}
+
+		a, h, err := eventAuthorHere(sub.UserID, sub.UserLogin, sub.UserName,
+			sub.BroadcasterUserID, sub.BroadcasterUserLogin)
+		if err != nil {
+			log.Error().Err(err).Interface("sub", sub).Msg("failed to parse subscribe event")
+			return
+		}
+
+		core.NewEventSubscribe(sub.Tier, 1, sub.IsGift, "", a, when, h, Frontend).Send()
+
+	case helix.EventSubTypeChannelSubscriptionMessage:
+		var resub helix.EventSubChannelSubscriptionMessageEvent
+		if err := json.Unmarshal(event, &resub); err != nil {
+			log.Error().Err(err).Msg("failed to decode resubscribe event")
+			return
+		}
+
+		a, h, err := eventAuthorHere(resub.UserID, resub.UserLogin, resub.UserName,
+			resub.BroadcasterUserID, resub.BroadcasterUserLogin)
+		if err != nil {
+			log.Error().Err(err).Interface("resub", resub).Msg("failed to parse resubscribe event")
+			return
+		}
+
+		core.NewEventSubscribe(resub.Tier, resub.CumulativeMonths, false, resub.Message.Text,
+			a, when, h, Frontend).Send()
+
+	case helix.EventSubTypeChannelRaid:
+		var raid helix.EventSubChannelRaidEvent
+		if err := json.Unmarshal(event, &raid); err != nil {
+			log.Error().Err(err).Msg("failed to decode raid event")
+			return
+		}
+
+		a, h, err := eventAuthorHere(raid.FromBroadcasterUserID, raid.FromBroadcasterUserLogin,
+			raid.FromBroadcasterUserName, raid.ToBroadcasterUserID, raid.ToBroadcasterUserLogin)
+		if err != nil {
+			log.Error().Err(err).Interface("raid", raid).Msg("failed to parse raid event")
+			return
+		}
+
+		core.NewEventRaid(raid.Viewers, a, when, h, Frontend).Send()
+
+	case helix.EventSubTypeChannelCheer:
+		var cheer helix.EventSubChannelCheerEvent
+		if err := json.Unmarshal(event, &cheer); err != nil {
+			log.Error().Err(err).Msg("failed to decode cheer event")
+			return
+		}
+
+		// anonymous cheers don't include the user, the broadcaster is used
+		// instead in order to create the here object
+		userID, userLogin, userName := cheer.UserID, cheer.UserLogin, cheer.UserName
+		if cheer.IsAnonymous {
+			userID, userLogin, userName = cheer.BroadcasterUserID, cheer.BroadcasterUserLogin, cheer.BroadcasterUserName
+		}
+
+		a, h, err := eventAuthorHere(userID, userLogin, userName,
+			cheer.BroadcasterUserID, cheer.BroadcasterUserLogin)
+		if err != nil {
+			log.Error().Err(err).Interface("cheer", cheer).Msg("failed to parse cheer event")
+			return
+		}
+		if cheer.IsAnonymous {
+			a = nil
+		}
+
+		core.NewEventCheer(cheer.Bits, cheer.Message, a, when, h, Frontend).Send()
+
+	default:
+		log.Debug().Msgf("unhandled event type '%s'", t)
+	}
+}
+
+// Loads the secret that webhook notifications are signed with, a random one is
+// generated on the first run. Returns true if the secret was just generated,
+// in which case any existing subscriptions were signed with a different
+// secret and have to be re-created.
+func eventsubLoadSecret() (bool, error) {
+	secret, err := dbGetEventsubSecret()
+	if err == nil {
+		eventsubSecret.Set(secret)
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	// twitch allows secrets between 10 and 100 characters
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return false, err
+	}
+	secret = hex.EncodeToString(b)
+	if err := dbSetEventsubSecret(secret); err != nil {
+		return false, err
+	}
+	eventsubSecret.Set(secret)
+	log.Info().Msg("generated new eventsub secret")
+	return true, nil
+}
+
+// Returns the client used to manage the broadcaster's subscriptions. Webhook
+// subscriptions are created with the app access token, while websocket ones
+// require the broadcaster's user access token.
+func eventsubHelix(broadcasterID string) (*Helix, error) {
+	if Frontend.EventSub == EventSubWebSocket {
+		return NewHelix(broadcasterID)
+	}
+	return Frontend.Helix()
+}
+
+// Returns the transport that new subscriptions are delivered over.
+func eventsubTransport() helix.EventSubTransport {
+	if Frontend.EventSub == EventSubWebSocket {
+		return helix.EventSubTransport{
+			Method:    "websocket",
+			SessionID: eventsubSession.Get(),
+		}
+	}
+	return helix.EventSubTransport{
+		Method:   "webhook",
+		Callback: "https://" + core.VirtualHost + CallbackEventSub,
+		Secret:   eventsubSecret.Get(),
+	}
+}
+
+// Re-creates all the saved subscriptions. Used when the old ones can no longer
+// deliver notifications, i.e. when a new websocket session is started or when
+// the webhook secret has changed.
+func eventsubRecreate() error {
+	subs, err := dbGetSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if err := eventsubRecreateOne(sub); err != nil {
+			return err
+		}
+	}
+
+	log.Debug().Int("#subs", len(subs)).Msg("re-created subscriptions")
+	return nil
+}
+
+// Only returns an error if updating the database fails, failing to create the
+// subscription just gets logged.
+func eventsubRecreateOne(sub subscription) error {
+	hx, err := eventsubHelix(sub.Channel)
+	if err != nil {
+		return err
+	}
+
+	// websocket subscriptions get deleted automatically when their session
+	// ends, so it's expected for this to fail sometimes
+	if err := hx.DeleteSubscription(sub.SubID); err != nil {
+		log.Debug().Err(err).Str("id", sub.SubID).Msg("failed to delete old subscription")
+	}
+
+	subID, err := hx.CreateSubscription(sub.Channel, sub.SubType)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("type", sub.SubType).
+			Str("channel", sub.Channel).
+			Msg("failed to re-create subscription")
+		return nil
+	}
+	return dbUpdateSubscriptionID(sub.ID, subID)
+}
+
+// Re-creates the saved subscriptions that twitch doesn't deliver to the
+// configured webhook, e.g. the ones that were created while using websockets,
+// for a different callback or the ones that twitch has disabled.
+func eventsubRecreateMismatched() error {
+	subs, err := dbGetSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	hx, err := Frontend.Helix()
+	if err != nil {
+		return err
+	}
+	// no subscriptions at all means that every saved one has to be re-created
+	existing, err := hx.ListSubscriptions()
+	if err != nil && err != ErrNoResults {
+		return err
+	}
+
+	want := eventsubTransport()
+	ok := make(map[string]struct{}, len(existing))
+	for _, e := range existing {
+		if e.Transport.Method != want.Method || e.Transport.Callback != want.Callback {
+			continue
+		}
+		if e.Status != helix.EventSubStatusEnabled && e.Status != helix.EventSubStatusPending {
+			continue
+		}
+		ok[e.ID] = struct{}{}
+	}
+
+	n := 0
+	for _, sub := range subs {
+		if _, found := ok[sub.SubID]; found {
+			continue
+		}
+		if err := eventsubRecreateOne(sub); err != nil {
+			return err
+		}
+		n++
+	}
+
+	log.Debug().Int("#subs", n).Msg("re-created mismatched subscriptions")
+	return nil
+}
+
+// Prepares the configured EventSub transport, the websocket connection is
+// kept alive until stop is closed.
+func eventsubInit(stop chan struct{}) error {
+	if Frontend.EventSub == EventSubWebSocket {
+		go eventsubListen(stop)
+		return nil
+	}
+
+	fresh, err := eventsubLoadSecret()
+	if err != nil {
+		return err
+	}
+	go func() {
+		var err error
+		if fresh {
+			err = eventsubRecreate()
+		} else {
+			err = eventsubRecreateMismatched()
+		}
+		if err != nil {
+			log.Error().Err(err).Msg("failed to re-create subscriptions")
+		}
+	}()
+	return nil
+}
+
+// Returns the author and here of an event that happened in the broadcaster's
+// channel.
+func eventAuthorHere(userID, userLogin, userName, broadcasterID, broadcasterLogin string) (core.Personifier, core.Placer, error) {
+	a, err := NewAuthor(userID, userLogin, userName, broadcasterID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := NewHere(broadcasterID, broadcasterLogin, a)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, h, nil
 }
 
 // EventsubEnsureCreated creates all subscriptions not already registered for place.
@@ -213,12 +515,12 @@ func init() {
 // not modifying the ones that existed before the function call.
 // If that fails, it will log an error.
 func EventsubEnsureCreated(place int64, subTypes ...string) error {
-	hx, err := Frontend.Helix()
+	broadcasterID, err := dbGetChannel(place)
 	if err != nil {
 		return err
 	}
 
-	broadcasterID, err := dbGetChannel(place)
+	hx, err := eventsubHelix(broadcasterID)
 	if err != nil {
 		return err
 	}
@@ -290,7 +592,12 @@ func EventsubEnsureCreated(place int64, subTypes ...string) error {
 // EventsubEnsureDeleted deletes all provided subscriptions.
 // Skips over any not found in the database.
 func EventsubEnsureDeleted(place int64, subTypes ...string) error {
-	hx, err := Frontend.Helix()
+	broadcasterID, err := dbGetChannel(place)
+	if err != nil {
+		return err
+	}
+
+	hx, err := eventsubHelix(broadcasterID)
 	if err != nil {
 		return err
 	}
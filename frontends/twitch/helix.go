@@ -1,11 +1,13 @@
 package twitch
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-
-	"github.com/kvlach/janitorjeff/core"
+	"time"
 
 	"github.com/kvlach/gosafe"
 	"github.com/nicklaw5/helix/v2"
@@ -16,6 +18,7 @@ var (
 	ErrRetry             = errors.New("refresh the access token and try again")
 	ErrNoResults         = errors.New("couldn't find what you were looking for")
 	ErrUserTokenRequired = errors.New("this channel's broadcaster must connect their twitch account to the bot")
+	ErrNotManageable     = errors.New("the reward wasn't created by the bot, so it can't be changed")
 )
 
 var appAccessToken = gosafe.Value[string]{}
@@ -398,18 +401,518 @@ func (hx *Helix) RedeemsList(broadcasterID string) ([]helix.ChannelCustomReward,
 	}
 }
 
+// RedeemsManageable returns the broadcaster's rewards that were created by the
+// bot, which are the only ones whose redemptions it can update.
+func (hx *Helix) RedeemsManageable(broadcasterID string) ([]helix.ChannelCustomReward, error) {
+	resp, err := hx.c.GetCustomRewards(&helix.GetCustomRewardsParams{
+		BroadcasterID:         broadcasterID,
+		OnlyManageableRewards: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.ChannelCustomRewards))
+
+	switch err {
+	case nil:
+		log.Debug().
+			Interface("redeems", resp.Data.ChannelCustomRewards).
+			Msg("got manageable redeems")
+		return resp.Data.ChannelCustomRewards, nil
+	case ErrNoResults:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.RedeemsManageable(broadcasterID)
+	default:
+		return nil, err
+	}
+}
+
+// Returns the user error for a rejected channel points request. Rewards can
+// only be changed by the client that created them, anything else is usually
+// something like a duplicate title or an invalid cost, in which case twitch's
+// message is passed along.
+func redeemUrr(resp helix.ResponseCommon) error {
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		return ErrNotManageable
+	case http.StatusBadRequest, http.StatusNotFound:
+		return errors.New(resp.ErrorMessage)
+	default:
+		return nil
+	}
+}
+
+// RedeemGet returns the broadcaster's reward with the given ID. The returned
+// user error is ErrNoResults if the reward doesn't exist.
+func (hx *Helix) RedeemGet(broadcasterID, id string) (helix.ChannelCustomReward, error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return helix.ChannelCustomReward{}, ErrUserTokenRequired, nil
+	}
+
+	resp, err := hx.c.GetCustomRewards(&helix.GetCustomRewardsParams{
+		BroadcasterID: broadcasterID,
+		ID:            id,
+	})
+	if err != nil {
+		return helix.ChannelCustomReward{}, nil, err
+	}
+	if urr := redeemUrr(resp.ResponseCommon); urr != nil {
+		return helix.ChannelCustomReward{}, urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.ChannelCustomRewards))
+
+	switch err {
+	case nil:
+		return resp.Data.ChannelCustomRewards[0], nil, nil
+	case ErrNoResults:
+		return helix.ChannelCustomReward{}, ErrNoResults, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return helix.ChannelCustomReward{}, nil, err
+		}
+		return hx.RedeemGet(broadcasterID, id)
+	default:
+		return helix.ChannelCustomReward{}, nil, err
+	}
+}
+
+// RedeemCreate creates a new reward in the broadcaster's channel.
+func (hx *Helix) RedeemCreate(params *helix.ChannelCustomRewardsParams) (helix.ChannelCustomReward, error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return helix.ChannelCustomReward{}, ErrUserTokenRequired, nil
+	}
+
+	resp, err := hx.c.CreateCustomReward(params)
+	if err != nil {
+		return helix.ChannelCustomReward{}, nil, err
+	}
+	if urr := redeemUrr(resp.ResponseCommon); urr != nil {
+		return helix.ChannelCustomReward{}, urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.ChannelCustomRewards))
+
+	switch err {
+	case nil:
+		r := resp.Data.ChannelCustomRewards[0]
+		log.Debug().Interface("reward", r).Msg("created reward")
+		return r, nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return helix.ChannelCustomReward{}, nil, err
+		}
+		return hx.RedeemCreate(params)
+	default:
+		return helix.ChannelCustomReward{}, nil, err
+	}
+}
+
+// RedeemUpdate replaces the reward's settings with the given ones. Every
+// setting is sent, so the reward should first be fetched with RedeemGet and
+// then modified.
+func (hx *Helix) RedeemUpdate(r helix.ChannelCustomReward) (helix.ChannelCustomReward, error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return helix.ChannelCustomReward{}, ErrUserTokenRequired, nil
+	}
+
+	resp, err := hx.c.UpdateCustomReward(&helix.UpdateChannelCustomRewardsParams{
+		ID:                                r.ID,
+		BroadcasterID:                     r.BroadcasterID,
+		Title:                             r.Title,
+		Cost:                              r.Cost,
+		Prompt:                            r.Prompt,
+		IsEnabled:                         r.IsEnabled,
+		BackgroundColor:                   r.BackgroundColor,
+		IsUserInputRequired:               r.IsUserInputRequired,
+		IsMaxPerStreamEnabled:             r.MaxPerStreamSetting.IsEnabled,
+		MaxPerStream:                      r.MaxPerStreamSetting.MaxPerStream,
+		IsMaxPerUserPerStreamEnabled:      r.MaxPerUserPerStreamSetting.IsEnabled,
+		MaxPerUserPerStream:               r.MaxPerUserPerStreamSetting.MaxPerUserPerStream,
+		IsGlobalCooldownEnabled:           r.GlobalCooldownSetting.IsEnabled,
+		GlobalCooldownSeconds:             r.GlobalCooldownSetting.GlobalCooldownSeconds,
+		ShouldRedemptionsSkipRequestQueue: r.ShouldRedemptionsSkipRequestQueue,
+	})
+	if err != nil {
+		return helix.ChannelCustomReward{}, nil, err
+	}
+	if urr := redeemUrr(resp.ResponseCommon); urr != nil {
+		return helix.ChannelCustomReward{}, urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.ChannelCustomRewards))
+
+	switch err {
+	case nil:
+		return resp.Data.ChannelCustomRewards[0], nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return helix.ChannelCustomReward{}, nil, err
+		}
+		return hx.RedeemUpdate(r)
+	default:
+		return helix.ChannelCustomReward{}, nil, err
+	}
+}
+
+// Makes a request that the wrapper library doesn't support, using the user
+// access token. The body is encoded as JSON if it's not nil. Only the status
+// code and twitch's error message are returned, if there is one.
+func (hx *Helix) request(method, url string, body any) (helix.ResponseCommon, error) {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return helix.ResponseCommon{}, err
+		}
+		r = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return helix.ResponseCommon{}, err
+	}
+	req.Header.Set("Client-ID", ClientID)
+	req.Header.Set("Authorization", "Bearer "+hx.c.GetUserAccessToken())
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return helix.ResponseCommon{}, err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&payload)
+
+	return helix.ResponseCommon{
+		StatusCode:   resp.StatusCode,
+		ErrorMessage: payload.Message,
+	}, nil
+}
+
+// RedeemPause pauses or unpauses the reward. A paused reward is still shown
+// to viewers but can't be redeemed.
+func (hx *Helix) RedeemPause(broadcasterID, id string, paused bool) (error, error) {
+	// The wrapper library doesn't support changing is_paused, so the request
+	// is made manually. Fetching the reward first makes sure that it exists
+	// and that the user access token has been refreshed if it had expired.
+	_, urr, err := hx.RedeemGet(broadcasterID, id)
+	if urr != nil || err != nil {
+		return urr, err
+	}
+
+	url := fmt.Sprintf("https://api.twitch.tv/helix/channel_points/custom_rewards?broadcaster_id=%s&id=%s",
+		broadcasterID, id)
+	resp, err := hx.request(http.MethodPatch, url, map[string]bool{"is_paused": paused})
+	if err != nil {
+		return nil, err
+	}
+	if urr := redeemUrr(resp); urr != nil {
+		return urr, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%d: %s", resp.StatusCode, resp.ErrorMessage)
+	}
+
+	log.Debug().Str("id", id).Bool("paused", paused).Msg("changed reward's paused state")
+	return nil, nil
+}
+
+// RedeemDelete deletes the reward from the broadcaster's channel.
+func (hx *Helix) RedeemDelete(broadcasterID, id string) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	resp, err := hx.c.DeleteCustomRewards(&helix.DeleteCustomRewardsParams{
+		BroadcasterID: broadcasterID,
+		ID:            id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if urr := redeemUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, 1)
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.RedeemDelete(broadcasterID, id)
+	default:
+		return nil, err
+	}
+}
+
+// RedemptionUpdate marks the redemption as fulfilled, or as canceled in which
+// case the viewer gets their points back. Only redemptions of rewards created
+// by the bot that are still in the request queue can be updated.
+func (hx *Helix) RedemptionUpdate(broadcasterID, rewardID, redemptionID string, fulfilled bool) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	status := "CANCELED"
+	if fulfilled {
+		status = "FULFILLED"
+	}
+
+	resp, err := hx.c.UpdateChannelCustomRewardsRedemptionStatus(&helix.UpdateChannelCustomRewardsRedemptionStatusParams{
+		ID:            redemptionID,
+		BroadcasterID: broadcasterID,
+		RewardID:      rewardID,
+		Status:        status,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if urr := redeemUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.Redemptions))
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrNoResults:
+		return ErrNoResults, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.RedemptionUpdate(broadcasterID, rewardID, redemptionID, fulfilled)
+	default:
+		return nil, err
+	}
+}
+
+// Returns the user error for a rejected moderation request. Twitch's message
+// is passed along since it explains what went wrong, for example that the user
+// is already banned or that a cooldown is still active.
+func modUrr(resp helix.ResponseCommon) error {
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusTooManyRequests:
+		return errors.New(resp.ErrorMessage)
+	default:
+		return nil
+	}
+}
+
+// Shoutout sends a native shoutout from the broadcaster to another channel,
+// which is only possible while the broadcaster is live. Twitch's own cooldowns
+// apply, hitting them returns a user error with twitch's message.
+func (hx *Helix) Shoutout(fromID, toID string) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	// Fetching the channel first makes sure that it exists and that the user
+	// access token has been refreshed if it had expired.
+	if _, err := hx.GetChannelInfo(toID); err != nil {
+		return nil, err
+	}
+
+	// the broadcaster is always a moderator of their own channel
+	url := fmt.Sprintf("https://api.twitch.tv/helix/chat/shoutouts?from_broadcaster_id=%s&to_broadcaster_id=%s&moderator_id=%s",
+		fromID, toID, fromID)
+	resp, err := hx.request(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if urr := modUrr(resp); urr != nil {
+		return urr, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%d: %s", resp.StatusCode, resp.ErrorMessage)
+	}
+
+	log.Debug().Str("from", fromID).Str("to", toID).Msg("sent shoutout")
+	return nil, nil
+}
+
+// Ban bans the user from the broadcaster's chat. If the duration isn't zero
+// the user is timed out instead, twitch allows up to two weeks.
+func (hx *Helix) Ban(broadcasterID, userID, reason string, duration time.Duration) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	// the broadcaster is always a moderator of their own channel
+	resp, err := hx.c.BanUser(&helix.BanUserParams{
+		BroadcasterID: broadcasterID,
+		ModeratorId:   broadcasterID,
+		Body: helix.BanUserRequestBody{
+			Duration: int(duration.Seconds()),
+			Reason:   reason,
+			UserId:   userID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if urr := modUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.Bans))
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.Ban(broadcasterID, userID, reason, duration)
+	default:
+		return nil, err
+	}
+}
+
+// Unban removes the user's ban or timeout from the broadcaster's chat.
+func (hx *Helix) Unban(broadcasterID, userID string) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	resp, err := hx.c.UnbanUser(&helix.UnbanUserParams{
+		BroadcasterID: broadcasterID,
+		ModeratorID:   broadcasterID,
+		UserID:        userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if urr := modUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, 1)
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.Unban(broadcasterID, userID)
+	default:
+		return nil, err
+	}
+}
+
+// ChatSettingsUpdate changes the broadcaster's chat settings, only the
+// settings that are set in params are changed.
+func (hx *Helix) ChatSettingsUpdate(params *helix.UpdateChatSettingsParams) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	params.ModeratorID = params.BroadcasterID
+	resp, err := hx.c.UpdateChatSettings(params)
+	if err != nil {
+		return nil, err
+	}
+	if urr := modUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.Settings))
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.ChatSettingsUpdate(params)
+	default:
+		return nil, err
+	}
+}
+
+// ChatClear deletes all the messages in the broadcaster's chat.
+func (hx *Helix) ChatClear(broadcasterID string) (error, error) {
+	if hx.c.GetUserAccessToken() == "" {
+		return ErrUserTokenRequired, nil
+	}
+
+	// not specifying a message id deletes every message
+	resp, err := hx.c.DeleteChatMessage(&helix.DeleteChatMessageParams{
+		BroadcasterID: broadcasterID,
+		ModeratorID:   broadcasterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if urr := modUrr(resp.ResponseCommon); urr != nil {
+		return urr, nil
+	}
+
+	err = checkErrors(err, resp.ResponseCommon, 1)
+
+	switch err {
+	case nil:
+		return nil, nil
+	case ErrRetry:
+		if err := hx.refreshToken(); err != nil {
+			return nil, err
+		}
+		return hx.ChatClear(broadcasterID)
+	default:
+		return nil, err
+	}
+}
+
+// Returns the version and condition used to subscribe to events of type t in
+// the broadcaster's channel.
+func subscriptionCondition(broadcasterID, t string) (string, helix.EventSubCondition) {
+	switch t {
+	case helix.EventSubTypeChannelFollow:
+		// v1 has been deprecated, v2 requires a moderator of the channel,
+		// which the broadcaster always is
+		return "2", helix.EventSubCondition{
+			BroadcasterUserID: broadcasterID,
+			ModeratorUserID:   broadcasterID,
+		}
+	case helix.EventSubTypeChannelRaid:
+		return "1", helix.EventSubCondition{
+			ToBroadcasterUserID: broadcasterID,
+		}
+	default:
+		return "1", helix.EventSubCondition{
+			BroadcasterUserID: broadcasterID,
+		}
+	}
+}
+
 func (hx *Helix) CreateSubscription(broadcasterID, t string) (string, error) {
+	version, condition := subscriptionCondition(broadcasterID, t)
 	resp, err := hx.c.CreateEventSubSubscription(&helix.EventSubSubscription{
-		Type:    t,
-		Version: "1",
-		Condition: helix.EventSubCondition{
-			BroadcasterUserID: broadcasterID,
-		},
-		Transport: helix.EventSubTransport{
-			Method:   "webhook",
-			Callback: "https://" + core.VirtualHost + CallbackEventSub,
-			Secret:   secret,
-		},
+		Type:      t,
+		Version:   version,
+		Condition: condition,
+		Transport: eventsubTransport(),
 	})
 
 	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.EventSubSubscriptions))
@@ -450,24 +953,47 @@ func (hx *Helix) DeleteSubscription(subID string) error {
 	}
 }
 
+// ListSubscriptions returns all the subscriptions, across every page.
 func (hx *Helix) ListSubscriptions() ([]helix.EventSubSubscription, error) {
-	resp, err := hx.c.GetEventSubSubscriptions(&helix.EventSubSubscriptionsParams{})
+	var subs []helix.EventSubSubscription
+	after := ""
+	for {
+		page, next, err := hx.listSubscriptions(after)
+		// the last page may be empty
+		if err == ErrNoResults && after != "" {
+			return subs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, page...)
+		if next == "" {
+			return subs, nil
+		}
+		after = next
+	}
+}
+
+func (hx *Helix) listSubscriptions(after string) ([]helix.EventSubSubscription, string, error) {
+	resp, err := hx.c.GetEventSubSubscriptions(&helix.EventSubSubscriptionsParams{
+		After: after,
+	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	err = checkErrors(err, resp.ResponseCommon, len(resp.Data.EventSubSubscriptions))
 
 	switch err {
 	case nil:
-		return resp.Data.EventSubSubscriptions, nil
+		return resp.Data.EventSubSubscriptions, resp.Data.Pagination.Cursor, nil
 	case ErrRetry:
 		if err := hx.refreshToken(); err != nil {
-			return nil, err
+			return nil, "", err
 		}
-		return hx.ListSubscriptions()
+		return hx.listSubscriptions(after)
 	default:
-		return nil, err
+		return nil, "", err
 	}
 }
 
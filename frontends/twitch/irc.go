@@ -26,6 +26,9 @@ type frontend struct {
 	Nick     string
 	OAuth    string
 	Channels []string
+	// How EventSub notifications are received, either EventSubWebhook or
+	// EventSubWebSocket.
+	EventSub string
 }
 
 var Frontend = &frontend{}
@@ -103,6 +106,10 @@ func (f *frontend) Init(wgInit, wgStop *sync.WaitGroup, stop chan struct{}) {
 		panic(err)
 	}
 
+	if err := eventsubInit(stop); err != nil {
+		log.Fatal().Err(err).Msg("failed to start eventsub")
+	}
+
 	wgInit.Done()
 	<-stop
 
@@ -134,6 +141,32 @@ func (f *frontend) CreateMessage(person, place int64, _ string) (*core.EventMess
 	return NewMessage(pm)
 }
 
+func (f *frontend) Announce(place int64, text, _ string) error {
+	// twitch has no concept of roles, so the announcement is just sent as is
+	m, err := f.CreateMessage(place, place, "")
+	if err != nil {
+		return err
+	}
+	_, err = m.Client.Send(text, nil)
+	return err
+}
+
+func (f *frontend) RedeemResolve(rc *core.EventRedeemClaim, fulfilled bool) error {
+	broadcasterID, err := rc.Here.IDExact()
+	if err != nil {
+		return err
+	}
+	hx, err := NewHelix(broadcasterID)
+	if err != nil {
+		return err
+	}
+	urr, err := hx.RedemptionUpdate(broadcasterID, rc.ID, rc.RedemptionID, fulfilled)
+	if err != nil {
+		return err
+	}
+	return urr
+}
+
 func (f *frontend) Usage(usage string) any {
 	return fmt.Sprintf("Usage: %s", usage)
 }
@@ -225,6 +258,14 @@ func (t *Twitch) Person(id string) (int64, error) {
 	return dbAddChannel(id)
 }
 
+// This is how twitch's server seems to count the length, even though the
+// chat client on twitch's website doesn't follow this.
+const textLimit = 500
+
+// The longest mention that can be added to a message, a username is at most 25
+// characters long.
+const mentionLimit = len("@ -> ") + 25
+
 func (t *Twitch) send(msg any, mention string) (*core.EventMessage, error) {
 	var text string
 	switch t := msg.(type) {
@@ -236,11 +277,8 @@ func (t *Twitch) send(msg any, mention string) (*core.EventMessage, error) {
 
 	text = strings.ReplaceAll(text, "\n", " ")
 
-	// This is how twitch's server seems to count the length, even though the
-	// chat client on twitch's website doesn't follow this. Subtract the
-	// mention's length since it is added to every message sent.
-	lenLim := 500 - len(mention)
-	lenCnt := utf8.RuneCountInString
+	// Subtract the mention's length since it is added to every message sent.
+	lenCnt, lenLim := utf8.RuneCountInString, textLimit-len(mention)
 
 	ch, err := t.Here.Name()
 	if err != nil {
@@ -294,3 +332,8 @@ func (t *Twitch) Natural(msg any, _ core.Urr) (*core.EventMessage, error) {
 func (t *Twitch) QuoteCommand(cmd string) string {
 	return "'" + cmd + "'"
 }
+
+// Limit leaves enough room for any mention added when sending.
+func (t *Twitch) Limit() (func(string) int, int) {
+	return utf8.RuneCountInString, textLimit - mentionLimit
+}
@@ -35,6 +35,17 @@ func readVar(name string) string {
 	return v
 }
 
+// Same as readVar, except that def is returned if the variable isn't set.
+func readVarDefault(name, def string) string {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		log.Debug().Str(name, def).Msg("env variable not given, using default")
+		return def
+	}
+	log.Debug().Str(name, v).Msg("read env variable")
+	return v
+}
+
 func init() {
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
@@ -82,6 +93,10 @@ func connect(stop chan struct{}, wgStop *sync.WaitGroup) {
 	twitch.Frontend.Nick = "JanitorJeff"
 	twitch.Frontend.OAuth = readVar("TWITCH_OAUTH")
 	twitch.Frontend.Channels = strings.Split(readVar("TWITCH_CHANNELS"), ",")
+	twitch.Frontend.EventSub = readVarDefault("TWITCH_EVENTSUB", twitch.EventSubWebhook)
+	if t := twitch.Frontend.EventSub; t != twitch.EventSubWebhook && t != twitch.EventSubWebSocket {
+		log.Fatal().Msgf("invalid $TWITCH_EVENTSUB '%s', expected webhook or websocket", t)
+	}
 
 	discord.Frontend.Token = readVar("DISCORD_TOKEN")
 
@@ -134,6 +149,14 @@ func main() {
 	}
 	core.MinGodInterval = time.Duration(minGodIntervalSeconds) * time.Second
 
+	core.GodBaseURL = readVarDefault("GOD_BASE_URL", "https://api.openai.com/v1")
+	core.GodModel = readVarDefault("GOD_MODEL", "gpt-3.5-turbo")
+	godTimeoutSeconds, err := strconv.Atoi(readVarDefault("GOD_TIMEOUT_SECONDS", "30"))
+	if err != nil || godTimeoutSeconds <= 0 {
+		log.Fatal().Msg("invalid $GOD_TIMEOUT_SECONDS, expected a positive number")
+	}
+	core.GodTimeout = time.Duration(godTimeoutSeconds) * time.Second
+
 	core.Prefixes.Add(core.Admin, "##")
 	core.Prefixes.Add(core.Normal, "!")
 	core.Prefixes.Add(core.Advanced, "$")
@@ -0,0 +1,5 @@
+-- Custom commands used to only match when the message was exactly the trigger.
+-- Keep it that way for the existing ones, moderators can allow arguments with
+-- the args set command.
+UPDATE cmd_customcommand_commands SET args_max = 0 WHERE args_max IS NULL;
+ALTER TABLE cmd_customcommand_commands ALTER COLUMN args_max SET DEFAULT 0;
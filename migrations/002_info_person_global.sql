@@ -0,0 +1,27 @@
+-- Timezones used to be stored per place in info_person, they are now the same
+-- everywhere and live in info_person_global. Linked accounts share the
+-- timezone of their canonical person.
+BEGIN;
+
+CREATE TABLE info_person_global (
+	person BIGINT PRIMARY KEY,
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+
+	cmd_time_tz VARCHAR(255) NOT NULL DEFAULT 'UTC'
+);
+
+-- if a person set different timezones in different places, the one from the
+-- place with the lowest id is kept
+INSERT INTO info_person_global (person, cmd_time_tz)
+SELECT DISTINCT ON (person) person, cmd_time_tz
+FROM (
+	SELECT COALESCE(l.canonical, p.person) AS person, p.place, p.cmd_time_tz
+	FROM info_person p
+	LEFT JOIN person_links l ON l.person = p.person
+	WHERE p.cmd_time_tz <> 'UTC'
+) tz
+ORDER BY person, place;
+
+ALTER TABLE info_person DROP COLUMN cmd_time_tz;
+
+COMMIT;
@@ -10,6 +10,39 @@ CREATE TABLE scopes (
 	frontend_id VARCHAR(255) NOT NULL
 );
 
+-- Accounts of the same person on different frontends can be linked, in which
+-- case they all use the info of the canonical person.
+CREATE TABLE person_links (
+	person BIGINT PRIMARY KEY,
+	canonical BIGINT NOT NULL,
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (canonical) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX person_links_index_canonical ON person_links (canonical);
+
+-- Places on different frontends can be grouped together in order to share
+-- some of their data, the authority is the place whose data is used.
+CREATE TABLE place_groups (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	authority BIGINT NOT NULL UNIQUE,
+	FOREIGN KEY (authority) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE TABLE place_group_members (
+	place BIGINT PRIMARY KEY, -- a place can only be in one group
+	grp BIGINT NOT NULL,
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (grp) REFERENCES place_groups(id) ON DELETE CASCADE
+);
+
+CREATE TABLE place_group_features (
+	grp BIGINT NOT NULL,
+	feature VARCHAR(255) NOT NULL, -- see core.ShareFeatures
+	UNIQUE(grp, feature),
+	FOREIGN KEY (grp) REFERENCES place_groups(id) ON DELETE CASCADE
+);
+
 CREATE TABLE prefixes (
 	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
 	place BIGINT NOT NULL,
@@ -68,6 +101,48 @@ CREATE TABLE frontend_twitch_eventsub (
     FOREIGN KEY (channel) REFERENCES frontend_twitch_channels(scope) ON DELETE CASCADE
 );
 
+-- The secret used to sign webhook notifications, generated on the first run.
+CREATE TABLE frontend_twitch_eventsub_secret (
+	id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id), -- there's only ever one row
+	secret VARCHAR(100) NOT NULL
+);
+
+--------------------
+--                --
+-- Command: Alert --
+--                --
+--------------------
+
+-- The messages sent when someone follows, subscribes, raids or cheers.
+CREATE TABLE cmd_alert_messages (
+	place BIGINT NOT NULL,
+	event VARCHAR(255) NOT NULL, -- follow, sub, raid or cheer
+	message TEXT NOT NULL,
+	UNIQUE(place, event),
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+-----------------------
+--                   --
+-- Command: Announce --
+--                   --
+-----------------------
+
+CREATE TABLE cmd_announce_announcements (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	place BIGINT NOT NULL,
+	creator BIGINT NOT NULL,
+	time INTEGER NOT NULL,
+	what VARCHAR(255) NOT NULL,
+	role VARCHAR(255), -- null means that nobody is mentioned
+	attempts INT NOT NULL DEFAULT 0, -- failed delivery attempts
+	retry_at INTEGER, -- null means that the announcement is not being retried
+	dead BOOLEAN NOT NULL DEFAULT false, -- true if delivery was given up on
+	error TEXT, -- the last delivery error
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (creator) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
 ------------------------------
 --                          --
 -- Command: Custom Commands --
@@ -87,11 +162,28 @@ CREATE TABLE cmd_customcommand_commands (
 	deleter BIGINT,
 	deleted BIGINT,
 
+	args_min INT NOT NULL DEFAULT 0,
+	args_max INT DEFAULT 0, -- null means no maximum, by default only the trigger itself matches
+	args_usage VARCHAR(255), -- null means the default usage message is used
+
+	role VARCHAR(255) NOT NULL DEFAULT 'everyone', -- everyone, subscriber, moderator or admin
+	cooldown_global INT NOT NULL DEFAULT 0, -- in seconds
+	cooldown_user INT NOT NULL DEFAULT 0, -- in seconds
+
 	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
 	FOREIGN KEY (creator) REFERENCES scopes(id) ON DELETE CASCADE,
 	FOREIGN KEY (deleter) REFERENCES scopes(id) ON DELETE CASCADE
 );
 
+CREATE TABLE cmd_customcommand_counters (
+	place BIGINT NOT NULL,
+	trigger VARCHAR(255) NOT NULL,
+	count BIGINT NOT NULL DEFAULT 0,
+
+	UNIQUE(place, trigger),
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
 --------------------
 --                --
 -- Command: God   --
@@ -113,6 +205,74 @@ INSERT INTO cmd_god_personalities(place, name, prompt) VALUES
     (NULL, 'rude', 'Always respond in a snarky and rude way. Respond with 300 characters or less.'),
     (NULL, 'sad', 'You are God who has taken the form of a janitor. You are very sad about everything. Respond in 300 characters or less.');
 
+-- What God remembers of each person's conversation in a place. The system
+-- prompt isn't saved, the place's active personality is always used instead.
+CREATE TABLE cmd_god_memory (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	person BIGINT NOT NULL,
+	place BIGINT NOT NULL,
+	role VARCHAR(255) NOT NULL, -- user or assistant
+	content TEXT NOT NULL,
+	time BIGINT NOT NULL, -- unix timestamp
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX cmd_god_memory_conversation ON cmd_god_memory(person, place, id);
+
+-- The summary of the turns that no longer fit in the place's token budget.
+CREATE TABLE cmd_god_memory_summaries (
+	person BIGINT NOT NULL,
+	place BIGINT NOT NULL,
+	summary TEXT NOT NULL,
+	time BIGINT NOT NULL, -- unix timestamp
+	PRIMARY KEY (person, place),
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+-- People whose messages are never kept as chat context for auto-replies.
+CREATE TABLE cmd_god_context_excluded_people (
+	place BIGINT NOT NULL,
+	person BIGINT NOT NULL,
+	PRIMARY KEY (place, person),
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+-- Messages containing any of these, case-insensitively, are never kept as
+-- chat context for auto-replies.
+CREATE TABLE cmd_god_context_excluded_keywords (
+	place BIGINT NOT NULL,
+	keyword VARCHAR(255) NOT NULL, -- lowercase
+	PRIMARY KEY (place, keyword),
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+-- Terms that God will neither respond to nor say, case-insensitively.
+CREATE TABLE cmd_god_filter_terms (
+	place BIGINT NOT NULL,
+	term VARCHAR(255) NOT NULL, -- lowercase
+	PRIMARY KEY (place, term),
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+-- Prompts and replies that were blocked by the filter, kept so that moderators
+-- can review them.
+CREATE TABLE cmd_god_filter_blocked (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	person BIGINT, -- NULL for auto-replies
+	place BIGINT NOT NULL,
+	kind VARCHAR(255) NOT NULL, -- prompt or reply
+	text TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	time BIGINT NOT NULL, -- unix timestamp
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX cmd_god_filter_blocked_place ON cmd_god_filter_blocked(place, id);
+
 ---------------------
 --                 --
 -- Command: Lens   --
@@ -124,6 +284,69 @@ CREATE TABLE cmd_lens_directors (
     name VARCHAR(255) NOT NULL UNIQUE
 );
 
+------------------
+--              --
+-- Command: Mod --
+--              --
+------------------
+
+-- Every moderation action taken through the bot, so that moderators can look
+-- back at who did what and why.
+CREATE TABLE cmd_mod_actions (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	place BIGINT NOT NULL,
+	moderator BIGINT NOT NULL,
+	action VARCHAR(255) NOT NULL, -- timeout, ban, unban, slow, followers, emoteonly or clear
+	target BIGINT, -- null for actions that affect the whole chat
+	arg VARCHAR(255) NOT NULL DEFAULT '', -- e.g. the timeout's duration
+	reason TEXT NOT NULL DEFAULT '',
+	time BIGINT NOT NULL, -- unix timestamp
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (moderator) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (target) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX cmd_mod_actions_place ON cmd_mod_actions(place, time);
+
+---------------------
+--                 --
+-- Command: Redeem --
+--                 --
+---------------------
+
+-- Actions that are run when a channel point reward is redeemed, a reward can
+-- have more than one action.
+CREATE TABLE cmd_redeem_bindings (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	place BIGINT NOT NULL,
+	reward UUID NOT NULL,
+	action VARCHAR(255) NOT NULL, -- command, sound, timer or discord
+	arg TEXT NOT NULL, -- depends on the action
+	creator BIGINT NOT NULL,
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (creator) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX cmd_redeem_bindings_reward ON cmd_redeem_bindings(place, reward);
+
+--------------------
+--                --
+-- Command: Relay --
+--                --
+--------------------
+
+-- Mirrors the chat of a twitch channel into a discord channel, and optionally
+-- the other way around.
+CREATE TABLE cmd_relay_pairs (
+	id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+	twitch BIGINT NOT NULL,
+	discord BIGINT NOT NULL,
+	to_twitch BOOLEAN NOT NULL DEFAULT false, -- only the broadcaster can enable
+	UNIQUE(twitch, discord),
+	FOREIGN KEY (twitch) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (discord) REFERENCES scopes(id) ON DELETE CASCADE
+);
+
 -------------------
 --               --
 -- Command: Time --
@@ -137,8 +360,15 @@ CREATE TABLE cmd_time_reminders (
 	time INTEGER NOT NULL,
 	what VARCHAR(255) NOT NULL,
 	msg_id VARCHAR(255) NOT NULL,
+	recur VARCHAR(255), -- null means that the reminder only happens once
+	place_logical BIGINT NOT NULL, -- used to list reminders across places that share them
+	attempts INT NOT NULL DEFAULT 0, -- failed delivery attempts
+	retry_at INTEGER, -- null means that the reminder is not being retried
+	dead BOOLEAN NOT NULL DEFAULT false, -- true if delivery was given up on
+	error TEXT, -- the last delivery error
 	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
-	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE
+	FOREIGN KEY (place) REFERENCES scopes(id) ON DELETE CASCADE,
+	FOREIGN KEY (place_logical) REFERENCES scopes(id) ON DELETE CASCADE
 );
 
 ----------
@@ -158,6 +388,8 @@ CREATE TABLE info_place (
 	stream_offline_norm_prev BIGINT NOT NULL DEFAULT 0,
 	stream_grace INT NOT NULL DEFAULT 1800, -- in seconds
 
+	redeem_policy VARCHAR(255) NOT NULL DEFAULT 'auto', -- auto, refund or off
+
 	cmd_streak_redeem UUID, -- the streak tracking redeem id
 
 	cmd_god_auto_on BOOL NOT NULL DEFAULT FALSE,
@@ -167,6 +399,21 @@ CREATE TABLE info_place (
 	cmd_god_personality BIGINT NOT NULL DEFAULT 1,
 	cmd_god_everyone BOOL NOT NULL DEFAULT FALSE,
 	cmd_god_max INT NOT NULL DEFAULT 80,
+	cmd_god_model VARCHAR(255), -- uses the global default if NULL
+	cmd_god_base_url TEXT, -- uses the global default if NULL
+	cmd_god_timeout INT NOT NULL DEFAULT 0, -- in seconds, 0 uses the global default
+	cmd_god_memory_retention INT NOT NULL DEFAULT 86400, -- in seconds, 0 means forever
+	cmd_god_memory_budget INT NOT NULL DEFAULT 1000, -- in tokens
+	cmd_god_context_on BOOL NOT NULL DEFAULT FALSE,
+	cmd_god_context_size INT NOT NULL DEFAULT 20, -- in messages
+	cmd_god_filter_moderation BOOL NOT NULL DEFAULT FALSE,
+	cmd_god_filter_fallback TEXT, -- uses the default fallback if NULL
+
+	cmd_shoutout_auto BOOL NOT NULL DEFAULT FALSE,
+	cmd_shoutout_template TEXT, -- uses the default template if NULL
+	cmd_shoutout_cooldown INT NOT NULL DEFAULT 3600, -- in seconds
+
+	cmd_time_format VARCHAR(255) NOT NULL DEFAULT '24h', -- 12h, 24h, iso or relative
 	FOREIGN KEY (cmd_god_personality) REFERENCES cmd_god_personalities(id) ON DELETE NO ACTION
 );
 
@@ -182,10 +429,20 @@ CREATE TABLE info_person (
 
 	cmd_streak_num INT NOT NULL DEFAULT 0,
 	cmd_streak_last BIGINT NOT NULL DEFAULT 0,
-
-	cmd_time_tz VARCHAR(255) NOT NULL DEFAULT 'UTC'
+	cmd_streak_longest INT NOT NULL DEFAULT 0,
+	cmd_streak_total INT NOT NULL DEFAULT 0, -- total number of appearances
+	cmd_streak_first BIGINT NOT NULL DEFAULT 0 -- 0 means never seen
 );
 
 CREATE INDEX info_person_index_person_place ON info_person (person, place);
 CREATE INDEX info_person_index_nick ON info_person (cmd_nick_nick);
 
+-- Info about a person that doesn't depend on the place, which means that it's
+-- the same everywhere for all the linked accounts.
+CREATE TABLE info_person_global (
+	person BIGINT PRIMARY KEY,
+	FOREIGN KEY (person) REFERENCES scopes(id) ON DELETE CASCADE,
+
+	cmd_time_tz VARCHAR(255) NOT NULL DEFAULT 'UTC'
+);
+